@@ -0,0 +1,90 @@
+package goline
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RequireAll composes middlewares so that the request must pass all of them in order, e.g.
+//
+//	router.Use(goline.RequireAll(allowlist, lineAuth.VerifyIDTokenMiddleware))
+func RequireAll(middlewares ...func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
+
+// CIDRList is a list of networks to check the source IP address of requests
+type CIDRList []*net.IPNet
+
+// ParseCIDRList parses CIDRs like "192.0.2.0/24" or single IP addresses
+func ParseCIDRList(cidrs ...string) (CIDRList, error) {
+	l := make(CIDRList, 0, len(cidrs))
+	for _, c := range cidrs {
+		if !strings.Contains(c, "/") {
+			ip := net.ParseIP(c)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address: %s", c)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			l = append(l, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			return nil, err
+		}
+		l = append(l, n)
+	}
+	return l, nil
+}
+
+// Contains reports whether the IP address is in the list
+func (l CIDRList) Contains(ip net.IP) bool {
+	for _, n := range l {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// IPAllowlist returns a middleware responding 403 Forbidden to requests from outside of the CIDRs.
+// The source IP address is taken from the peer address. Forwarded headers are not trusted.
+func IPAllowlist(cidrs ...string) (func(http.Handler) http.Handler, error) {
+	l, err := ParseCIDRList(cidrs...)
+	if err != nil {
+		return nil, err
+	}
+	return ipFilter(func(ip net.IP) bool { return l.Contains(ip) }), nil
+}
+
+// IPDenylist returns a middleware responding 403 Forbidden to requests from the CIDRs
+func IPDenylist(cidrs ...string) (func(http.Handler) http.Handler, error) {
+	l, err := ParseCIDRList(cidrs...)
+	if err != nil {
+		return nil, err
+	}
+	return ipFilter(func(ip net.IP) bool { return !l.Contains(ip) }), nil
+}
+
+func ipFilter(allow func(ip net.IP) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := net.ParseIP(remoteIP(r))
+			if ip == nil || !allow(ip) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
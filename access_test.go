@@ -0,0 +1,105 @@
+package goline
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseCIDRList(t *testing.T) {
+	tests := []struct {
+		name     string
+		cidrs    []string
+		wantErr  bool
+		contains []string
+		excludes []string
+	}{
+		{name: "IPv4 CIDR", cidrs: []string{"192.0.2.0/24"}, contains: []string{"192.0.2.1", "192.0.2.255"}, excludes: []string{"192.0.3.1"}},
+		{name: "bare IPv4", cidrs: []string{"192.0.2.1"}, contains: []string{"192.0.2.1", "::ffff:192.0.2.1"}, excludes: []string{"192.0.2.2"}},
+		{name: "bare IPv6", cidrs: []string{"2001:db8::1"}, contains: []string{"2001:db8::1"}, excludes: []string{"2001:db8::2"}},
+		{name: "IPv6 CIDR", cidrs: []string{"2001:db8::/32"}, contains: []string{"2001:db8:1::1"}, excludes: []string{"2001:db9::1", "192.0.2.1"}},
+		{name: "IPv4-mapped IPv6", cidrs: []string{"::ffff:192.0.2.1"}, contains: []string{"192.0.2.1", "::ffff:192.0.2.1"}, excludes: []string{"192.0.2.2"}},
+		{name: "invalid CIDR", cidrs: []string{"192.0.2.0/33"}, wantErr: true},
+		{name: "invalid IP", cidrs: []string{"192.0.2.256"}, wantErr: true},
+		{name: "hostname", cidrs: []string{"example.com"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := ParseCIDRList(tt.cidrs...)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("ParseCIDRList() succeeded")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCIDRList() error = %v", err)
+			}
+			for _, ip := range tt.contains {
+				if !l.Contains(net.ParseIP(ip)) {
+					t.Errorf("Contains(%s) = false", ip)
+				}
+			}
+			for _, ip := range tt.excludes {
+				if l.Contains(net.ParseIP(ip)) {
+					t.Errorf("Contains(%s) = true", ip)
+				}
+			}
+		})
+	}
+}
+
+func TestIPFilter(t *testing.T) {
+	cidrs := []string{"192.0.2.0/24", "2001:db8::1"}
+	allowlist, err := IPAllowlist(cidrs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	denylist, err := IPDenylist(cidrs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		wantAllow  int
+		wantDeny   int
+	}{
+		{name: "IPv4 in the list", remoteAddr: "192.0.2.1:1234", wantAllow: http.StatusOK, wantDeny: http.StatusForbidden},
+		{name: "IPv4 out of the list", remoteAddr: "198.51.100.1:1234", wantAllow: http.StatusForbidden, wantDeny: http.StatusOK},
+		{name: "IPv6 in the list", remoteAddr: "[2001:db8::1]:1234", wantAllow: http.StatusOK, wantDeny: http.StatusForbidden},
+		{name: "IPv6 out of the list", remoteAddr: "[2001:db8::2]:1234", wantAllow: http.StatusForbidden, wantDeny: http.StatusOK},
+		{name: "IPv4-mapped IPv6", remoteAddr: "[::ffff:192.0.2.1]:1234", wantAllow: http.StatusOK, wantDeny: http.StatusForbidden},
+		{name: "without port", remoteAddr: "192.0.2.1", wantAllow: http.StatusOK, wantDeny: http.StatusForbidden},
+		{name: "unparsable", remoteAddr: "pipe", wantAllow: http.StatusForbidden, wantDeny: http.StatusForbidden},
+		{name: "empty", remoteAddr: "", wantAllow: http.StatusForbidden, wantDeny: http.StatusForbidden},
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, c := range []struct {
+				filter func(http.Handler) http.Handler
+				want   int
+			}{{allowlist, tt.wantAllow}, {denylist, tt.wantDeny}} {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.RemoteAddr = tt.remoteAddr
+				w := httptest.NewRecorder()
+				c.filter(ok).ServeHTTP(w, r)
+				if w.Code != c.want {
+					t.Errorf("status = %d, want %d", w.Code, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestIPAllowlistInvalidCIDR(t *testing.T) {
+	if _, err := IPAllowlist("192.0.2.0/24", "invalid"); err == nil {
+		t.Error("IPAllowlist() succeeded")
+	}
+	if _, err := IPDenylist("invalid/8"); err == nil {
+		t.Error("IPDenylist() succeeded")
+	}
+}
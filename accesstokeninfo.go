@@ -0,0 +1,93 @@
+package goline
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// AccessTokenInfo is the access token verified by VerifyAccessTokenOnlyMiddleware
+type AccessTokenInfo struct {
+	// ClientID is the channel ID the token is issued for. It is the channel ID of the Client unless WithClientIDCheck(false).
+	ClientID string
+	// Scopes are the permissions granted to the token e.g. "profile", "openid"
+	Scopes []string
+	// ExpiresAt is the time when the token expires
+	ExpiresAt time.Time
+}
+
+// HasScope returns true when the token is granted the scope
+func (i *AccessTokenInfo) HasScope(scope string) bool {
+	return slices.Contains(i.Scopes, scope)
+}
+
+type accessTokenInfoContextKey struct{}
+
+// SetAccessTokenInfo returns the context with the verified access token.
+// VerifyAccessTokenOnlyMiddleware sets the verified token by it. It is also useful to test handlers.
+func SetAccessTokenInfo(ctx context.Context, i *AccessTokenInfo) context.Context {
+	return context.WithValue(ctx, accessTokenInfoContextKey{}, i)
+}
+
+// AccessTokenInfoFromContext returns the access token set by VerifyAccessTokenOnlyMiddleware
+func AccessTokenInfoFromContext(ctx context.Context) (*AccessTokenInfo, bool) {
+	i, ok := ctx.Value(accessTokenInfoContextKey{}).(*AccessTokenInfo)
+	return i, ok && i != nil
+}
+
+// VerifyAccessTokenOnlyMiddleware is a lighter VerifyAccessTokenMiddleware for the APIs which only need
+// the access token is valid for the channel. It verifies the access token upstream but does not get the profile,
+// so that it takes one round trip to LINE, or none when cached by WithCache.
+// The verified token is available by AccessTokenInfoFromContext. The LINE user is not available,
+// so the user info headers are removed from the request, and the validators, the enricher and
+// the degradation policy are not applied.
+func (a *Authorizer) VerifyAccessTokenOnlyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, log := a.withCorrelationID(r, a.log.With("handler", "VerifyAccessTokenOnlyMiddleware"))
+		start := a.lineClient.clock.Now()
+
+		token, err := extractBearerToken(r.Header.Get(authHeader))
+		if err != nil {
+			// ErrBearerTokenNotFound or ErrBearerTokenMalformed
+			a.deny(w, r, log, classifyAuthFailure(err), err, start)
+			return
+		}
+		info, err := a.introspectAccessToken(r.Context(), token)
+		if err != nil {
+			a.deny(w, r, log, classifyAuthFailure(err), err, start)
+			return
+		}
+		a.recordDecision(r.Context(), log, nil, "", nil, start)
+		if a.audit != nil {
+			a.audit.Audit(r.Context(), a.newAuditEvent(r, AuditDecisionAllow))
+		}
+
+		// Not to trust the user info headers of the client
+		a.resetUserHeaders(r.Header)
+		a.setExpiresInHeader(w.Header(), &User{ExpiresAt: info.ExpiresAt})
+
+		next.ServeHTTP(w, r.WithContext(SetAccessTokenInfo(r.Context(), info)))
+	})
+}
+
+// introspectAccessToken verifies the access token upstream, which also checks the client ID
+func (a *Authorizer) introspectAccessToken(ctx context.Context, accessToken string) (*AccessTokenInfo, error) {
+	if a.dev != nil {
+		u, err := a.authenticateDev(accessToken)
+		if err != nil {
+			return nil, err
+		}
+		return &AccessTokenInfo{ClientID: a.lineClient.clientid, ExpiresAt: u.ExpiresAt}, nil
+	}
+	v, err := a.lineClient.VerifyAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	return &AccessTokenInfo{
+		ClientID:  v.ClientID,
+		Scopes:    strings.Fields(v.Scope),
+		ExpiresAt: v.ExpiresAt(),
+	}, nil
+}
@@ -0,0 +1,34 @@
+package goline
+
+import "net/http"
+
+// Authentication methods in amr claim of ID token
+// https://developers.line.biz/ja/reference/line-login/#verify-id-token-response
+const (
+	AMRPassword  = "pwd"
+	AMRAutoLogin = "lineautologin"
+	AMRQRCode    = "lineqr"
+	AMRSSO       = "linesso"
+	AMRMFA       = "mfa"
+)
+
+// RequireAMR returns a middleware allowing users authenticated with any of the methods, e.g. to reject
+// auto login for sensitive endpoints. Use it after VerifyIDTokenMiddleware since only ID tokens have amr.
+// It responds 401 Unauthorized with error="insufficient_user_authentication" (RFC 9470) to ask the user to log in again.
+func RequireAMR(methods ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			u, ok := UserFromContext(r.Context())
+			if !ok {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if !containsAny(u.AMR, methods) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_user_authentication", error_description="authentication method is not acceptable"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
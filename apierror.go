@@ -0,0 +1,128 @@
+package goline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	headerRequestID = "X-Line-Request-Id"
+
+	// Maximum size of the error response body parsed into APIError
+	maxErrorBodySize = 64 * 1024
+)
+
+// APIError is the error response of LINE API. It wraps the sentinel error of the status code,
+// so errors.Is(err, ErrUnauthorized) keeps working while errors.As gives the details.
+//
+//	var apiErr *goline.APIError
+//	if errors.As(err, &apiErr) {
+//		log.Info("LINE API error", "status", apiErr.StatusCode, "message", apiErr.Message, "requestId", apiErr.RequestID)
+//	}
+type APIError struct {
+	StatusCode int
+	// Code is "error" of LINE Login APIs e.g. "invalid_request"
+	Code string
+	// Message is "error_description" of LINE Login APIs or "message" of Messaging API
+	Message string
+	// Details is "details" of Messaging API
+	Details []APIErrorDetail
+	// RequestID is X-Line-Request-Id response header
+	RequestID string
+	// RetryAfter is Retry-After response header e.g. of 429 Too Many Requests
+	RetryAfter time.Duration
+
+	err error
+}
+
+// APIErrorDetail is the detail of the error response of Messaging API
+type APIErrorDetail struct {
+	Message  string `json:"message"`
+	Property string `json:"property"`
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return e.err.Error()
+	}
+	return fmt.Sprintf("%s: %s", e.err.Error(), e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.err
+}
+
+// errorResponse is the union of the error response json of LINE Login APIs and Messaging API
+type errorResponse struct {
+	Error            json.RawMessage  `json:"error"`
+	ErrorDescription string           `json:"error_description"`
+	Message          string           `json:"message"`
+	Details          []APIErrorDetail `json:"details"`
+}
+
+// CheckResponse checks the status code of LINE API response and returns the corresponding error.
+// It returns nil when the status code is 2xx. Otherwise it returns *APIError wrapping the sentinel error e.g. ErrBadRequest.
+// The body is restored after parsed, so the caller can read it again.
+func CheckResponse(res *http.Response) error {
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+	e := &APIError{
+		StatusCode: res.StatusCode,
+		RequestID:  res.Header.Get(headerRequestID),
+		RetryAfter: ParseRetryAfter(res.Header.Get("Retry-After")),
+		err:        errByStatusCode(res.StatusCode),
+	}
+	if res.Body == nil {
+		return e
+	}
+
+	b, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodySize))
+	res.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(b), res.Body), res.Body}
+
+	body := &errorResponse{}
+	if json.Unmarshal(b, body) == nil {
+		// "error" is an object in some APIs, so it is set only when it is a string
+		json.Unmarshal(body.Error, &e.Code)
+		e.Message = body.ErrorDescription
+		if e.Message == "" {
+			e.Message = body.Message
+		}
+		e.Details = body.Details
+	}
+	return e
+}
+
+func errByStatusCode(statusCode int) error {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return ErrBadRequest
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusTooManyRequests:
+		return ErrTooManyRequests
+	case http.StatusInternalServerError:
+		return ErrInternalServerError
+	case http.StatusBadGateway:
+		return ErrBadGateway
+	case http.StatusServiceUnavailable:
+		return ErrServiceUnavailable
+	case http.StatusGatewayTimeout:
+		return ErrGatewayTimeout
+	default:
+		return fmt.Errorf("Unknown status code %d", statusCode)
+	}
+}
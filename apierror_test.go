@@ -0,0 +1,184 @@
+package goline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newResponse(status int, header http.Header, body string) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader(body))}
+}
+
+func TestCheckResponseSentinels(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusBadRequest, ErrBadRequest},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrForbidden},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusConflict, ErrConflict},
+		{http.StatusTooManyRequests, ErrTooManyRequests},
+		{http.StatusInternalServerError, ErrInternalServerError},
+		{http.StatusBadGateway, ErrBadGateway},
+		{http.StatusServiceUnavailable, ErrServiceUnavailable},
+		{http.StatusGatewayTimeout, ErrGatewayTimeout},
+	}
+	sentinels := make([]error, 0, len(tests))
+	for _, tt := range tests {
+		sentinels = append(sentinels, tt.want)
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.status), func(t *testing.T) {
+			err := CheckResponse(newResponse(tt.status, nil, ""))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.want)
+			}
+			for _, s := range sentinels {
+				if s != tt.want && errors.Is(err, s) {
+					t.Errorf("errors.Is(%v, %v) = true", err, s)
+				}
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Errorf("errors.As(%v) = %+v, want status %d", err, apiErr, tt.status)
+			}
+			// Wrapped by the callers
+			wrapped := fmt.Errorf("failed: %w", err)
+			if !errors.Is(wrapped, tt.want) || !errors.As(wrapped, &apiErr) {
+				t.Errorf("wrapped %v does not match %v or *APIError", wrapped, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckResponse(t *testing.T) {
+	if err := CheckResponse(newResponse(http.StatusOK, nil, "")); err != nil {
+		t.Errorf("CheckResponse(200) = %v", err)
+	}
+
+	t.Run("unknown status", func(t *testing.T) {
+		err := CheckResponse(newResponse(http.StatusTeapot, nil, ""))
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTeapot {
+			t.Errorf("errors.As(%v) = %+v, want status 418", err, apiErr)
+		}
+	})
+
+	t.Run("LINE Login error", func(t *testing.T) {
+		h := http.Header{headerRequestID: {"req-1"}}
+		res := newResponse(http.StatusBadRequest, h, `{"error":"invalid_request","error_description":"invalid id_token"}`)
+		err := CheckResponse(res)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("errors.As(%v) = false", err)
+		}
+		if apiErr.Code != "invalid_request" || apiErr.Message != "invalid id_token" || apiErr.RequestID != "req-1" {
+			t.Errorf("got %+v", apiErr)
+		}
+		if got := err.Error(); got != "400 Bad Request: invalid id_token" {
+			t.Errorf("Error() = %s", got)
+		}
+		// The body is restored
+		if b, _ := io.ReadAll(res.Body); !strings.Contains(string(b), "invalid_request") {
+			t.Errorf("body = %s", b)
+		}
+	})
+
+	t.Run("Messaging API error", func(t *testing.T) {
+		res := newResponse(http.StatusTooManyRequests, http.Header{"Retry-After": {"3"}},
+			`{"message":"The request body has 1 error(s)","details":[{"message":"May not be empty","property":"messages[0].text"}]}`)
+		var apiErr *APIError
+		if !errors.As(CheckResponse(res), &apiErr) {
+			t.Fatal("errors.As() = false")
+		}
+		if apiErr.Message != "The request body has 1 error(s)" || len(apiErr.Details) != 1 || apiErr.Details[0].Property != "messages[0].text" {
+			t.Errorf("got %+v", apiErr)
+		}
+		if apiErr.RetryAfter != 3*time.Second {
+			t.Errorf("RetryAfter = %s", apiErr.RetryAfter)
+		}
+	})
+
+	t.Run("error object", func(t *testing.T) {
+		var apiErr *APIError
+		if !errors.As(CheckResponse(newResponse(http.StatusBadRequest, nil, `{"error":{"code":1},"message":"bad"}`)), &apiErr) {
+			t.Fatal("errors.As() = false")
+		}
+		if apiErr.Code != "" || apiErr.Message != "bad" {
+			t.Errorf("got %+v", apiErr)
+		}
+	})
+}
+
+func TestClientErrorsWrapSentinels(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   []error
+	}{
+		{name: "expired", status: http.StatusBadRequest, body: `{"error":"invalid_request","error_description":"access token expired"}`,
+			want: []error{ErrTokenExpired, ErrBadRequest}},
+		{name: "revoked", status: http.StatusBadRequest, body: `{"error":"invalid_grant","error_description":"revoked"}`,
+			want: []error{ErrTokenRevoked, ErrBadRequest}},
+		{name: "bad request", status: http.StatusBadRequest, body: `{"error":"invalid_request","error_description":"missing"}`,
+			want: []error{ErrBadRequest}},
+		{name: "unauthorized", status: http.StatusUnauthorized, want: []error{ErrUnauthorized}},
+		{name: "service unavailable", status: http.StatusServiceUnavailable, want: []error{ErrServiceUnavailable}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, hc := newTestLINE(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set(headerRequestID, "req-1")
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			_, err := NewClient("123", hc).VerifyAccessToken(context.Background(), "token")
+			for _, want := range tt.want {
+				if !errors.Is(err, want) {
+					t.Errorf("errors.Is(%v, %v) = false", err, want)
+				}
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status || apiErr.RequestID != "req-1" {
+				t.Errorf("errors.As(%v) = %+v, want status %d", err, apiErr, tt.status)
+			}
+		})
+	}
+}
+
+func TestClassifyTokenErrorWrapped(t *testing.T) {
+	apiErr := &APIError{StatusCode: http.StatusBadRequest, Message: "access token expired", err: ErrBadRequest}
+	wrapped := fmt.Errorf("transport: %w", apiErr)
+
+	err := classifyTokenError(wrapped)
+	if !errors.Is(err, ErrTokenExpired) || !errors.Is(err, ErrBadRequest) {
+		t.Errorf("classifyTokenError(%v) = %v, want ErrTokenExpired", wrapped, err)
+	}
+	// The wrapping error is kept in the chain
+	if !errors.Is(err, wrapped) {
+		t.Errorf("classifyTokenError(%v) = %v, want to wrap the original error", wrapped, err)
+	}
+	var got *APIError
+	if !errors.As(err, &got) || got != apiErr {
+		t.Errorf("errors.As() = %v, want the APIError", got)
+	}
+
+	other := fmt.Errorf("transport: %w", &APIError{StatusCode: http.StatusBadRequest, Message: "missing", err: ErrBadRequest})
+	if err := classifyTokenError(other); err != other {
+		t.Errorf("classifyTokenError(%v) = %v, want as it is", other, err)
+	}
+}
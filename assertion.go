@@ -0,0 +1,342 @@
+package goline
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	assertionAudience = "https://api.line.me/"
+	// Maximum lifetime of the assertion JWT
+	assertionLifetime = 30 * time.Minute
+	// Maximum lifetime of the channel access token v2.1
+	maxChannelTokenExp = 30 * 24 * time.Hour
+)
+
+var (
+	// ErrNoActiveAssertionKey is returned when no assertion signing key is valid at the time
+	ErrNoActiveAssertionKey = errors.New("no active assertion signing key")
+	// ErrAssertionKeyNotFound is returned when the assertion signing key of the kid is not registered
+	ErrAssertionKeyNotFound = errors.New("assertion signing key not found")
+)
+
+// AssertionKey is the private key of the assertion signing key pair registered in the channel.
+// NotBefore and NotAfter are the rotation schedule of the key. Zero value means no limit.
+// https://developers.line.biz/ja/docs/messaging-api/generate-json-web-token/
+type AssertionKey struct {
+	// KeyID is the kid issued by LINE when the public key is registered
+	KeyID      string
+	PrivateKey *rsa.PrivateKey
+	NotBefore  time.Time
+	NotAfter   time.Time
+}
+
+// activeAt returns true if the key is in the validity period at the time
+func (k *AssertionKey) activeAt(t time.Time) bool {
+	return (k.NotBefore.IsZero() || !t.Before(k.NotBefore)) && (k.NotAfter.IsZero() || t.Before(k.NotAfter))
+}
+
+// AssertionSigner signs JWT assertions to issue channel access tokens v2.1.
+// It holds multiple active keys and selects the newest one valid at the time,
+// so that the keys can be rolled over by adding the new key before the old one expires.
+type AssertionSigner struct {
+	channelID string
+	clock     Clock
+	tokenExp  time.Duration
+	threshold time.Duration
+	onExpiry  func(k AssertionKey)
+
+	mu       sync.RWMutex
+	keys     []AssertionKey
+	notified map[string]bool
+}
+
+// AssertionSignerOption configures AssertionSigner
+type AssertionSignerOption func(*AssertionSigner)
+
+// WithAssertionClock sets Clock used to select keys and set the time claims. Default is SystemClock.
+func WithAssertionClock(clock Clock) AssertionSignerOption {
+	return func(s *AssertionSigner) {
+		s.clock = clock
+	}
+}
+
+// WithChannelTokenExpiry sets the lifetime of the issued channel access tokens up to 30 days. Default is 30 days.
+func WithChannelTokenExpiry(d time.Duration) AssertionSignerOption {
+	return func(s *AssertionSigner) {
+		s.tokenExp = d
+	}
+}
+
+// WithKeyExpiryHook sets a function called once per key when the selected key expires within the threshold.
+// Use it to alert or to start generating and registering the next key.
+func WithKeyExpiryHook(threshold time.Duration, fn func(k AssertionKey)) AssertionSignerOption {
+	return func(s *AssertionSigner) {
+		s.threshold = threshold
+		s.onExpiry = fn
+	}
+}
+
+// NewAssertionSigner returns AssertionSigner of the channel
+func NewAssertionSigner(channelID string, keys []AssertionKey, opts ...AssertionSignerOption) (*AssertionSigner, error) {
+	if channelID == "" {
+		return nil, errors.New("channel ID not found")
+	}
+	s := &AssertionSigner{
+		channelID: channelID,
+		clock:     SystemClock,
+		tokenExp:  maxChannelTokenExp,
+		notified:  make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.tokenExp <= 0 || s.tokenExp > maxChannelTokenExp {
+		return nil, fmt.Errorf("channel token expiry must be up to %s", maxChannelTokenExp)
+	}
+	if err := s.SetKeys(keys); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SetKeys replaces all keys. It is safe to call while signing.
+func (s *AssertionSigner) SetKeys(keys []AssertionKey) error {
+	for _, k := range keys {
+		if err := validateAssertionKey(k); err != nil {
+			return err
+		}
+	}
+	ks := make([]AssertionKey, len(keys))
+	copy(ks, keys)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = ks
+	return nil
+}
+
+// AddKey adds the key or replaces the key of the same kid
+func (s *AssertionSigner) AddKey(k AssertionKey) error {
+	if err := validateAssertionKey(k); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.keys {
+		if s.keys[i].KeyID == k.KeyID {
+			s.keys[i] = k
+			return nil
+		}
+	}
+	s.keys = append(s.keys, k)
+	return nil
+}
+
+// RemoveKey removes the key of the kid
+func (s *AssertionSigner) RemoveKey(kid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.keys {
+		if s.keys[i].KeyID == kid {
+			s.keys = append(s.keys[:i], s.keys[i+1:]...)
+			delete(s.notified, kid)
+			return
+		}
+	}
+}
+
+// KeyIDs returns kids of the keys active at now, newest first
+func (s *AssertionSigner) KeyIDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := s.activeKeys(s.clock.Now())
+	kids := make([]string, len(keys))
+	for i, k := range keys {
+		kids[i] = k.KeyID
+	}
+	return kids
+}
+
+// Sign returns JWT assertion signed by the newest key active at now
+func (s *AssertionSigner) Sign() (string, error) {
+	now := s.clock.Now()
+
+	s.mu.RLock()
+	keys := s.activeKeys(now)
+	s.mu.RUnlock()
+	if len(keys) == 0 {
+		return "", ErrNoActiveAssertionKey
+	}
+	k := keys[0]
+	s.checkExpiry(k, now)
+	return s.sign(k, now)
+}
+
+// SignWithKeyID returns JWT assertion signed by the key of the kid.
+// ErrAssertionKeyNotFound is returned when the key is not registered or not active at now.
+func (s *AssertionSigner) SignWithKeyID(kid string) (string, error) {
+	now := s.clock.Now()
+
+	s.mu.RLock()
+	keys := s.activeKeys(now)
+	s.mu.RUnlock()
+	for _, k := range keys {
+		if k.KeyID == kid {
+			s.checkExpiry(k, now)
+			return s.sign(k, now)
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrAssertionKeyNotFound, kid)
+}
+
+// activeKeys returns the keys active at the time sorted by NotBefore descending. s.mu must be held.
+func (s *AssertionSigner) activeKeys(now time.Time) []AssertionKey {
+	keys := make([]AssertionKey, 0, len(s.keys))
+	for _, k := range s.keys {
+		if k.activeAt(now) {
+			keys = append(keys, k)
+		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].NotBefore.After(keys[j].NotBefore)
+	})
+	return keys
+}
+
+func (s *AssertionSigner) checkExpiry(k AssertionKey, now time.Time) {
+	if s.onExpiry == nil || k.NotAfter.IsZero() || k.NotAfter.Sub(now) > s.threshold {
+		return
+	}
+	s.mu.Lock()
+	notified := s.notified[k.KeyID]
+	s.notified[k.KeyID] = true
+	s.mu.Unlock()
+	if !notified {
+		s.onExpiry(k)
+	}
+}
+
+func (s *AssertionSigner) sign(k AssertionKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"kid": k.KeyID,
+	})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"iss":       s.channelID,
+		"sub":       s.channelID,
+		"aud":       assertionAudience,
+		"exp":       now.Add(assertionLifetime).Unix(),
+		"token_exp": int64(s.tokenExp / time.Second),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	signingInput := enc.EncodeToString(header) + "." + enc.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, k.PrivateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + enc.EncodeToString(sig), nil
+}
+
+func validateAssertionKey(k AssertionKey) error {
+	if k.KeyID == "" {
+		return errors.New("assertion key ID not found")
+	}
+	if k.PrivateKey == nil {
+		return fmt.Errorf("private key of assertion key %s not found", k.KeyID)
+	}
+	if !k.NotBefore.IsZero() && !k.NotAfter.IsZero() && !k.NotBefore.Before(k.NotAfter) {
+		return fmt.Errorf("assertion key %s must be valid for a period", k.KeyID)
+	}
+	return nil
+}
+
+// ParseAssertionPrivateKey parses RSA private key in JWK generated for the assertion signing key pair,
+// or in PEM encoded PKCS#1 or PKCS#8. Combine it with SecretProvider not to embed the key in code.
+//
+//	b, err := provider.GetSecret(ctx, "line-assertion-key")
+//	key, err := goline.ParseAssertionPrivateKey(b)
+func ParseAssertionPrivateKey(b []byte) (*rsa.PrivateKey, error) {
+	if block, _ := pem.Decode(b); block != nil {
+		if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+			return key, nil
+		}
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %w", err)
+		}
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("private key must be RSA")
+		}
+		return rsaKey, nil
+	}
+	return parseRSAJWK(b)
+}
+
+// rsaJWK is the json struct of RSA private key in JWK
+type rsaJWK struct {
+	Kty string `json:"kty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	D   string `json:"d"`
+	P   string `json:"p"`
+	Q   string `json:"q"`
+}
+
+func parseRSAJWK(b []byte) (*rsa.PrivateKey, error) {
+	jwk := &rsaJWK{}
+	if err := json.Unmarshal(b, jwk); err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	if jwk.Kty != "RSA" {
+		return nil, fmt.Errorf("unsupported key type: %s", jwk.Kty)
+	}
+
+	var n, e, d, p, q *big.Int
+	for _, v := range []struct {
+		dst **big.Int
+		src string
+	}{{&n, jwk.N}, {&e, jwk.E}, {&d, jwk.D}, {&p, jwk.P}, {&q, jwk.Q}} {
+		raw, err := base64.RawURLEncoding.DecodeString(v.src)
+		if err != nil || len(raw) == 0 {
+			return nil, errors.New("invalid RSA private key in JWK")
+		}
+		*v.dst = new(big.Int).SetBytes(raw)
+	}
+	if !e.IsInt64() {
+		return nil, errors.New("invalid RSA public exponent in JWK")
+	}
+
+	key := &rsa.PrivateKey{
+		PublicKey: rsa.PublicKey{N: n, E: int(e.Int64())},
+		D:         d,
+		Primes:    []*big.Int{p, q},
+	}
+	if err := key.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid RSA private key in JWK: %w", err)
+	}
+	key.Precompute()
+	return key, nil
+}
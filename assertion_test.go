@@ -0,0 +1,255 @@
+package goline
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func generateRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// verifyAssertion verifies the signature of the assertion by the public key and returns the header and the claims
+func verifyAssertion(t *testing.T, assertion string, pub *rsa.PublicKey) (header map[string]string, claims map[string]interface{}) {
+	t.Helper()
+	parts := strings.Split(assertion, ".")
+	if len(parts) != 3 {
+		t.Fatalf("assertion has %d parts", len(parts))
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig); err != nil {
+		t.Fatalf("invalid signature: %v", err)
+	}
+	for i, v := range []interface{}{&header, &claims} {
+		b, err := base64.RawURLEncoding.DecodeString(parts[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(b, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return header, claims
+}
+
+func TestAssertionSignerSign(t *testing.T) {
+	key := generateRSAKey(t)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s, err := NewAssertionSigner("123", []AssertionKey{{KeyID: "kid1", PrivateKey: key}},
+		WithAssertionClock(ClockFunc(func() time.Time { return now })),
+		WithChannelTokenExpiry(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertion, err := s.Sign()
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	header, claims := verifyAssertion(t, assertion, &key.PublicKey)
+	if header["kid"] != "kid1" || header["alg"] != "RS256" {
+		t.Errorf("header = %v", header)
+	}
+	want := map[string]interface{}{
+		"iss":       "123",
+		"sub":       "123",
+		"aud":       assertionAudience,
+		"exp":       float64(now.Add(assertionLifetime).Unix()),
+		"token_exp": float64(24 * 60 * 60),
+	}
+	for k, v := range want {
+		if claims[k] != v {
+			t.Errorf("claim %s = %v, want %v", k, claims[k], v)
+		}
+	}
+}
+
+func TestAssertionSignerRotation(t *testing.T) {
+	oldKey, newKey := generateRSAKey(t), generateRSAKey(t)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	keys := []AssertionKey{
+		{KeyID: "old", PrivateKey: oldKey, NotAfter: start.Add(48 * time.Hour)},
+		{KeyID: "new", PrivateKey: newKey, NotBefore: start.Add(24 * time.Hour)},
+	}
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		wantKid string
+		wantKey *rsa.PrivateKey
+		wantIDs []string
+	}{
+		{name: "before the new key", now: start, wantKid: "old", wantKey: oldKey, wantIDs: []string{"old"}},
+		{name: "overlap uses the newest", now: start.Add(36 * time.Hour), wantKid: "new", wantKey: newKey, wantIDs: []string{"new", "old"}},
+		{name: "at NotAfter of the old key", now: start.Add(48 * time.Hour), wantKid: "new", wantKey: newKey, wantIDs: []string{"new"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewAssertionSigner("123", keys, WithAssertionClock(ClockFunc(func() time.Time { return tt.now })))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertion, err := s.Sign()
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if header, _ := verifyAssertion(t, assertion, &tt.wantKey.PublicKey); header["kid"] != tt.wantKid {
+				t.Errorf("kid = %s, want %s", header["kid"], tt.wantKid)
+			}
+			if got := s.KeyIDs(); strings.Join(got, ",") != strings.Join(tt.wantIDs, ",") {
+				t.Errorf("KeyIDs() = %v, want %v", got, tt.wantIDs)
+			}
+		})
+	}
+
+	t.Run("no active key", func(t *testing.T) {
+		s, err := NewAssertionSigner("123", keys[1:], WithAssertionClock(ClockFunc(func() time.Time { return start })))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Sign(); err != ErrNoActiveAssertionKey {
+			t.Errorf("Sign() error = %v, want ErrNoActiveAssertionKey", err)
+		}
+		if _, err := s.SignWithKeyID("new"); err == nil {
+			t.Error("SignWithKeyID() of the inactive key succeeded")
+		}
+	})
+}
+
+func TestAssertionSignerExpiryHook(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	now := start
+	clock := ClockFunc(func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	})
+	var notified []string
+	s, err := NewAssertionSigner("123",
+		[]AssertionKey{{KeyID: "kid1", PrivateKey: generateRSAKey(t), NotAfter: start.Add(48 * time.Hour)}},
+		WithAssertionClock(clock),
+		WithKeyExpiryHook(24*time.Hour, func(k AssertionKey) {
+			mu.Lock()
+			notified = append(notified, k.KeyID)
+			mu.Unlock()
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Not within the threshold yet
+	if _, err := s.Sign(); err != nil {
+		t.Fatal(err)
+	}
+	if len(notified) != 0 {
+		t.Fatalf("hook called %d times before the threshold", len(notified))
+	}
+
+	mu.Lock()
+	now = start.Add(30 * time.Hour)
+	mu.Unlock()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.Sign(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if len(notified) != 1 || notified[0] != "kid1" {
+		t.Errorf("hook called for %v, want once for kid1", notified)
+	}
+}
+
+func TestParseAssertionPrivateKey(t *testing.T) {
+	key := generateRSAKey(t)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecPKCS8, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b64 := func(i *big.Int) string { return base64.RawURLEncoding.EncodeToString(i.Bytes()) }
+	jwk := func(modify func(m map[string]string)) []byte {
+		m := map[string]string{
+			"kty": "RSA",
+			"n":   b64(key.N),
+			"e":   b64(big.NewInt(int64(key.E))),
+			"d":   b64(key.D),
+			"p":   b64(key.Primes[0]),
+			"q":   b64(key.Primes[1]),
+		}
+		if modify != nil {
+			modify(m)
+		}
+		b, _ := json.Marshal(m)
+		return b
+	}
+
+	tests := []struct {
+		name    string
+		in      []byte
+		wantErr bool
+	}{
+		{name: "PKCS#1", in: pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})},
+		{name: "PKCS#8", in: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})},
+		{name: "JWK", in: jwk(nil)},
+		{name: "PKCS#8 not RSA", in: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: ecPKCS8}), wantErr: true},
+		{name: "broken PEM", in: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("broken")}), wantErr: true},
+		{name: "JWK not RSA", in: jwk(func(m map[string]string) { m["kty"] = "EC" }), wantErr: true},
+		{name: "JWK without d", in: jwk(func(m map[string]string) { delete(m, "d") }), wantErr: true},
+		{name: "JWK invalid base64", in: jwk(func(m map[string]string) { m["p"] = "!!" }), wantErr: true},
+		{name: "JWK inconsistent", in: jwk(func(m map[string]string) { m["d"] = b64(big.NewInt(3)) }), wantErr: true},
+		{name: "not a key", in: []byte("not a key"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAssertionPrivateKey(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("ParseAssertionPrivateKey() succeeded")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAssertionPrivateKey() error = %v", err)
+			}
+			if !got.Equal(key) {
+				t.Error("parsed key does not equal the original")
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package goline
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+// AuditDecision is the result of authentication
+type AuditDecision string
+
+const (
+	AuditDecisionAllow AuditDecision = "allow"
+	AuditDecisionDeny  AuditDecision = "deny"
+)
+
+// AuditEvent is an authentication event passed to AuditHook
+type AuditEvent struct {
+	Time     time.Time
+	UserID   string
+	RemoteIP string
+	Method   string
+	Path     string
+	// CorrelationID is the ID taken from the header set by WithCorrelationIDHeader
+	CorrelationID string
+	Decision      AuditDecision
+	// Reason is empty when allowed
+	Reason AuthFailure
+	// Err is the verification error when denied
+	Err error
+}
+
+// AuditHook is invoked on every authentication success and failure.
+// It is called synchronously in the request, so implementations should not block for long.
+type AuditHook interface {
+	Audit(ctx context.Context, e *AuditEvent)
+}
+
+// AuditHookFunc is an adapter to use a function as AuditHook
+type AuditHookFunc func(ctx context.Context, e *AuditEvent)
+
+// Audit implements AuditHook
+func (f AuditHookFunc) Audit(ctx context.Context, e *AuditEvent) {
+	f(ctx, e)
+}
+
+// WithAuditHook sets AuditHook e.g. to stream the authentication decisions to SIEM
+func WithAuditHook(h AuditHook) AuthorizerOption {
+	return func(a *Authorizer) {
+		a.audit = h
+	}
+}
+
+func (a *Authorizer) auditAllow(r *http.Request, u *User) {
+	if a.audit == nil {
+		return
+	}
+	e := a.newAuditEvent(r, AuditDecisionAllow)
+	e.UserID = u.ID
+	a.audit.Audit(r.Context(), e)
+}
+
+func (a *Authorizer) auditDeny(r *http.Request, f AuthFailure, err error) {
+	if a.audit == nil {
+		return
+	}
+	e := a.newAuditEvent(r, AuditDecisionDeny)
+	e.Reason = f
+	e.Err = err
+	a.audit.Audit(r.Context(), e)
+}
+
+func (a *Authorizer) newAuditEvent(r *http.Request, d AuditDecision) *AuditEvent {
+	return &AuditEvent{
+		Time:          a.lineClient.clock.Now(),
+		RemoteIP:      remoteIP(r),
+		Method:        r.Method,
+		Path:          r.URL.Path,
+		CorrelationID: CorrelationIDFromContext(r.Context()),
+		Decision:      d,
+	}
+}
+
+// remoteIP returns the IP address of the peer. Forwarded headers are not trusted.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
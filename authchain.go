@@ -0,0 +1,240 @@
+package goline
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Names of the stages of AuthChain
+const (
+	StageExtract  = "extract"
+	StageVerify   = "verify"
+	StageValidate = "validate"
+	StageEnrich   = "enrich"
+	StageInject   = "inject"
+)
+
+// AuthState is the state of the request passed through the stages of AuthChain
+type AuthState struct {
+	// Request is the request being authenticated. The inject stage replaces it with the request having the user.
+	Request *http.Request
+	// Token is set by the extract stage
+	Token string
+	// User is set by the verify stage
+	User *User
+}
+
+// AuthStage is a named stage of AuthChain. Run returns an error to reject the request.
+type AuthStage struct {
+	Name string
+	Run  func(ctx context.Context, s *AuthState) error
+}
+
+// AuthChain is the authentication middleware composed of the stages
+// extract → verify → validate → enrich → inject, so that the stages can be reordered or replaced without forking the middleware.
+// There is no caching stage: caching is delegated to the Client. The verify stages of the Authorizer call the Client,
+// which caches the verification results when created with WithCache, so the cache is shared with the other middlewares.
+// LocalVerifyStage is not cached as it does not call LINE. AuthChain is immutable and the methods return a new chain.
+//
+//	chain := goline.NewAuthChain(lineAuth, lineAuth.IDTokenStages()...).
+//		Replace(goline.StageVerify, goline.LocalVerifyStage(verifier))
+//	mux.Handle("/api/", chain.Middleware(api))
+type AuthChain struct {
+	a      *Authorizer
+	stages []AuthStage
+}
+
+// NewAuthChain returns AuthChain of the stages. The rejections are responded, audited and logged by the Authorizer.
+func NewAuthChain(a *Authorizer, stages ...AuthStage) *AuthChain {
+	return &AuthChain{a: a, stages: append([]AuthStage(nil), stages...)}
+}
+
+// Stages returns the names of the stages in order
+func (c *AuthChain) Stages() []string {
+	names := make([]string, len(c.stages))
+	for i, s := range c.stages {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// with returns a new chain with the stages modified by fn
+func (c *AuthChain) with(fn func(stages []AuthStage) []AuthStage) *AuthChain {
+	return &AuthChain{a: c.a, stages: fn(append([]AuthStage(nil), c.stages...))}
+}
+
+func (c *AuthChain) index(name string) int {
+	for i, s := range c.stages {
+		if s.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Replace returns a new chain replacing the stage of the name. The chain is not changed when not found.
+func (c *AuthChain) Replace(name string, stage AuthStage) *AuthChain {
+	i := c.index(name)
+	return c.with(func(stages []AuthStage) []AuthStage {
+		if i >= 0 {
+			stages[i] = stage
+		}
+		return stages
+	})
+}
+
+// InsertBefore returns a new chain inserting the stage before the stage of the name, or at the end when not found
+func (c *AuthChain) InsertBefore(name string, stage AuthStage) *AuthChain {
+	i := c.index(name)
+	return c.with(func(stages []AuthStage) []AuthStage {
+		if i < 0 {
+			return append(stages, stage)
+		}
+		return append(stages[:i], append([]AuthStage{stage}, stages[i:]...)...)
+	})
+}
+
+// InsertAfter returns a new chain inserting the stage after the stage of the name, or at the end when not found
+func (c *AuthChain) InsertAfter(name string, stage AuthStage) *AuthChain {
+	i := c.index(name)
+	return c.with(func(stages []AuthStage) []AuthStage {
+		if i < 0 {
+			return append(stages, stage)
+		}
+		return append(stages[:i+1], append([]AuthStage{stage}, stages[i+1:]...)...)
+	})
+}
+
+// Remove returns a new chain without the stage of the name
+func (c *AuthChain) Remove(name string) *AuthChain {
+	i := c.index(name)
+	return c.with(func(stages []AuthStage) []AuthStage {
+		if i < 0 {
+			return stages
+		}
+		return append(stages[:i], stages[i+1:]...)
+	})
+}
+
+// Middleware returns a middleware running the stages in order.
+// It responds 401 Unauthorized when a stage fails, or 500 Internal Server Error when the enrichment fails.
+func (c *AuthChain) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, log := c.a.withCorrelationID(r, c.a.log.With("handler", "AuthChain"))
+
+		start := c.a.lineClient.clock.Now()
+		s := &AuthState{Request: r}
+		for _, stage := range c.stages {
+			if err := stage.Run(s.Request.Context(), s); err != nil {
+				log := log.With("stage", stage.Name)
+				if errors.Is(err, ErrEnrichmentFailed) {
+					c.a.recordDecision(s.Request.Context(), log, s.User, "", err, start)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				c.a.deny(w, s.Request, log, classifyAuthFailure(err), err, start)
+				return
+			}
+		}
+		if s.User == nil {
+			c.a.deny(w, s.Request, log, AuthFailureTokenRejected, errors.New("no stage verified the user"), start)
+			return
+		}
+		c.a.recordDecision(s.Request.Context(), log, s.User, "", nil, start)
+		c.a.auditAllow(s.Request, s.User)
+		c.a.setExpiresInHeader(w.Header(), s.User)
+		next.ServeHTTP(w, s.Request)
+	})
+}
+
+// IDTokenStages returns the stages of VerifyIDTokenMiddleware
+func (a *Authorizer) IDTokenStages() []AuthStage {
+	return []AuthStage{ExtractBearerStage(), a.VerifyIDTokenStage(), a.ValidateStage(), a.EnrichStage(), a.InjectStage()}
+}
+
+// AccessTokenStages returns the stages of VerifyAccessTokenMiddleware
+func (a *Authorizer) AccessTokenStages() []AuthStage {
+	return []AuthStage{ExtractBearerStage(), a.VerifyAccessTokenStage(), a.ValidateStage(), a.EnrichStage(), a.InjectStage()}
+}
+
+// ExtractBearerStage returns the extract stage taking the bearer token in authorization header
+func ExtractBearerStage() AuthStage {
+	return AuthStage{Name: StageExtract, Run: func(ctx context.Context, s *AuthState) error {
+		token, err := extractBearerToken(s.Request.Header.Get(authHeader))
+		if err != nil {
+			return err
+		}
+		s.Token = token
+		return nil
+	}}
+}
+
+// VerifyIDTokenStage returns the verify stage verifying the ID token upstream by the Client of the Authorizer
+func (a *Authorizer) VerifyIDTokenStage() AuthStage {
+	return AuthStage{Name: StageVerify, Run: func(ctx context.Context, s *AuthState) error {
+		u, err := a.verifyIDToken(ctx, s.Token)
+		if err != nil {
+			return err
+		}
+		s.User = u
+		return nil
+	}}
+}
+
+// VerifyAccessTokenStage returns the verify stage verifying the access token upstream by the Client of the Authorizer
+func (a *Authorizer) VerifyAccessTokenStage() AuthStage {
+	return AuthStage{Name: StageVerify, Run: func(ctx context.Context, s *AuthState) error {
+		u, err := a.verifyAccessToken(ctx, s.Token)
+		if err != nil {
+			return err
+		}
+		s.User = u
+		return nil
+	}}
+}
+
+// LocalVerifyStage returns the verify stage verifying the ID token locally by LocalVerifier
+func LocalVerifyStage(v *LocalVerifier) AuthStage {
+	return AuthStage{Name: StageVerify, Run: func(ctx context.Context, s *AuthState) error {
+		d, err := v.Verify(ctx, s.Token, nil)
+		if err != nil {
+			return err
+		}
+		s.User = userFromIDToken(d)
+		return nil
+	}}
+}
+
+// ValidateStage returns the validate stage running the validators set by WithValidators
+func (a *Authorizer) ValidateStage() AuthStage {
+	return AuthStage{Name: StageValidate, Run: func(ctx context.Context, s *AuthState) error {
+		if s.User == nil {
+			return errors.New("user not verified")
+		}
+		return a.validate(ctx, s.Token, s.User)
+	}}
+}
+
+// EnrichStage returns the enrich stage running the enricher set by WithEnricher
+func (a *Authorizer) EnrichStage() AuthStage {
+	return AuthStage{Name: StageEnrich, Run: func(ctx context.Context, s *AuthState) error {
+		if s.User == nil {
+			return errors.New("user not verified")
+		}
+		return a.enrich(ctx, s.User)
+	}}
+}
+
+// InjectStage returns the inject stage setting the user info headers and the user in the request context
+func (a *Authorizer) InjectStage() AuthStage {
+	return AuthStage{Name: StageInject, Run: func(ctx context.Context, s *AuthState) error {
+		if s.User == nil {
+			return errors.New("user not verified")
+		}
+		a.checkSoftExpiry(ctx, s.User)
+		a.setUserHeaders(s.Request.Header, s.User)
+		s.Request = s.Request.WithContext(SetUser(ctx, s.User))
+		return nil
+	}}
+}
@@ -0,0 +1,71 @@
+package goline
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAuthChainStages(t *testing.T) {
+	a := NewAuthorizer(NewClient("123", nil), nil)
+	chain := NewAuthChain(a, a.IDTokenStages()...)
+	if got, want := chain.Stages(), []string{StageExtract, StageVerify, StageValidate, StageEnrich, StageInject}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Stages() = %v, want %v", got, want)
+	}
+
+	custom := AuthStage{Name: "custom"}
+	tests := []struct {
+		name  string
+		chain *AuthChain
+		want  []string
+	}{
+		{name: "replace", chain: chain.Replace(StageVerify, custom), want: []string{StageExtract, "custom", StageValidate, StageEnrich, StageInject}},
+		{name: "insert before", chain: chain.InsertBefore(StageValidate, custom), want: []string{StageExtract, StageVerify, "custom", StageValidate, StageEnrich, StageInject}},
+		{name: "insert after", chain: chain.InsertAfter(StageInject, custom), want: []string{StageExtract, StageVerify, StageValidate, StageEnrich, StageInject, "custom"}},
+		{name: "remove", chain: chain.Remove(StageEnrich), want: []string{StageExtract, StageVerify, StageValidate, StageInject}},
+		{name: "not found", chain: chain.Remove("unknown").Replace("unknown", custom), want: chain.Stages()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.chain.Stages(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Stages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	// The original chain is not changed
+	if n := len(chain.Stages()); n != 5 {
+		t.Errorf("the original chain has %d stages", n)
+	}
+}
+
+// TestAuthChainCachedByClient checks the caching of the verification is delegated to the Client
+func TestAuthChainCachedByClient(t *testing.T) {
+	var calls atomic.Int32
+	hc := inMemoryLINE()
+	base := hc.Transport
+	hc.Transport = roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		return base.RoundTrip(r)
+	})
+	a := NewAuthorizer(NewClient("123", hc, WithCache(time.Minute, 10)), nil)
+	var user *User
+	h := NewAuthChain(a, a.IDTokenStages()...).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _ = UserFromContext(r.Context())
+	}))
+
+	for i := 0; i < 3; i++ {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", "Bearer token")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusOK || user == nil || user.ID != "U1" {
+			t.Fatalf("status = %d, user = %+v", w.Code, user)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("%d requests to LINE, want 1 cached by the Client", n)
+	}
+}
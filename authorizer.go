@@ -3,11 +3,9 @@ package goline
 import (
 	"context"
 	"errors"
-	"fmt"
+	"log/slog"
 	"net/http"
-	"strings"
-
-	"github.com/go-logr/logr"
+	"time"
 )
 
 const (
@@ -18,103 +16,344 @@ const (
 	HeaderKeyLINEStatusMessage = "LINEStatusMessage"
 )
 
-// Authorizer is a clientset of LINE Auth API
+// HeaderNames is a set of request header names to inject the authorized LINE user info
+type HeaderNames struct {
+	UserID        string
+	DisplayName   string
+	PictureURL    string
+	Email         string
+	StatusMessage string
+}
+
+// DefaultHeaderNames is the default header names
+var DefaultHeaderNames = HeaderNames{
+	UserID:        HeaderKeyLINEUserID,
+	DisplayName:   HeaderKeyLINEDisplayName,
+	PictureURL:    HeaderKeyLINEPictureURL,
+	Email:         HeaderKeyLINEEmail,
+	StatusMessage: HeaderKeyLINEStatusMessage,
+}
+
+// WithPrefix returns the header names with the prefix e.g. "X-"
+func (h HeaderNames) WithPrefix(prefix string) HeaderNames {
+	return HeaderNames{
+		UserID:        prefix + h.UserID,
+		DisplayName:   prefix + h.DisplayName,
+		PictureURL:    prefix + h.PictureURL,
+		Email:         prefix + h.Email,
+		StatusMessage: prefix + h.StatusMessage,
+	}
+}
+
+// All returns all header names
+func (h HeaderNames) All() []string {
+	return []string{h.UserID, h.DisplayName, h.PictureURL, h.Email, h.StatusMessage}
+}
+
+// Authorizer is a clientset of LINE Auth API.
+// Authorizer is safe for concurrent use by multiple goroutines. The configuration is immutable after NewAuthorizer returns.
 type Authorizer struct {
 	lineClient *Client
-	log        logr.Logger
+	log        *slog.Logger
+	headers    HeaderNames
+	encoding   HeaderEncoding
+	problem    bool
+	realm      string
+	audit      AuditHook
+
+	claimHeaders ClaimMapping
+	clockSkew    time.Duration
+	channels     map[string]*Client
+	validators   []Validator
+	liffClaims   []string
+
+	enricher          Enricher
+	enrichmentTTL     time.Duration
+	enrichmentHeaders []string
+	enrichments       *verificationCache
+
+	correlationHeader string
+
+	dev *devUser
+
+	expiresInHeader bool
+	softExpiry      time.Duration
+	onSoftExpiry    func(ctx context.Context, u *User, remaining time.Duration)
+
+	degradation         DegradationPolicy
+	degradationVerifier *LocalVerifier
+	lastVerified        *verificationCache
+}
+
+// AuthorizerOption configures Authorizer
+type AuthorizerOption func(*Authorizer)
+
+// WithHeaderNames sets the request header names to inject the authorized LINE user info
+func WithHeaderNames(h HeaderNames) AuthorizerOption {
+	return func(a *Authorizer) {
+		a.headers = h
+	}
+}
+
+// WithHeaderPrefix adds the prefix to the request header names e.g. "X-" for "X-LINEUserID".
+// It is applied to the header names set by WithHeaderNames when specified before it.
+func WithHeaderPrefix(prefix string) AuthorizerOption {
+	return func(a *Authorizer) {
+		a.headers = a.headers.WithPrefix(prefix)
+	}
 }
 
 // NewAuthorizer return new Authorizer
-func NewAuthorizer(lineClient *Client, log logr.Logger) *Authorizer {
-	return &Authorizer{lineClient: lineClient, log: log.WithName("goline.Authorizer")}
+func NewAuthorizer(lineClient *Client, log *slog.Logger, opts ...AuthorizerOption) *Authorizer {
+	a := &Authorizer{
+		lineClient: lineClient,
+		log:        newLogger(log).With("logger", "goline.Authorizer"),
+		headers:    DefaultHeaderNames,
+		clockSkew:  defaultClockSkew,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
 }
 
-// VerifyIDTokenMiddleware is a middleware of http handler
-// Obtain id token from authorization header and verify it upstream
-// The authorized LINE user info is set in request headers "LINEUserID", "LINEDisplayName", "LINEPictureURL", "LINEEmail"
-func (a *Authorizer) VerifyIDTokenMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log := a.log.WithName("VerifyAccessTokenMiddleware")
-		ctx := context.TODO()
+// resetUserHeaders removes the user info headers sent by the client not to be spoofed
+func (a *Authorizer) resetUserHeaders(h http.Header) {
+	for _, k := range a.headers.All() {
+		h.Del(k)
+	}
+	for _, k := range a.claimHeaders {
+		h.Del(k)
+	}
+	for _, k := range a.enrichmentHeaders {
+		h.Del(k)
+	}
+}
 
-		authHeader := r.Header.Get(authHeader)
-		if authHeader == "" {
-			log.Error(errors.New("innvalid header"), "bearer token not found in authorization header")
-			w.WriteHeader(http.StatusUnauthorized)
-			return
+// setUserHeaders sets the user info in headers. Empty fields and empty header names are not set.
+func (a *Authorizer) setUserHeaders(h http.Header, u *User) {
+	a.resetUserHeaders(h)
+	set := func(k, v string) {
+		if k != "" && v != "" {
+			h.Set(k, v)
 		}
-		idToken, err := extractBearerToken(authHeader)
-		if err != nil {
-			log.Error(err, "failed to extract token form bearer")
-			w.WriteHeader(http.StatusUnauthorized)
-			return
+	}
+	set(a.headers.UserID, u.ID)
+	set(a.headers.DisplayName, a.encoding.Encode(u.DisplayName))
+	set(a.headers.PictureURL, u.PictureURL)
+	set(a.headers.Email, u.Email)
+	set(a.headers.StatusMessage, a.encoding.Encode(u.StatusMessage))
+	for claim, k := range a.claimHeaders {
+		if v, ok := u.Claims[claim]; ok {
+			set(k, a.encoding.Encode(claimString(v)))
 		}
+	}
+	a.setEnrichmentHeaders(h, u.Enrichment)
+}
 
-		p, err := a.lineClient.VerifyIDToken(ctx, idToken, "", "")
-		if err != nil || p == nil {
-			log.Error(err, "failed to verify id token", "profile", p)
-			w.WriteHeader(http.StatusUnauthorized)
-			return
+// UserHeaders returns the headers to inject the user info with the configured names and encoding.
+// It is used by adapters for external authorization servers.
+func (a *Authorizer) UserHeaders(u *User) http.Header {
+	h := make(http.Header)
+	a.setUserHeaders(h, u)
+	return h
+}
+
+// HeaderNames returns the configured header names
+func (a *Authorizer) HeaderNames() HeaderNames {
+	return a.headers
+}
+
+// AuthenticateIDToken verifies the ID token upstream and returns the LINE user
+func (a *Authorizer) AuthenticateIDToken(ctx context.Context, idToken string) (*User, error) {
+	u, err := a.verifyIDToken(ctx, idToken)
+	if err != nil {
+		return nil, err
+	}
+	return a.completeUser(ctx, idToken, u)
+}
+
+// AuthenticateAccessToken verifies the access token upstream and returns the LINE user with the profile
+func (a *Authorizer) AuthenticateAccessToken(ctx context.Context, accessToken string) (*User, error) {
+	u, err := a.verifyAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	return a.completeUser(ctx, accessToken, u)
+}
+
+// verifyIDToken verifies the ID token upstream and returns the LINE user without validators and enrichment
+func (a *Authorizer) verifyIDToken(ctx context.Context, idToken string) (*User, error) {
+	if a.dev != nil {
+		return a.authenticateDev(idToken)
+	}
+	p, err := a.clientForIDToken(idToken).VerifyIDToken(ctx, idToken, nil)
+	if err != nil {
+		if u, ok := a.degrade(ctx, "id_token", idToken, err); ok {
+			return u, nil
 		}
+		return nil, err
+	}
+	u := userFromIDToken(p)
+	a.rememberVerified("id_token", idToken, u)
+	return u, nil
+}
 
-		r.Header.Add(HeaderKeyLINEUserID, p.Sub)
-		r.Header.Add(HeaderKeyLINEDisplayName, p.Name)
-		r.Header.Add(HeaderKeyLINEPictureURL, p.Picutre)
-		r.Header.Add(HeaderKeyLINEEmail, p.Email)
+// verifyAccessToken verifies the access token upstream and returns the LINE user without validators and enrichment
+func (a *Authorizer) verifyAccessToken(ctx context.Context, accessToken string) (*User, error) {
+	if a.dev != nil {
+		return a.authenticateDev(accessToken)
+	}
+	// first verify access token to check client ID
+	v, err := a.lineClient.VerifyAccessToken(ctx, accessToken)
+	if err != nil {
+		if u, ok := a.degrade(ctx, "access_token", accessToken, err); ok {
+			return u, nil
+		}
+		return nil, err
+	}
+	p, err := a.lineClient.GetProfile(ctx, accessToken)
+	if err != nil {
+		if u, ok := a.degrade(ctx, "access_token", accessToken, err); ok {
+			return u, nil
+		}
+		return nil, err
+	}
+	u := &User{
+		ID:            p.UserID,
+		DisplayName:   p.DisplayName,
+		PictureURL:    p.PictureURL,
+		StatusMessage: p.StatusMessage,
+		ExpiresAt:     v.ExpiresAt(),
+		Claims: map[string]interface{}{
+			"userId":        p.UserID,
+			"displayName":   p.DisplayName,
+			"pictureUrl":    p.PictureURL,
+			"statusMessage": p.StatusMessage,
+		},
+	}
+	a.rememberVerified("access_token", accessToken, u)
+	return u, nil
+}
 
-		next.ServeHTTP(w, r)
-	})
+// userFromIDToken returns the LINE user of the verified ID token
+func userFromIDToken(p *IDTokenData) *User {
+	return &User{
+		ID:          p.Sub,
+		DisplayName: p.Name,
+		PictureURL:  p.Picture,
+		Email:       p.Email,
+		AMR:         p.Amr,
+		AuthTime:    authTime(p),
+		ExpiresAt:   time.Unix(p.Exp, 0),
+		Claims:      p.claims,
+	}
+}
+
+// completeUser runs the validators and the enricher for the verified user
+func (a *Authorizer) completeUser(ctx context.Context, token string, u *User) (*User, error) {
+	if err := a.validate(ctx, token, u); err != nil {
+		return nil, err
+	}
+	if err := a.enrich(ctx, u); err != nil {
+		return nil, err
+	}
+	a.checkSoftExpiry(ctx, u)
+	return u, nil
+}
+
+// authenticateFunc is AuthenticateIDToken or AuthenticateAccessToken
+type authenticateFunc func(ctx context.Context, token string) (*User, error)
+
+// authenticate authenticates the request by the bearer token in authorization header.
+// It writes 401 Unauthorized and returns nil when failed.
+func (a *Authorizer) authenticate(w http.ResponseWriter, r *http.Request, log *slog.Logger, fn authenticateFunc) *User {
+	start := a.lineClient.clock.Now()
+	token, err := extractBearerToken(r.Header.Get(authHeader))
+	if err != nil {
+		// ErrBearerTokenNotFound or ErrBearerTokenMalformed
+		a.deny(w, r, log, classifyAuthFailure(err), err, start)
+		return nil
+	}
+
+	u, err := fn(r.Context(), token)
+	if errors.Is(err, ErrEnrichmentFailed) {
+		a.recordDecision(r.Context(), log, nil, "", err, start)
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil
+	}
+	if err != nil {
+		a.deny(w, r, log, classifyAuthFailure(err), err, start)
+		return nil
+	}
+	a.recordDecision(r.Context(), log, u, "", nil, start)
+	a.auditAllow(r, u)
+	return u
+}
+
+// deny logs, audits and responds 401 Unauthorized
+func (a *Authorizer) deny(w http.ResponseWriter, r *http.Request, log *slog.Logger, f AuthFailure, err error, start time.Time) {
+	a.recordDecision(r.Context(), log, nil, f, err, start)
+	a.auditDeny(r, f, err)
+	a.unauthorized(w, r, f, err)
+}
+
+// VerifyIDTokenMiddleware is a middleware of http handler
+// Obtain id token from authorization header and verify it upstream
+// The authorized LINE user info is set in request headers "LINEUserID", "LINEDisplayName", "LINEPictureURL", "LINEEmail"
+// by default. The header names can be changed by WithHeaderNames or WithHeaderPrefix.
+// The user is also available by UserFromContext.
+func (a *Authorizer) VerifyIDTokenMiddleware(next http.Handler) http.Handler {
+	return a.middleware("VerifyIDTokenMiddleware", a.AuthenticateIDToken, next)
 }
 
 // VerifyAccessTokenMiddleware is a middleware of http handler
 // Obtain access token from authorization header and verify it upstream
 // The authorized LINE user info is set in request headers "LINEUserID", "LINEDisplayName", "LINEPictureURL", "LINEStatusMessage"
+// by default. The header names can be changed by WithHeaderNames or WithHeaderPrefix.
+// The user is also available by UserFromContext.
 func (a *Authorizer) VerifyAccessTokenMiddleware(next http.Handler) http.Handler {
+	return a.middleware("VerifyAccessTokenMiddleware", a.AuthenticateAccessToken, next)
+}
+
+func (a *Authorizer) middleware(name string, fn authenticateFunc, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log := a.log.WithName("VerifyAccessTokenMiddleware")
-		ctx := context.TODO()
+		r, log := a.withCorrelationID(r, a.log.With("handler", name))
 
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			log.Error(errors.New("innvalid header"), "bearer token not found in authorization header")
-			w.WriteHeader(http.StatusUnauthorized)
+		u := a.authenticate(w, r, log, fn)
+		if u == nil {
 			return
 		}
+		a.setUserHeaders(r.Header, u)
+		a.setExpiresInHeader(w.Header(), u)
 
-		accessToken, err := extractBearerToken(authHeader)
-		if err != nil {
-			log.Error(err, "failed to extract token form bearer")
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
+		next.ServeHTTP(w, r.WithContext(SetUser(r.Context(), u)))
+	})
+}
 
-		// first verify access token to check client ID
-		if _, err := a.lineClient.VerifyAccessToken(ctx, accessToken); err != nil {
-			log.Error(err, "failed to verify access token")
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
+// IDTokenAuthHandler returns a forward-auth handler for ingress proxies such as NGINX auth_request,
+// Traefik ForwardAuth or Envoy ext_authz HTTP service.
+// It verifies the ID token in authorization header of the original request
+// and responds 200 OK with the LINE user info headers, or 401 Unauthorized.
+func (a *Authorizer) IDTokenAuthHandler() http.Handler {
+	return a.authHandler("IDTokenAuthHandler", a.AuthenticateIDToken)
+}
 
-		p, err := a.lineClient.GetProfile(ctx, accessToken)
-		if err != nil || p == nil {
-			log.Error(err, "failed to get profile", "profile", p)
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
+// AccessTokenAuthHandler returns a forward-auth handler same as IDTokenAuthHandler verifying the access token
+func (a *Authorizer) AccessTokenAuthHandler() http.Handler {
+	return a.authHandler("AccessTokenAuthHandler", a.AuthenticateAccessToken)
+}
 
-		r.Header.Add(HeaderKeyLINEUserID, p.UserID)
-		r.Header.Add(HeaderKeyLINEDisplayName, p.DisplayName)
-		r.Header.Add(HeaderKeyLINEPictureURL, p.PictureURL)
-		r.Header.Add(HeaderKeyLINEStatusMessage, p.StatusMessage)
+func (a *Authorizer) authHandler(name string, fn authenticateFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, log := a.withCorrelationID(r, a.log.With("handler", name))
 
-		next.ServeHTTP(w, r)
+		u := a.authenticate(w, r, log, fn)
+		if u == nil {
+			return
+		}
+		a.setUserHeaders(w.Header(), u)
+		a.setExpiresInHeader(w.Header(), u)
+		w.WriteHeader(http.StatusOK)
 	})
 }
-
-func extractBearerToken(authHeader string) (string, error) {
-	arr := strings.Split(authHeader, "Bearer ")
-	if len(arr) != 2 {
-		return "", fmt.Errorf("not bearer")
-	}
-	return arr[1], nil
-}
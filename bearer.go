@@ -0,0 +1,64 @@
+package goline
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrBearerTokenNotFound is returned when no token is in authorization header
+	ErrBearerTokenNotFound = errors.New("bearer token not found")
+	// ErrBearerTokenMalformed is returned when authorization header is not a valid bearer credential
+	ErrBearerTokenMalformed = errors.New("bearer token malformed")
+)
+
+const bearerScheme = "Bearer"
+
+// ParseBearerToken returns the token in the value of authorization header by RFC 6750.
+// The scheme is case-insensitive and the whitespaces around the scheme and the token are allowed.
+// ErrBearerTokenNotFound is returned when the header is empty, and ErrBearerTokenMalformed when the scheme is not Bearer,
+// the token is empty or has characters not allowed in b64token, or the header has multiple credentials or auth params.
+// https://www.rfc-editor.org/rfc/rfc6750#section-2.1
+func ParseBearerToken(authHeader string) (string, error) {
+	return extractBearerToken(authHeader)
+}
+
+func extractBearerToken(authHeader string) (string, error) {
+	h := strings.Trim(authHeader, " \t")
+	if h == "" {
+		return "", ErrBearerTokenNotFound
+	}
+	i := strings.IndexAny(h, " \t")
+	if i < 0 {
+		return "", fmt.Errorf("%w: token not found", ErrBearerTokenMalformed)
+	}
+	if !strings.EqualFold(h[:i], bearerScheme) {
+		return "", fmt.Errorf("%w: not bearer", ErrBearerTokenMalformed)
+	}
+	token := strings.TrimLeft(h[i:], " \t")
+	if !isB64Token(token) {
+		// Do not include the token in the error not to leak it
+		return "", fmt.Errorf("%w: invalid token characters", ErrBearerTokenMalformed)
+	}
+	return token, nil
+}
+
+// isB64Token reports whether s is b64token of RFC 6750:
+// 1*( ALPHA / DIGIT / "-" / "." / "_" / "~" / "+" / "/" ) *"="
+func isB64Token(s string) bool {
+	body := strings.TrimRight(s, "=")
+	if body == "" {
+		return false
+	}
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '-', c == '.', c == '_', c == '~', c == '+', c == '/':
+		default:
+			return false
+		}
+	}
+	return true
+}
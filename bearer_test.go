@@ -0,0 +1,86 @@
+package goline
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseBearerToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+		err    error
+	}{
+		{name: "bearer", header: "Bearer abc.DEF-123_~+/", want: "abc.DEF-123_~+/"},
+		{name: "padding", header: "Bearer abc==", want: "abc=="},
+		{name: "lower case scheme", header: "bearer abc", want: "abc"},
+		{name: "upper case scheme", header: "BEARER abc", want: "abc"},
+		{name: "mixed case scheme", header: "bEaReR abc", want: "abc"},
+		{name: "surrounding whitespaces", header: " \tBearer abc\t ", want: "abc"},
+		{name: "multiple whitespaces after scheme", header: "Bearer  \t abc", want: "abc"},
+		{name: "empty header", header: "", err: ErrBearerTokenNotFound},
+		{name: "whitespaces only", header: " \t ", err: ErrBearerTokenNotFound},
+		{name: "scheme only", header: "Bearer", err: ErrBearerTokenMalformed},
+		{name: "scheme and whitespaces only", header: "Bearer   ", err: ErrBearerTokenMalformed},
+		{name: "padding only", header: "Bearer ==", err: ErrBearerTokenMalformed},
+		{name: "basic scheme", header: "Basic dXNlcjpwYXNz", err: ErrBearerTokenMalformed},
+		{name: "token without scheme", header: "abc", err: ErrBearerTokenMalformed},
+		{name: "multiple tokens", header: "Bearer abc def", err: ErrBearerTokenMalformed},
+		{name: "auth params", header: `Bearer realm="example"`, err: ErrBearerTokenMalformed},
+		{name: "comma", header: "Bearer abc,def", err: ErrBearerTokenMalformed},
+		{name: "padding in the middle", header: "Bearer ab=c", err: ErrBearerTokenMalformed},
+		{name: "non ascii", header: "Bearer abcé", err: ErrBearerTokenMalformed},
+		{name: "control character", header: "Bearer abc\x00", err: ErrBearerTokenMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBearerToken(tt.header)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParseBearerToken(%q) error = %v, want %v", tt.header, err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseBearerToken(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBearerTokenErrorDoesNotLeakToken(t *testing.T) {
+	_, err := ParseBearerToken("Bearer secret-token!")
+	if err == nil {
+		t.Fatal("want error")
+	}
+	if strings.Contains(err.Error(), "secret-token") {
+		t.Errorf("error %q contains the token", err)
+	}
+}
+
+func FuzzParseBearerToken(f *testing.F) {
+	for _, s := range []string{"", "Bearer abc", "bearer abc==", " Bearer\tabc ", "Basic abc", "Bearer a b", "Bearer ==", "Bearer é"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, header string) {
+		token, err := ParseBearerToken(header)
+		if err != nil {
+			if !errors.Is(err, ErrBearerTokenNotFound) && !errors.Is(err, ErrBearerTokenMalformed) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if token != "" {
+				t.Fatalf("token %q returned with error", token)
+			}
+			return
+		}
+		if !isB64Token(token) {
+			t.Fatalf("token %q is not b64token", token)
+		}
+		if !strings.Contains(header, token) {
+			t.Fatalf("token %q is not in the header %q", token, header)
+		}
+		// The token parsed from the canonical form is the same
+		if got, err := ParseBearerToken(bearerScheme + " " + token); err != nil || got != token {
+			t.Fatalf("ParseBearerToken(canonical) = %q, %v, want %q", got, err, token)
+		}
+	})
+}
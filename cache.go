@@ -0,0 +1,272 @@
+package goline
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCacheSize        = 10000
+	defaultNegativeCacheTTL = 10 * time.Second
+)
+
+// WithCache caches the verification results of VerifyIDToken and VerifyAccessToken for ttl, up to size entries
+// evicted in LRU order per shard. The result is not cached longer than the token lifetime. Size is 10000 when 0 or less.
+// Definitive rejections are also cached, see WithNegativeCacheTTL.
+func WithCache(ttl time.Duration, size int) ClientOption {
+	return func(c *Client) {
+		c.cacheTTL = ttl
+		c.cacheSize = size
+	}
+}
+
+// WithNegativeCacheTTL sets the duration to cache definitive rejections, the tokens expired, revoked
+// or issued for other channels, not to call LINE repeatedly for clients retrying with a bad token in a tight loop.
+// Default is 10 seconds when WithCache is set. Zero disables negative caching.
+// Transient errors like network errors and 5xx are never cached.
+func WithNegativeCacheTTL(ttl time.Duration) ClientOption {
+	return func(c *Client) {
+		c.negativeTTL = &ttl
+	}
+}
+
+// resultSource tells where the verification result came from
+type resultSource int
+
+const (
+	sourceUpstream resultSource = iota
+	sourceShared
+	sourceCache
+)
+
+// isDefinitiveRejection returns true when the same token will be always rejected
+func isDefinitiveRejection(err error) bool {
+	return errors.Is(err, ErrTokenExpired) ||
+		errors.Is(err, ErrTokenRevoked) ||
+		errors.Is(err, ErrClientIDMismatch) ||
+		errors.Is(err, errAudienceMismatch)
+}
+
+// verificationCache is a LRU cache of the verification results keyed by tokenKey.
+// Both the values and the errors of definitive rejections are cached. It is safe for concurrent use.
+// The cached values are shared by the callers, so they must not be modified.
+//
+// The entries are striped over the shards by the key, each with its own lock and LRU list,
+// not to contend on a single mutex at high RPS. The capacity is split evenly among the shards,
+// so the eviction is LRU per shard.
+type verificationCache struct {
+	shards []cacheShard
+	mask   uint32
+}
+
+const (
+	maxCacheShards = 32
+	// Minimum capacity of a shard. Small caches have less shards to keep the eviction close to LRU.
+	minCacheShardSize = 64
+	// Interval to sweep the expired entries of a shard, which are otherwise removed only on get or eviction
+	cacheSweepInterval = time.Minute
+)
+
+// cacheShard is a LRU list of the entries linked from the most recently used
+type cacheShard struct {
+	mu        sync.Mutex
+	size      int
+	entries   map[string]*cacheEntry
+	head      *cacheEntry
+	tail      *cacheEntry
+	lastSweep time.Time
+
+	// Pad to separate the locks of the shards in different cache lines
+	_ [64]byte
+}
+
+type cacheEntry struct {
+	key        string
+	val        interface{}
+	err        error
+	expiresAt  time.Time
+	prev, next *cacheEntry
+}
+
+func newVerificationCache(size int) *verificationCache {
+	if size <= 0 {
+		size = defaultCacheSize
+	}
+	n := 1
+	for n < maxCacheShards && size/(n*2) >= minCacheShardSize {
+		n *= 2
+	}
+	c := &verificationCache{shards: make([]cacheShard, n), mask: uint32(n - 1)}
+	for i := range c.shards {
+		c.shards[i].size = (size + n - 1) / n
+		c.shards[i].entries = make(map[string]*cacheEntry)
+	}
+	return c
+}
+
+// shard returns the shard of the key by FNV-1a hash
+func (c *verificationCache) shard(key string) *cacheShard {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return &c.shards[h&c.mask]
+}
+
+// get returns the cached result. ok is false when not cached or expired.
+func (c *verificationCache) get(key string, now time.Time) (val interface{}, err error, ok bool) {
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, found := s.entries[key]
+	if !found {
+		return nil, nil, false
+	}
+	if !now.Before(e.expiresAt) {
+		s.remove(e)
+		return nil, nil, false
+	}
+	s.moveToFront(e)
+	return e.val, e.err, true
+}
+
+// set caches the result until expiresAt. The entry evicted at capacity is reused not to allocate.
+func (c *verificationCache) set(key string, val interface{}, err error, now, expiresAt time.Time) {
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastSweep) >= cacheSweepInterval {
+		s.sweep(now)
+	}
+	if e, found := s.entries[key]; found {
+		e.val, e.err, e.expiresAt = val, err, expiresAt
+		s.moveToFront(e)
+		return
+	}
+
+	var e *cacheEntry
+	if len(s.entries) >= s.size {
+		e = s.tail
+		s.remove(e)
+		*e = cacheEntry{}
+	} else {
+		e = &cacheEntry{}
+	}
+	e.key, e.val, e.err, e.expiresAt = key, val, err, expiresAt
+	s.entries[key] = e
+	s.pushFront(e)
+}
+
+// sweep removes the expired entries
+func (s *cacheShard) sweep(now time.Time) {
+	s.lastSweep = now
+	for _, e := range s.entries {
+		if !now.Before(e.expiresAt) {
+			s.remove(e)
+		}
+	}
+}
+
+func (s *cacheShard) pushFront(e *cacheEntry) {
+	e.prev, e.next = nil, s.head
+	if s.head != nil {
+		s.head.prev = e
+	}
+	s.head = e
+	if s.tail == nil {
+		s.tail = e
+	}
+}
+
+func (s *cacheShard) unlink(e *cacheEntry) {
+	if e.prev != nil {
+		e.prev.next = e.next
+	} else {
+		s.head = e.next
+	}
+	if e.next != nil {
+		e.next.prev = e.prev
+	} else {
+		s.tail = e.prev
+	}
+	e.prev, e.next = nil, nil
+}
+
+func (s *cacheShard) moveToFront(e *cacheEntry) {
+	if s.head == e {
+		return
+	}
+	s.unlink(e)
+	s.pushFront(e)
+}
+
+func (s *cacheShard) remove(e *cacheEntry) {
+	s.unlink(e)
+	delete(s.entries, e.key)
+}
+
+// cacheResult caches the verification result. The value is cached until min(now+ttl, exp),
+// and the definitive rejection for the negative cache TTL.
+func (c *Client) cacheResult(key string, val interface{}, exp time.Time, err error) {
+	if c.cache == nil {
+		return
+	}
+	now := c.clock.Now()
+	if err != nil {
+		if c.negativeCacheTTL() > 0 && isDefinitiveRejection(err) {
+			c.cache.set(key, nil, err, now, now.Add(c.negativeCacheTTL()))
+		}
+		return
+	}
+	expiresAt := now.Add(c.cacheTTL)
+	if !exp.IsZero() && exp.Before(expiresAt) {
+		expiresAt = exp
+	}
+	if expiresAt.After(now) {
+		c.cache.set(key, val, nil, now, expiresAt)
+	}
+}
+
+func (c *Client) negativeCacheTTL() time.Duration {
+	if c.negativeTTL == nil {
+		return defaultNegativeCacheTTL
+	}
+	return *c.negativeTTL
+}
+
+// cachedResult returns the cached verification result
+func (c *Client) cachedResult(key string) (interface{}, error, bool) {
+	if c.cache == nil {
+		return nil, nil, false
+	}
+	return c.cache.get(key, c.clock.Now())
+}
+
+// TokenHash returns hex encoded SHA-256 of the token. Use it as the key when caching or logging tokens
+// e.g. in Redis, so that raw tokens never sit in plaintext keys.
+func TokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// tokenKey returns SHA-256 of the kind and the values as the key of the cache and the shared calls,
+// not to keep raw tokens in memory longer than needed
+func tokenKey(kind string, values ...string) string {
+	h := sha256.New()
+	h.Write([]byte(kind))
+	for _, v := range values {
+		h.Write([]byte{0})
+		h.Write([]byte(v))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// equalSecret compares the secret values like tokens and nonces in constant time
+func equalSecret(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,181 @@
+package goline
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestVerificationCache(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	c := newVerificationCache(minCacheShardSize)
+	if len(c.shards) != 1 {
+		t.Fatalf("%d shards, want 1 for the small cache", len(c.shards))
+	}
+
+	c.set("a", "A", nil, now, now.Add(time.Minute))
+	rejected := errors.New("rejected")
+	c.set("b", nil, rejected, now, now.Add(time.Second))
+
+	if v, err, ok := c.get("a", now); !ok || v != "A" || err != nil {
+		t.Errorf("get(a) = %v, %v, %v", v, err, ok)
+	}
+	if _, err, ok := c.get("b", now); !ok || err != rejected {
+		t.Errorf("get(b) = %v, %v, want the cached error", err, ok)
+	}
+	if _, _, ok := c.get("b", now.Add(time.Second)); ok {
+		t.Error("get(b) after expiry, want not found")
+	}
+	if _, _, ok := c.get("unknown", now); ok {
+		t.Error("get(unknown), want not found")
+	}
+
+	// Overwrite
+	c.set("a", "A2", nil, now, now.Add(time.Minute))
+	if v, _, _ := c.get("a", now); v != "A2" {
+		t.Errorf("get(a) = %v, want A2", v)
+	}
+}
+
+func TestVerificationCacheLRU(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	c := newVerificationCache(minCacheShardSize)
+	exp := now.Add(time.Hour)
+	for i := 0; i < minCacheShardSize; i++ {
+		c.set(fmt.Sprint(i), i, nil, now, exp)
+	}
+	// 0 is used recently, so 1 is the least recently used
+	c.get("0", now)
+	c.set("new", "new", nil, now, exp)
+
+	if _, _, ok := c.get("1", now); ok {
+		t.Error("the least recently used entry is not evicted")
+	}
+	for _, k := range []string{"0", "2", "new"} {
+		if _, _, ok := c.get(k, now); !ok {
+			t.Errorf("get(%s), want found", k)
+		}
+	}
+	if n := len(c.shards[0].entries); n != minCacheShardSize {
+		t.Errorf("%d entries, want %d", n, minCacheShardSize)
+	}
+}
+
+func TestVerificationCacheSweep(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	c := newVerificationCache(minCacheShardSize)
+	c.set("short", 1, nil, now, now.Add(time.Second))
+	c.set("long", 2, nil, now, now.Add(time.Hour))
+
+	// The expired entries are removed on set after the sweep interval without get
+	c.set("other", 3, nil, now.Add(cacheSweepInterval), now.Add(time.Hour))
+	s := &c.shards[0]
+	if _, ok := s.entries["short"]; ok {
+		t.Error("expired entry is not swept")
+	}
+	if _, ok := s.entries["long"]; !ok {
+		t.Error("valid entry is swept")
+	}
+}
+
+func TestVerificationCacheShards(t *testing.T) {
+	tests := []struct {
+		size   int
+		shards int
+	}{
+		{size: 1, shards: 1},
+		{size: minCacheShardSize * 2, shards: 2},
+		{size: 0, shards: maxCacheShards},
+		{size: 1 << 20, shards: maxCacheShards},
+	}
+	for _, tt := range tests {
+		c := newVerificationCache(tt.size)
+		if len(c.shards) != tt.shards {
+			t.Errorf("newVerificationCache(%d) has %d shards, want %d", tt.size, len(c.shards), tt.shards)
+		}
+	}
+}
+
+func TestVerificationCacheConcurrent(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	c := newVerificationCache(256)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				k := fmt.Sprint((g*1000 + i) % 512)
+				if v, _, ok := c.get(k, now); ok && v != k {
+					t.Errorf("get(%s) = %v", k, v)
+					return
+				}
+				c.set(k, k, nil, now, now.Add(time.Minute))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// The cache benchmarks measure get and set of 20000 tokens in a cache of 10000, so that half of set evict.
+// The parallel ones show the contention among the shards, run them with -cpu e.g. -cpu 1,4,16.
+//
+//	go test -run XXX -bench VerificationCache -benchmem -cpu 1,4,16 .
+const benchCacheKeys = 20000
+
+// benchCacheValue is the cached value, not to measure boxing the values into interface{}
+var benchCacheValue = &IDTokenData{}
+
+func benchCacheKeySet() []string {
+	keys := make([]string, benchCacheKeys)
+	for i := range keys {
+		keys[i] = tokenKey("id_token", fmt.Sprint(i))
+	}
+	return keys
+}
+
+func BenchmarkVerificationCacheGet(b *testing.B) {
+	now := time.Now()
+	keys := benchCacheKeySet()
+	c := newVerificationCache(defaultCacheSize)
+	for _, k := range keys {
+		c.set(k, benchCacheValue, nil, now, now.Add(time.Hour))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.get(keys[i%len(keys)], now)
+	}
+}
+
+func BenchmarkVerificationCacheSet(b *testing.B) {
+	now := time.Now()
+	keys := benchCacheKeySet()
+	c := newVerificationCache(defaultCacheSize)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		k := keys[i%len(keys)]
+		c.set(k, benchCacheValue, nil, now, now.Add(time.Hour))
+	}
+}
+
+func BenchmarkVerificationCacheParallel(b *testing.B) {
+	now := time.Now()
+	keys := benchCacheKeySet()
+	c := newVerificationCache(defaultCacheSize)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			k := keys[i%len(keys)]
+			if _, _, ok := c.get(k, now); !ok {
+				c.set(k, benchCacheValue, nil, now, now.Add(time.Hour))
+			}
+			i += 7
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package goline
+
+import (
+	"strings"
+)
+
+// WithRealm sets realm of WWW-Authenticate header in 401 Unauthorized responses
+func WithRealm(realm string) AuthorizerOption {
+	return func(a *Authorizer) {
+		a.realm = realm
+	}
+}
+
+// bearerChallenge returns the value of WWW-Authenticate header per RFC 6750
+// https://www.rfc-editor.org/rfc/rfc6750#section-3
+func bearerChallenge(realm string, f AuthFailure) string {
+	params := make([]string, 0, 3)
+	if realm != "" {
+		params = append(params, "realm="+quote(realm))
+	}
+	switch f {
+	case AuthFailureTokenMissing:
+		// No error code when the request lacks any authentication information
+	case AuthFailureTokenMalformed:
+		params = append(params, `error="invalid_request"`, "error_description="+quote(f.Detail()))
+	default:
+		params = append(params, `error="invalid_token"`, "error_description="+quote(f.Detail()))
+	}
+	if len(params) == 0 {
+		return "Bearer"
+	}
+	return "Bearer " + strings.Join(params, ", ")
+}
+
+// quote returns quoted-string of RFC 7230
+func quote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,32 @@
+package goline
+
+// WithChannels adds Clients of other LINE Login channels to accept ID tokens issued for them, e.g. the LIFF apps
+// of several channels sharing one backend. The channel is selected by the unverified "aud" claim of the ID token,
+// so only one verification is done per token instead of trying each channel in sequence.
+// ID tokens of unknown audience and access tokens are verified by the Client passed to NewAuthorizer.
+func WithChannels(clients ...*Client) AuthorizerOption {
+	return func(a *Authorizer) {
+		if a.channels == nil {
+			a.channels = make(map[string]*Client, len(clients))
+		}
+		for _, c := range clients {
+			a.channels[c.clientid] = c
+		}
+	}
+}
+
+// clientForIDToken returns the Client of the channel of the ID token audience.
+// The audience is not trusted here as the token is verified by the selected Client with its channel ID.
+func (a *Authorizer) clientForIDToken(idToken string) *Client {
+	if len(a.channels) == 0 {
+		return a.lineClient
+	}
+	d, err := DecodeIDToken(idToken)
+	if err != nil {
+		return a.lineClient
+	}
+	if c, ok := a.channels[d.Aud]; ok {
+		return c
+	}
+	return a.lineClient
+}
@@ -0,0 +1,117 @@
+package goline
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+const (
+	// See https://developers.line.biz/ja/reference/messaging-api/#verfiy-channel-access-token-v2-1
+	urlVerifyChannelAccessToken = "https://api.line.me/oauth2/v2.1/verify"
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-all-valid-channel-access-token-key-ids-v2-1
+	urlChannelAccessTokenKeyIDs = "https://api.line.me/oauth2/v2.1/tokens/kid"
+	// See https://developers.line.biz/ja/reference/messaging-api/#issue-channel-access-token-v2-1
+	urlIssueChannelAccessToken = "https://api.line.me/oauth2/v2.1/token"
+
+	clientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+)
+
+// VerifyChannelAccessToken is a function to call verify-channel-access-token v2.1 API.
+// Unlike VerifyAccessToken, client ID is not checked as the channel access token is issued for Messaging API channel.
+// https://developers.line.biz/ja/reference/messaging-api/#verfiy-channel-access-token-v2-1
+func (c *Client) VerifyChannelAccessToken(ctx context.Context, channelAccessToken string) (*VerifyAccessTokenResponse, error) {
+	// Check token paramater
+	if channelAccessToken == "" {
+		return nil, errors.New("channel access token not found")
+	}
+
+	// Prepare http request
+	req, err := newRequest(http.MethodGet, urlVerifyChannelAccessToken).queryParams("access_token", channelAccessToken).build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	res := &VerifyAccessTokenResponse{}
+	if err := c.doRequestGetBody(req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetValidChannelAccessTokenKeyIDs is a function to call get-all-valid-channel-access-token-key-ids v2.1 API.
+// "clientAssertion" is a JWT signed with the assertion signing key of the channel.
+// https://developers.line.biz/ja/reference/messaging-api/#get-all-valid-channel-access-token-key-ids-v2-1
+func (c *Client) GetValidChannelAccessTokenKeyIDs(ctx context.Context, clientAssertion string) ([]string, error) {
+	// Check paramater
+	if clientAssertion == "" {
+		return nil, errors.New("client assertion not found")
+	}
+
+	// Prepare http request
+	req, err := newRequest(http.MethodGet, urlChannelAccessTokenKeyIDs).queryParams(
+		"client_assertion_type", clientAssertionType,
+		"client_assertion", clientAssertion,
+	).build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	res := struct {
+		Kids []string `json:"kids"`
+	}{}
+	if err := c.doRequestGetBody(req, &res); err != nil {
+		return nil, err
+	}
+	return res.Kids, nil
+}
+
+// ChannelAccessTokenResponse is the response json struct of issue-channel-access-token v2.1 API
+// https://developers.line.biz/ja/reference/messaging-api/#issue-channel-access-token-v2-1
+type ChannelAccessTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+	TokenType   string `json:"token_type"`
+	KeyID       string `json:"key_id"`
+
+	RawResponse
+}
+
+// IssueChannelAccessToken is a function to call issue-channel-access-token v2.1 API.
+// "clientAssertion" is a JWT signed with the assertion signing key of the channel. Use AssertionSigner to sign it.
+// https://developers.line.biz/ja/reference/messaging-api/#issue-channel-access-token-v2-1
+func (c *Client) IssueChannelAccessToken(ctx context.Context, clientAssertion string) (*ChannelAccessTokenResponse, error) {
+	// Check paramater
+	if clientAssertion == "" {
+		return nil, errors.New("client assertion not found")
+	}
+
+	// Prepare http request
+	req, err := newRequest(http.MethodPost, urlIssueChannelAccessToken).formBody(
+		"grant_type", "client_credentials",
+		"client_assertion_type", clientAssertionType,
+		"client_assertion", clientAssertion,
+	).build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	res := &ChannelAccessTokenResponse{}
+	if err := c.doRequestGetBody(req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// IssueChannelAccessTokenWithSigner issues the channel access token v2.1 with the assertion signed by AssertionSigner.
+// The assertion is signed by the newest active key so that the issuance keeps working during key rollover.
+func (c *Client) IssueChannelAccessTokenWithSigner(ctx context.Context, s *AssertionSigner) (*ChannelAccessTokenResponse, error) {
+	assertion, err := s.Sign()
+	if err != nil {
+		return nil, err
+	}
+	return c.IssueChannelAccessToken(ctx, assertion)
+}
@@ -0,0 +1,55 @@
+package goline
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ClaimMapping maps claim names to the header names to inject.
+// Claim names are the claims of the ID token including custom claims,
+// or the fields of the profile ("userId", "displayName", "pictureUrl", "statusMessage") for the access token.
+type ClaimMapping map[string]string
+
+// WithClaimMapping injects the claims into the headers in addition to the default headers.
+// Use WithHeaderNames(HeaderNames{}) together to inject only the mapped claims.
+// All claims are also available by User.Claims in the context.
+//
+//	goline.WithClaimMapping(goline.ClaimMapping{"amr": "X-Auth-Methods", "https://example.com/tenant": "X-Tenant"})
+func WithClaimMapping(m ClaimMapping) AuthorizerOption {
+	return func(a *Authorizer) {
+		// Copy the mapping not to be affected by changes after NewAuthorizer
+		a.claimHeaders = make(ClaimMapping, len(m))
+		for k, v := range m {
+			a.claimHeaders[k] = v
+		}
+	}
+}
+
+// claimString formats the claim value as a header value.
+// Arrays are joined by comma and objects are formatted in json.
+func claimString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case []interface{}:
+		s := make([]string, 0, len(t))
+		for _, e := range t {
+			s = append(s, claimString(e))
+		}
+		return strings.Join(s, ",")
+	case map[string]interface{}:
+		b, _ := json.Marshal(t)
+		return string(b)
+	case float64:
+		// json numbers are float64. Format integers without exponent
+		if t == float64(int64(t)) {
+			return fmt.Sprintf("%d", int64(t))
+		}
+		return fmt.Sprint(t)
+	default:
+		return fmt.Sprint(t)
+	}
+}
@@ -1,12 +1,20 @@
 package goline
 
+//go:generate go run ./internal/apigen -spec openapi/line-login.yaml -out apitypes_gen.go
+
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
 const (
@@ -18,6 +26,22 @@ const (
 	urlVerifyIDToken = "https://api.line.me/oauth2/v2.1/verify"
 
 	authHeader = "authorization"
+
+	contentTypeForm = "application/x-www-form-urlencoded"
+
+	// Maximum size of the remaining response body drained to reuse the connection
+	maxDrainSize = 4 * 1024
+)
+
+// formContentType is the precomputed header value set to the requests of the verification APIs.
+// It is shared among requests not to allocate on every verification, so it must not be modified.
+var formContentType = []string{contentTypeForm}
+
+// Requests of the verification APIs built once and cloned per call
+var (
+	verifyIDTokenRequest     = newRequestTemplate(http.MethodPost, urlVerifyIDToken)
+	verifyAccessTokenRequest = newRequestTemplate(http.MethodGet, urlVerifyAccessToken)
+	getUserProfileRequest    = newRequestTemplate(http.MethodGet, urlGetUserProfile)
 )
 
 var (
@@ -27,116 +51,322 @@ var (
 	ErrUnauthorized = errors.New("401 Unauthorized")
 	// ErrForbidden 403 Forbidden APIを使用する権限がありません。ご契約中のプランやアカウントに付与されている権限を確認してください。
 	ErrForbidden = errors.New("403 Forbidden")
+	// ErrNotFound 404 Not Found リクエストしたリソースが見つかりません。
+	ErrNotFound = errors.New("404 Not Found")
+	// ErrConflict 409 Conflict 同じリトライキーのリクエストが既に受理されています。
+	ErrConflict = errors.New("409 Conflict")
 	// ErrTooManyRequests 429 Too Many Requests リクエスト頻度をレート制限内に抑えてください。
 	ErrTooManyRequests = errors.New("429 Too Many Requests")
 	// ErrInternalServerError 500 Internal Server Error APIサーバーの一時的なエラーです。
 	ErrInternalServerError = errors.New("500 Internal Server Error")
+	// ErrBadGateway 502 Bad Gateway APIサーバーの一時的なエラーです。
+	ErrBadGateway = errors.New("502 Bad Gateway")
+	// ErrServiceUnavailable 503 Service Unavailable APIサーバーが一時的に利用できません。
+	ErrServiceUnavailable = errors.New("503 Service Unavailable")
+	// ErrGatewayTimeout 504 Gateway Timeout APIサーバーの一時的なエラーです。
+	ErrGatewayTimeout = errors.New("504 Gateway Timeout")
+
+	// ErrTokenExpired is returned when LINE responds 400 Bad Request as the token is expired.
+	// The user needs to refresh the token or log in again. errors.Is(err, ErrBadRequest) is also true.
+	ErrTokenExpired = errors.New("token expired")
+	// ErrTokenRevoked is returned when LINE responds 400 Bad Request as the token is invalid or revoked by the user.
+	// The user needs to log in again. errors.Is(err, ErrBadRequest) is also true.
+	ErrTokenRevoked = errors.New("token revoked")
+	// ErrClientIDMismatch is returned by VerifyAccessToken when client_id of the access token is not the channel ID of the Client,
+	// which means the token is issued for another channel. The check can be disabled by WithClientIDCheck.
+	ErrClientIDMismatch = errors.New("client ID does not match")
+
+	errAudienceMismatch = errors.New("aud does not match")
 )
 
-// Client is an http client access to LINE Login API
+// Client is an http client access to LINE Login API.
+// Client is safe for concurrent use by multiple goroutines and should be shared rather than created per request,
+// so that the shared calls and the cache take effect. The configuration is immutable after NewClient returns.
 type Client struct {
-	clientid string
-	client   *http.Client
+	clientid     string
+	clientSecret string
+	client       *http.Client
+	clock        Clock
+	recorder     Recorder
+	log          *slog.Logger
+	redaction    RedactionPolicy
+	metrics      MetricsSink
+	flights      flightGroup
+	strict       bool
+
+	cache       *verificationCache
+	cacheTTL    time.Duration
+	cacheSize   int
+	negativeTTL *time.Duration
+
+	proxyURL  *url.URL
+	tlsConfig *tls.Config
+
+	hedgeDelay time.Duration
+	retry      RetryPolicy
+
+	skipClientIDCheck bool
+
+	rateLimitHook func(rl *RateLimit)
+	rateLimit     atomic.Pointer[RateLimit]
+	throttled     atomic.Int64
+
+	channelToken channelTokenCache
+}
+
+// ClientOption configures Client
+type ClientOption func(*Client)
+
+// WithChannelSecret sets LINE Channel secret used by the APIs requiring client secret like RefreshAccessToken
+func WithChannelSecret(secret string) ClientOption {
+	return func(c *Client) {
+		c.clientSecret = secret
+	}
+}
+
+// WithClock sets Clock used by expiry checks of the Client and the components using the Client. Default is SystemClock.
+func WithClock(clock Clock) ClientOption {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// WithClientIDCheck enables or disables the check of client_id returned by VerifyAccessToken against the channel ID.
+// It is enabled by default when the channel ID is given to NewClient. Disable it only when the tokens of any channel
+// are accepted on purpose, e.g. a gateway checking client_id by itself.
+func WithClientIDCheck(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.skipClientIDCheck = !enabled
+	}
 }
 
 // NewClient returns LINE loging API Client. "id" is LINE Client ID a.k.a LINE Channel ID.
-func NewClient(clientid string, client *http.Client) *Client {
-	return &Client{
+// The http client is copied, so changing it after NewClient does not affect the Client. nil means the zero http.Client.
+func NewClient(clientid string, client *http.Client, opts ...ClientOption) *Client {
+	// Copy the http client not to be affected by and not to affect other users of it
+	hc := http.Client{}
+	if client != nil {
+		hc = *client
+	}
+	c := &Client{
 		clientid: clientid,
-		client:   client,
+		client:   &hc,
+		clock:    SystemClock,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.log = newLogger(c.log).With("logger", "goline.Client")
+	if c.cacheTTL > 0 {
+		c.cache = newVerificationCache(c.cacheSize)
 	}
+	c.configureTransport()
+	if c.recorder != nil {
+		c.client.Transport = &recordingTransport{base: c.client.Transport, recorder: c.recorder, clock: c.clock, redaction: c.redaction}
+	}
+	return c
 }
 
-// IDTokenData is the response json struct of verify-id-token API.
-// https://developers.line.biz/ja/reference/line-login/#verify-id-token
-type IDTokenData struct {
-	Iss     string   `json:"iss"`
-	Sub     string   `json:"sub"`
-	Aud     string   `json:"aud"`
-	Exp     string   `json:"exp"`
-	Nonce   string   `json:"nonce,omitempty"`
-	Amr     []string `json:"amr,omitempty"`
-	Name    string   `json:"name,omitempty"`
-	Picutre string   `json:"picture,omitempty"`
-	Email   string   `json:"email,omitempty"`
+// UnmarshalJSON implements json.Unmarshaler to keep all claims
+func (d *IDTokenData) UnmarshalJSON(b []byte) error {
+	type alias IDTokenData
+	if err := json.Unmarshal(b, (*alias)(d)); err != nil {
+		return err
+	}
+	return json.Unmarshal(b, &d.claims)
+}
+
+// clone returns a copy of d not sharing the slices and the maps, as the cached results are returned to many callers
+func (d *IDTokenData) clone() *IDTokenData {
+	res := *d
+	res.Amr = append([]string(nil), d.Amr...)
+	if d.claims != nil {
+		res.claims = make(map[string]interface{}, len(d.claims))
+		for k, v := range d.claims {
+			res.claims[k] = v
+		}
+	}
+	res.Raw = append(json.RawMessage(nil), d.Raw...)
+	return &res
+}
+
+// Claim returns the value of the claim by name, including custom claims
+func (d *IDTokenData) Claim(name string) (interface{}, bool) {
+	v, ok := d.claims[name]
+	return v, ok
+}
+
+// VerifyIDTokenOptions is the optional parameters of VerifyIDToken. nil or the zero value uses the defaults.
+type VerifyIDTokenOptions struct {
+	// ChannelID overrides the channel ID given to NewClient, e.g. for the ID tokens of another channel of the provider
+	ChannelID string
+	// UserID is the expected user ID of the ID token. It is not checked when empty.
+	UserID string
+	// Nonce is the expected nonce of the ID token. It is not checked when empty.
+	Nonce string
+}
+
+// idTokenOptions returns the copy of the options with the channel ID of the Client as default
+func (c *Client) idTokenOptions(opts *VerifyIDTokenOptions) VerifyIDTokenOptions {
+	var o VerifyIDTokenOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.ChannelID == "" {
+		o.ChannelID = c.clientid
+	}
+	return o
 }
 
 // VerifyIDToken is a function to call verify-id-token.
-// UserID and Nonce can be empty when not use.
+// The ID token is verified for the channel ID given to NewClient unless overridden by the options.
+// Concurrent calls with the same parameters share one API call, and the result is cached when WithCache is set.
 // https://developers.line.biz/ja/reference/line-login/#verify-id-token
-func (c *Client) VerifyIDToken(ctx context.Context, idToken, userid, nonce string) (*IDTokenData, error) {
+func (c *Client) VerifyIDToken(ctx context.Context, idToken string, opts *VerifyIDTokenOptions) (*IDTokenData, error) {
+	d, _, err := c.sharedVerifyIDToken(ctx, idToken, c.idTokenOptions(opts))
+	return d, err
+}
+
+// sharedVerifyIDToken verifies the ID token sharing the call among concurrent callers and the cached result.
+// "source" tells whether the result came from LINE, another caller or the cache.
+func (c *Client) sharedVerifyIDToken(ctx context.Context, idToken string, o VerifyIDTokenOptions) (d *IDTokenData, source resultSource, err error) {
 	// Check token paramater
 	if idToken == "" {
-		return nil, errors.New("idtoken not found")
+		return nil, sourceUpstream, errors.New("idtoken not found")
 	}
 
+	key := tokenKey("id_token", idToken, o.ChannelID, o.UserID, o.Nonce)
+	v, err, cached := c.cachedResult(key)
+	source = sourceCache
+	if !cached {
+		var shared bool
+		v, shared, err = c.flights.do(ctx, key, func(ctx context.Context) (interface{}, error) {
+			v, err := c.hedge(ctx, func(ctx context.Context) (interface{}, error) {
+				return c.verifyIDToken(ctx, idToken, o)
+			})
+			if err != nil {
+				c.cacheResult(key, nil, time.Time{}, err)
+				return nil, err
+			}
+			d := v.(*IDTokenData)
+			c.cacheResult(key, d, time.Unix(d.Exp, 0), nil)
+			return d, nil
+		})
+		source = sourceUpstream
+		if shared {
+			source = sourceShared
+		}
+	}
+	if err != nil {
+		return nil, source, err
+	}
+	// Copy not to share the result among callers
+	return v.(*IDTokenData).clone(), source, nil
+}
+
+func (c *Client) verifyIDToken(ctx context.Context, idToken string, o VerifyIDTokenOptions) (*IDTokenData, error) {
+
 	// Prepare http request
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, urlVerifyIDToken, nil)
+	req, err := verifyIDTokenRequest.newRequest().
+		formBody("id_token", idToken, "client_id", o.ChannelID, "nonce", o.Nonce, "user_id", o.UserID).
+		idempotentRequest().
+		build(ctx)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add(authHeader, bearerToken(idToken))
-	req.URL.Query().Add("clientid", c.clientid)
-	req.URL.Query().Add("nonce", nonce)
-	if userid != "" {
-		req.URL.Query().Add("userid", userid)
-	}
 
 	// Do http request and get response body
 	d := &IDTokenData{}
 	if err := c.doRequestGetBody(req, d); err != nil {
 		return nil, err
 	}
+
+	if d.Aud != o.ChannelID {
+		return nil, fmt.Errorf("%w: got %s want %s", errAudienceMismatch, d.Aud, o.ChannelID)
+	}
 	return d, nil
 }
 
-// VerifyAccessTokenResponse is the response json struct of verify-access-token API.
-// https://developers.line.biz/ja/reference/line-login/#verify-access-token
-type VerifyAccessTokenResponse struct {
-	Scope     string `json:"scope"`
-	ClientID  string `json:"client_id"`
-	ExpiresIn int    `json:"expires_in"`
+// ExpiresAt returns the expiry of the access token calculated from expires_in.
+// It is accurate even when the response is cached.
+func (r *VerifyAccessTokenResponse) ExpiresAt() time.Time {
+	return r.receivedAt.Add(time.Duration(r.ExpiresIn) * time.Second)
 }
 
-// VerifyAccessToken is a function to call verify-access-token API
+// VerifyAccessToken is a function to call verify-access-token API.
+// Concurrent calls with the same token share one API call, and the result is cached when WithCache is set.
 // https://developers.line.biz/ja/reference/line-login/#verify-access-token
 func (c *Client) VerifyAccessToken(ctx context.Context, accessToken string) (*VerifyAccessTokenResponse, error) {
+	res, _, err := c.sharedVerifyAccessToken(ctx, accessToken)
+	return res, err
+}
+
+// sharedVerifyAccessToken verifies the access token sharing the call among concurrent callers and the cached result.
+// "source" tells whether the result came from LINE, another caller or the cache.
+func (c *Client) sharedVerifyAccessToken(ctx context.Context, accessToken string) (r *VerifyAccessTokenResponse, source resultSource, err error) {
 	// Check token paramater
 	if accessToken == "" {
-		return nil, errors.New("access token not found")
+		return nil, sourceUpstream, errors.New("access token not found")
+	}
+
+	key := tokenKey("access_token", accessToken)
+	v, err, cached := c.cachedResult(key)
+	source = sourceCache
+	if !cached {
+		var shared bool
+		v, shared, err = c.flights.do(ctx, key, func(ctx context.Context) (interface{}, error) {
+			v, err := c.hedge(ctx, func(ctx context.Context) (interface{}, error) {
+				return c.verifyAccessToken(ctx, accessToken)
+			})
+			if err != nil {
+				c.cacheResult(key, nil, time.Time{}, err)
+				return nil, err
+			}
+			res := v.(*VerifyAccessTokenResponse)
+			c.cacheResult(key, res, res.ExpiresAt(), nil)
+			return res, nil
+		})
+		source = sourceUpstream
+		if shared {
+			source = sourceShared
+		}
+	}
+	if err != nil {
+		return nil, source, err
 	}
+	// Copy not to share the result among callers
+	res := *v.(*VerifyAccessTokenResponse)
+	res.Raw = append(json.RawMessage(nil), res.Raw...)
+	return &res, source, nil
+}
+
+func (c *Client) verifyAccessToken(ctx context.Context, accessToken string) (*VerifyAccessTokenResponse, error) {
 
 	// Prepare http request
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlVerifyAccessToken, nil)
+	req, err := verifyAccessTokenRequest.newRequest().queryParams("access_token", accessToken).build(ctx)
 	if err != nil {
 		return nil, err
 	}
-	params := req.URL.Query()
-	params.Add("access_token", accessToken)
-	req.URL.RawQuery = params.Encode()
 
 	// Do http request and get response body
 	res := &VerifyAccessTokenResponse{}
 	if err := c.doRequestGetBody(req, res); err != nil {
 		return nil, err
 	}
+	res.receivedAt = c.clock.Now()
 
-	if c.clientid != "" {
+	if c.checksClientID() {
 		if res.ClientID != c.clientid {
-			return nil, fmt.Errorf("client ID does not match: got %s want %s", res.ClientID, c.clientid)
+			return nil, fmt.Errorf("%w: got %s want %s", ErrClientIDMismatch, res.ClientID, c.clientid)
 		}
 	}
 
 	return res, nil
 }
 
-// LINEProfile is the response json struct of get-user-profile API
-// https://developers.line.biz/ja/reference/line-login-v2/#get-profile-response
-type LINEProfile struct {
-	UserID        string `json:"userId"`
-	DisplayName   string `json:"displayName"`
-	PictureURL    string `json:"pictureUrl"`
-	StatusMessage string `json:"statusMessage"`
+// checksClientID returns true when VerifyAccessToken checks client_id
+func (c *Client) checksClientID() bool {
+	return c.clientid != "" && !c.skipClientIDCheck
 }
 
 // GetProfile is a function to call get-user-profile API
@@ -148,11 +378,10 @@ func (c *Client) GetProfile(ctx context.Context, accessToken string) (*LINEProfi
 	}
 
 	// Prepare http request
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlGetUserProfile, nil)
+	req, err := getUserProfileRequest.newRequest().bearerAuth(accessToken).build(ctx)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add(authHeader, bearerToken(accessToken))
 
 	// Do http request and get response body
 	p := &LINEProfile{}
@@ -169,44 +398,59 @@ func (c *Client) doRequestGetBody(req *http.Request, resbody interface{}) error
 	if resbody == nil {
 		return errors.New("response body is nil")
 	}
-	// Do http request
-	res, err := c.client.Do(req)
+	// Do http request and check status code
+	res, err := c.do(req)
 	if err != nil {
-		return err
+		return classifyTokenError(err)
 	}
 	defer res.Body.Close()
 
-	// Check Status Code
-	if res.StatusCode != http.StatusOK {
-		return errByStatusCode(res.StatusCode)
-	}
-
-	// Read response body
-	b, err := io.ReadAll(res.Body)
-	if err != nil {
+	if err := decodeResponse(res.Body, resbody, c.strict); err != nil {
 		return err
 	}
+	// Drain the rest of body to reuse the connection
+	io.CopyN(io.Discard, res.Body, maxDrainSize)
+	return nil
+}
 
-	if err := json.Unmarshal(b, resbody); err != nil {
+// tokenError is a 400 Bad Request error classified by error_description
+type tokenError struct {
+	kind error
+	api  *APIError
+	// err is the classified error wrapping api
+	err error
+}
+
+func (e *tokenError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrBadRequest.Error(), e.kind.Error(), e.api.Message)
+}
+
+func (e *tokenError) Is(target error) bool {
+	return target == e.kind || target == ErrBadRequest
+}
+
+func (e *tokenError) Unwrap() error {
+	return e.err
+}
+
+// classifyTokenError classifies error_description of 400 Bad Request and returns ErrTokenExpired or ErrTokenRevoked.
+// It returns err as it is when the response is not classified.
+func classifyTokenError(err error) error {
+	var e *APIError
+	if !errors.As(err, &e) || e.StatusCode != http.StatusBadRequest {
 		return err
 	}
-	return nil
-}
 
-func errByStatusCode(statusCode int) error {
-	switch statusCode {
-	case http.StatusBadRequest:
-		return ErrBadRequest
-	case http.StatusUnauthorized:
-		return ErrUnauthorized
-	case http.StatusForbidden:
-		return ErrForbidden
-	case http.StatusTooManyRequests:
-		return ErrTooManyRequests
-	case http.StatusInternalServerError:
-		return ErrInternalServerError
+	desc := strings.ToLower(e.Message)
+	switch {
+	case strings.Contains(desc, "expired"):
+		return &tokenError{kind: ErrTokenExpired, api: e, err: err}
+	case e.Code == "invalid_grant",
+		strings.Contains(desc, "revoked"),
+		strings.Contains(desc, "invalid") && strings.Contains(desc, "token"):
+		return &tokenError{kind: ErrTokenRevoked, api: e, err: err}
 	default:
-		return fmt.Errorf("Unknown status code %d", statusCode)
+		return err
 	}
 }
 
@@ -0,0 +1,138 @@
+package goline
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var (
+	idTokenResponse     = []byte(`{"iss":"https://access.line.me","sub":"U1","aud":"123","exp":4102444800,"iat":1700000000,"amr":["pwd"],"name":"Taro","picture":"https://example.com/p","email":"taro@example.com"}`)
+	accessTokenResponse = []byte(`{"scope":"profile openid","client_id":"123","expires_in":2591659}`)
+	profileResponse     = []byte(`{"userId":"U1","displayName":"Taro","pictureUrl":"https://example.com/p","statusMessage":"Hello"}`)
+)
+
+// inMemoryLINE returns http.Client responding the verify and the profile APIs in memory,
+// to measure the Client without the network
+func inMemoryLINE() *http.Client {
+	return &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if r.Body != nil {
+			io.Copy(io.Discard, r.Body)
+			r.Body.Close()
+		}
+		var b []byte
+		switch {
+		case r.URL.Path == "/oauth2/v2.1/verify" && r.Method == http.MethodPost:
+			b = idTokenResponse
+		case r.URL.Path == "/oauth2/v2.1/verify":
+			b = accessTokenResponse
+		case r.URL.Path == "/v2/profile":
+			b = profileResponse
+		default:
+			return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody, Request: r}, nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader(b)),
+			Request:    r,
+		}, nil
+	})}
+}
+
+func TestDoRequestGetBody(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("decode", func(t *testing.T) {
+		d, err := NewClient("123", inMemoryLINE()).VerifyIDToken(ctx, "token", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Sub != "U1" || d.Email != "taro@example.com" || len(d.Amr) != 1 || d.Exp != 4102444800 {
+			t.Errorf("got %+v", d)
+		}
+		if string(d.Raw) != string(idTokenResponse) {
+			t.Errorf("Raw = %s", d.Raw)
+		}
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		hc := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"userId":"U1","newField":1}`)), Request: r}, nil
+		})}
+		if _, err := NewClient("123", hc).GetProfile(ctx, "token"); err != nil {
+			t.Errorf("GetProfile() error = %v, want unknown fields ignored", err)
+		}
+		if _, err := NewClient("123", hc, WithStrictDecoding()).GetProfile(ctx, "token"); err == nil {
+			t.Error("GetProfile() with WithStrictDecoding, want error on unknown field")
+		}
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		hc := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"userId":`)), Request: r}, nil
+		})}
+		if _, err := NewClient("123", hc).GetProfile(ctx, "token"); err == nil {
+			t.Error("want error")
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		c := NewClient("123", inMemoryLINE())
+		if err := c.doRequestGetBody(nil, &LINEProfile{}); err == nil {
+			t.Error("want error for nil request")
+		}
+		req, _ := http.NewRequest(http.MethodGet, urlGetUserProfile, nil)
+		if err := c.doRequestGetBody(req, nil); err == nil {
+			t.Error("want error for nil response body")
+		}
+	})
+}
+
+// The benchmarks measure the verification path of the Client without the network and the cache
+//
+//	go test -run XXX -bench 'Client' -benchmem .
+func BenchmarkClientVerifyIDToken(b *testing.B) {
+	c := NewClient("123", inMemoryLINE())
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.VerifyIDToken(ctx, "token", nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkClientVerifyAccessToken(b *testing.B) {
+	c := NewClient("123", inMemoryLINE())
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.VerifyAccessToken(ctx, "token"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkClientGetProfile(b *testing.B) {
+	c := NewClient("123", inMemoryLINE())
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.GetProfile(ctx, "token"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeResponse(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := decodeResponse(bytes.NewReader(accessTokenResponse), &VerifyAccessTokenResponse{}, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
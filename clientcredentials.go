@@ -0,0 +1,80 @@
+package goline
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// See https://developers.line.biz/ja/reference/messaging-api/#issue-stateless-channel-access-token
+	urlStatelessChannelAccessToken = "https://api.line.me/oauth2/v3/token"
+
+	// The cached channel access token is renewed when it expires within this duration
+	channelTokenRenewBefore = time.Minute
+)
+
+// IssueStatelessChannelAccessToken is a function to call issue-stateless-channel-access-token API
+// by client_credentials grant with the channel ID and the channel secret. The token is valid for 15 minutes.
+// The Client must be created with WithChannelSecret. Use ChannelAccessToken to cache and renew it automatically.
+// https://developers.line.biz/ja/reference/messaging-api/#issue-stateless-channel-access-token
+func (c *Client) IssueStatelessChannelAccessToken(ctx context.Context) (*ChannelAccessTokenResponse, error) {
+	// Check paramaters
+	if c.clientSecret == "" {
+		return nil, errors.New("channel secret is not set")
+	}
+
+	// Prepare http request
+	req, err := newRequest(http.MethodPost, urlStatelessChannelAccessToken).formBody(
+		"grant_type", "client_credentials",
+		"client_id", c.clientid,
+		"client_secret", c.clientSecret,
+	).build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	res := &ChannelAccessTokenResponse{}
+	if err := c.doRequestGetBody(req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// channelTokenCache caches the stateless channel access token
+type channelTokenCache struct {
+	mu        sync.RWMutex
+	token     string
+	expiresAt time.Time
+}
+
+// ChannelAccessToken returns the stateless channel access token of the channel for the APIs requiring channel tokens.
+// The token is cached and issued again by client_credentials grant 1 minute before expiry.
+// Concurrent calls share one issuance. The Client must be created with WithChannelSecret.
+func (c *Client) ChannelAccessToken(ctx context.Context) (string, error) {
+	c.channelToken.mu.RLock()
+	token, expiresAt := c.channelToken.token, c.channelToken.expiresAt
+	c.channelToken.mu.RUnlock()
+	if token != "" && c.clock.Now().Add(channelTokenRenewBefore).Before(expiresAt) {
+		return token, nil
+	}
+
+	v, _, err := c.flights.do(ctx, tokenKey("channel_access_token", c.clientid), func(ctx context.Context) (interface{}, error) {
+		res, err := c.IssueStatelessChannelAccessToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.channelToken.mu.Lock()
+		c.channelToken.token = res.AccessToken
+		c.channelToken.expiresAt = c.clock.Now().Add(time.Duration(res.ExpiresIn) * time.Second)
+		c.channelToken.mu.Unlock()
+		return res.AccessToken, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
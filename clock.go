@@ -0,0 +1,20 @@
+package goline
+
+import "time"
+
+// Clock provides the current time used by expiry checks.
+// Replace it by WithClock to simulate token expiration in tests without sleeping.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc is an adapter to use a function as Clock
+type ClockFunc func() time.Time
+
+// Now implements Clock
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the Clock returning the current system time
+var SystemClock Clock = ClockFunc(time.Now)
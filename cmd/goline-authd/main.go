@@ -0,0 +1,220 @@
+// Command goline-authd is a LINE token verification daemon for polyglot microservices.
+// Services in any language delegate the verification to it over HTTP or gRPC.
+//
+// HTTP endpoints:
+//
+//	POST /v1/tokenreview/idtoken      TokenReview of ID token
+//	POST /v1/tokenreview/accesstoken  TokenReview of access token
+//	GET  /v1/auth/idtoken             forward-auth of ID token in authorization header
+//	GET  /v1/auth/accesstoken         forward-auth of access token in authorization header
+//	GET  /healthz                     liveness probe
+//	GET  /readyz                      readiness probe checking the connectivity to LINE
+//	GET  /debug/vars                  metrics in expvar format
+//
+// gRPC: Envoy ext_authz v3 AuthorizationServer when -grpc-listen is set.
+//
+// The channel is configured by -config file or the environment variables of goline.ConfigFromEnv.
+package main
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"flag"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"github.com/go-logr/logr"
+	"github.com/go-logr/zapr"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/jlandowner/goline"
+	"github.com/jlandowner/goline/config/yamlconfig"
+	"github.com/jlandowner/goline/extauthz"
+	"github.com/jlandowner/goline/logadapter"
+)
+
+var (
+	// Metrics exposed in /debug/vars
+	authDecisions = expvar.NewMap("goline_auth_decisions")
+	httpResponses = expvar.NewMap("goline_http_responses")
+	grpcChecks    = expvar.NewMap("goline_grpc_checks")
+)
+
+func main() {
+	var (
+		configPath     string
+		listen         string
+		grpcListen     string
+		grpcAccessTok  bool
+		correlationHdr string
+		shutdownPeriod time.Duration
+	)
+	flag.StringVar(&configPath, "config", "", "YAML or JSON config file. The environment variables are used when empty")
+	flag.StringVar(&listen, "listen", ":8080", "HTTP listen address")
+	flag.StringVar(&grpcListen, "grpc-listen", "", "gRPC listen address of Envoy ext_authz. Disabled when empty")
+	flag.BoolVar(&grpcAccessTok, "grpc-access-token", false, "Verify access tokens instead of ID tokens in ext_authz")
+	flag.StringVar(&correlationHdr, "correlation-header", "X-Request-Id", "Request header of the correlation ID added to the logs")
+	flag.DurationVar(&shutdownPeriod, "shutdown-period", 10*time.Second, "Graceful shutdown period")
+	flag.Parse()
+
+	zapLog, err := zap.NewProduction()
+	if err != nil {
+		panic(err)
+	}
+	log := zapr.NewLogger(zapLog)
+
+	if err := run(log, configPath, listen, grpcListen, grpcAccessTok, correlationHdr, shutdownPeriod); err != nil {
+		log.Error(err, "goline-authd exited")
+		os.Exit(1)
+	}
+}
+
+func run(log logr.Logger, configPath, listen, grpcListen string, grpcAccessToken bool, correlationHeader string, shutdownPeriod time.Duration) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	if cfg.CacheTTL == 0 {
+		// Cache by default as the daemon is shared by many services
+		cfg.CacheTTL = time.Minute
+	}
+
+	hc := &http.Client{Timeout: 10 * time.Second}
+	lineClient := cfg.NewClient(hc)
+	opts := []goline.AuthorizerOption{goline.WithAuditHook(goline.AuditHookFunc(countDecision))}
+	if correlationHeader != "" {
+		opts = append(opts, goline.WithCorrelationIDHeader(correlationHeader))
+	}
+	lineAuth := goline.NewAuthorizer(lineClient, logadapter.FromLogr(log), opts...)
+
+	mux := http.NewServeMux()
+	mux.Handle("/v1/tokenreview/idtoken", lineAuth.IDTokenReviewHandler())
+	mux.Handle("/v1/tokenreview/accesstoken", lineAuth.AccessTokenReviewHandler())
+	mux.Handle("/v1/auth/idtoken", lineAuth.IDTokenAuthHandler())
+	mux.Handle("/v1/auth/accesstoken", lineAuth.AccessTokenAuthHandler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+	mux.Handle("/readyz", lineClient.HealthCheckHandler())
+	mux.Handle("/debug/vars", expvar.Handler())
+	expvar.Publish("goline_too_many_requests", expvar.Func(func() interface{} {
+		return lineClient.TooManyRequests()
+	}))
+	expvar.Publish("goline_rate_limit", expvar.Func(func() interface{} {
+		return lineClient.RateLimit()
+	}))
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := lineAuth.Warmup(ctx); err != nil {
+		log.Error(err, "warmup failed")
+	}
+
+	srv := &http.Server{
+		Addr:              listen,
+		Handler:           countResponses(mux),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	errCh := make(chan error, 2)
+	go func() {
+		log.Info("serving HTTP", "addr", listen)
+		if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+	}()
+
+	var gs *grpc.Server
+	if grpcListen != "" {
+		lis, err := net.Listen("tcp", grpcListen)
+		if err != nil {
+			return err
+		}
+		var opts []extauthz.Option
+		if grpcAccessToken {
+			opts = append(opts, extauthz.WithAccessToken())
+		}
+		gs = grpc.NewServer(grpc.UnaryInterceptor(countChecks))
+		extauthz.NewServer(lineAuth, opts...).Register(gs)
+		go func() {
+			log.Info("serving gRPC", "addr", grpcListen)
+			if err := gs.Serve(lis); err != nil {
+				errCh <- err
+			}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		log.Info("shutting down")
+	case err := <-errCh:
+		return err
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownPeriod)
+	defer cancel()
+	if gs != nil {
+		gs.GracefulStop()
+	}
+	return srv.Shutdown(shutdownCtx)
+}
+
+func loadConfig(path string) (*goline.Config, error) {
+	if path != "" {
+		return yamlconfig.Load(path)
+	}
+	return goline.ConfigFromEnv()
+}
+
+func countDecision(ctx context.Context, e *goline.AuditEvent) {
+	key := string(e.Decision)
+	if e.Reason != "" {
+		key += "_" + string(e.Reason)
+	}
+	authDecisions.Add(key, 1)
+}
+
+// statusRecorder records the status code written by the handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func countResponses(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		httpResponses.Add(strconv.Itoa(rec.status), 1)
+	})
+}
+
+func countChecks(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	res, err := handler(ctx, req)
+	switch {
+	case err != nil:
+		grpcChecks.Add("error", 1)
+	case isOK(res):
+		grpcChecks.Add("ok", 1)
+	default:
+		grpcChecks.Add("denied", 1)
+	}
+	return res, err
+}
+
+func isOK(res interface{}) bool {
+	r, ok := res.(*authv3.CheckResponse)
+	return ok && r.GetStatus().GetCode() == 0
+}
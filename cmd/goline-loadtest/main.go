@@ -0,0 +1,50 @@
+// Command goline-loadtest drives the middleware of goline.Authorizer against a fake LINE server
+// at the configured rate, and reports the latency percentiles and the allocations per request.
+//
+//	goline-loadtest -rps 5000 -duration 10s -cache-ttl 1m -upstream-latency 20ms
+//
+// It exits with 1 when -max-p99 is set and the p99 latency exceeds it, to catch regressions in CI.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jlandowner/goline/loadtest"
+)
+
+func main() {
+	var (
+		cfg    loadtest.Config
+		maxP99 time.Duration
+	)
+	flag.IntVar(&cfg.RPS, "rps", 1000, "Requests per second")
+	flag.DurationVar(&cfg.Duration, "duration", 10*time.Second, "Duration to send the requests")
+	flag.IntVar(&cfg.Concurrency, "concurrency", 64, "Number of the workers sending the requests")
+	flag.IntVar(&cfg.Tokens, "tokens", 1000, "Number of the distinct tokens sent in rotation")
+	flag.DurationVar(&cfg.CacheTTL, "cache-ttl", 0, "TTL of the verification cache. Disabled when 0")
+	flag.DurationVar(&cfg.UpstreamLatency, "upstream-latency", 0, "Latency of the fake LINE server")
+	flag.BoolVar(&cfg.AccessToken, "access-token", false, "Verify access tokens instead of ID tokens")
+	flag.DurationVar(&maxP99, "max-p99", 0, "Fail when the p99 latency exceeds it. Disabled when 0")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	res, err := loadtest.Run(ctx, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println(res)
+
+	if maxP99 > 0 && res.P99 > maxP99 {
+		fmt.Fprintf(os.Stderr, "p99 %s exceeds %s\n", res.P99, maxP99)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,177 @@
+package goline_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jlandowner/goline"
+	"github.com/jlandowner/goline/golinetest"
+)
+
+// The tests in this file share a Client and an Authorizer among goroutines. Run them with -race.
+const (
+	concurrency = 16
+	iterations  = 50
+	channelID   = "123"
+)
+
+// lineHandler is a fake of LINE APIs. The ID token "id-N" and the access token "at-N" are of the user "UN".
+func lineHandler(w http.ResponseWriter, r *http.Request) {
+	var res interface{}
+	switch {
+	case r.URL.Path == "/oauth2/v2.1/verify" && r.Method == http.MethodPost:
+		res = golinetest.Claims(r.PostFormValue("client_id"), "U"+strings.TrimPrefix(r.PostFormValue("id_token"), "id-"), time.Hour)
+	case r.URL.Path == "/oauth2/v2.1/verify":
+		res = map[string]interface{}{"scope": "profile openid", "client_id": channelID, "expires_in": 3600}
+	case r.URL.Path == "/v2/profile":
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		res = map[string]interface{}{"userId": "U" + strings.TrimPrefix(token, "at-"), "displayName": "user"}
+	case r.URL.Path == "/.well-known/openid-configuration":
+		res = map[string]interface{}{"issuer": golinetest.Issuer}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func newFakeLINEClient(t *testing.T) *http.Client {
+	return newLINEClient(t, http.HandlerFunc(lineHandler))
+}
+
+// parallel runs fn concurrently and reports the errors
+func parallel(t *testing.T, fn func(g, i int) error) {
+	t.Helper()
+	var wg sync.WaitGroup
+	for g := 0; g < concurrency; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				if err := fn(g, i); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestClientConcurrentUse(t *testing.T) {
+	key, err := golinetest.NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks := golinetest.NewJWKSServer(key)
+	t.Cleanup(jwks.Close)
+
+	c := goline.NewClient(channelID, newFakeLINEClient(t),
+		goline.WithCache(time.Minute, 8),
+		goline.WithRecorder(goline.NewRingRecorder(16)))
+	// Expire JWKS often to refresh it concurrently
+	lv := goline.NewLocalVerifier(c, goline.WithJWKSURL(jwks.URL), goline.WithJWKSTTL(time.Millisecond), goline.WithJWKSRefreshCooldown(0))
+	ctx := context.Background()
+
+	parallel(t, func(g, i int) error {
+		// More users than the cache size to evict concurrently
+		user := fmt.Sprint((g + i) % 12)
+
+		d, err := c.VerifyIDToken(ctx, "id-"+user, nil)
+		if err != nil {
+			return err
+		}
+		if d.Sub != "U"+user {
+			return fmt.Errorf("VerifyIDToken() sub = %s, want U%s", d.Sub, user)
+		}
+		// The results must not be shared among callers
+		d.Sub = "modified"
+		d.Amr = append(d.Amr, "modified")
+
+		v, err := c.VerifyAccessToken(ctx, "at-"+user)
+		if err != nil {
+			return err
+		}
+		if v.ClientID != channelID {
+			return fmt.Errorf("VerifyAccessToken() client_id = %s, want %s", v.ClientID, channelID)
+		}
+		v.ClientID = "modified"
+
+		p, err := c.GetProfile(ctx, "at-"+user)
+		if err != nil {
+			return err
+		}
+		if p.UserID != "U"+user {
+			return fmt.Errorf("GetProfile() userId = %s, want U%s", p.UserID, user)
+		}
+
+		token, err := golinetest.SignIDToken(golinetest.Claims(channelID, "U"+user, time.Hour), key)
+		if err != nil {
+			return err
+		}
+		if _, err := lv.Verify(ctx, token, nil); err != nil {
+			return fmt.Errorf("LocalVerifier.Verify() error = %w", err)
+		}
+
+		if i%10 == 0 {
+			return c.HealthCheck(ctx)
+		}
+		return nil
+	})
+}
+
+func TestAuthorizerConcurrentMiddleware(t *testing.T) {
+	c := goline.NewClient(channelID, newFakeLINEClient(t), goline.WithCache(time.Minute, 8))
+	a := goline.NewAuthorizer(c, nil,
+		goline.WithDegradationPolicy(goline.FailOpenCachedOnly),
+		goline.WithTokenExpiresInHeader(),
+		goline.WithAuditHook(goline.AuditHookFunc(func(ctx context.Context, e *goline.AuditEvent) {})),
+		goline.WithEnricher(goline.EnricherFunc(func(ctx context.Context, u *goline.User) (*goline.Enrichment, error) {
+			return &goline.Enrichment{InternalID: "internal-" + u.ID, Headers: map[string]string{"X-Internal-ID": "internal-" + u.ID}}, nil
+		}), time.Minute, "X-Internal-ID"))
+
+	check := func(w http.ResponseWriter, r *http.Request) {
+		u := goline.MustUserFromContext(r.Context())
+		if got := r.Header.Get(goline.HeaderKeyLINEUserID); got != u.ID {
+			http.Error(w, fmt.Sprintf("user ID header %s, want %s", got, u.ID), http.StatusInternalServerError)
+			return
+		}
+		if got := r.Header.Get("X-Internal-ID"); got != "internal-"+u.ID {
+			http.Error(w, fmt.Sprintf("internal ID header %s, want internal-%s", got, u.ID), http.StatusInternalServerError)
+			return
+		}
+		// The users must not be shared among requests
+		u.Claims["modified"] = true
+		u.DisplayName = "modified"
+		fmt.Fprint(w, u.ID)
+	}
+	handlers := []struct {
+		prefix  string
+		handler http.Handler
+	}{
+		{"id-", a.VerifyIDTokenMiddleware(http.HandlerFunc(check))},
+		{"at-", a.VerifyAccessTokenMiddleware(http.HandlerFunc(check))},
+	}
+
+	parallel(t, func(g, i int) error {
+		user := fmt.Sprint((g + i) % 12)
+		h := handlers[(g+i)%len(handlers)]
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", "Bearer "+h.prefix+user)
+		w := httptest.NewRecorder()
+		h.handler.ServeHTTP(w, r)
+		if w.Code != http.StatusOK || w.Body.String() != "U"+user {
+			return fmt.Errorf("%s%s: got %d %s, want 200 U%s", h.prefix, user, w.Code, w.Body.String(), user)
+		}
+		return nil
+	})
+}
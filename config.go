@@ -0,0 +1,170 @@
+package goline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Environment variables read by ConfigFromEnv
+const (
+	EnvChannelID     = "LINE_CHANNEL_ID"
+	EnvChannelSecret = "LINE_CHANNEL_SECRET"
+	EnvRedirectURI   = "LINE_REDIRECT_URI"
+	EnvCacheTTL      = "LINE_CACHE_TTL"
+	EnvCacheSize     = "LINE_CACHE_SIZE"
+)
+
+// Config is the configuration of LINE Login channel consumed by NewClient and NewAuthorizer
+type Config struct {
+	ChannelID     string
+	ChannelSecret string
+	// RedirectURI is the callback URL of LINE Login registered in the channel, used by NewLoginHandler
+	RedirectURI string
+	// CacheTTL is the duration to cache verification results
+	CacheTTL time.Duration
+	// CacheSize is the maximum number of cached verification results
+	CacheSize int
+}
+
+// fileConfig is the file format of Config
+type fileConfig struct {
+	ChannelID     string `json:"channelId" yaml:"channelId"`
+	ChannelSecret string `json:"channelSecret" yaml:"channelSecret"`
+	RedirectURI   string `json:"redirectUri" yaml:"redirectUri"`
+	Cache         struct {
+		TTL  string `json:"ttl" yaml:"ttl"`
+		Size int    `json:"size" yaml:"size"`
+	} `json:"cache" yaml:"cache"`
+}
+
+// ConfigFromEnv returns validated Config from the environment variables
+// LINE_CHANNEL_ID, LINE_CHANNEL_SECRET, LINE_REDIRECT_URI, LINE_CACHE_TTL (e.g. "5m") and LINE_CACHE_SIZE
+func ConfigFromEnv() (*Config, error) {
+	c := &Config{
+		ChannelID:     os.Getenv(EnvChannelID),
+		ChannelSecret: os.Getenv(EnvChannelSecret),
+		RedirectURI:   os.Getenv(EnvRedirectURI),
+	}
+	if v := os.Getenv(EnvCacheTTL); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvCacheTTL, err)
+		}
+		c.CacheTTL = d
+	}
+	if v := os.Getenv(EnvCacheSize); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvCacheSize, err)
+		}
+		c.CacheSize = n
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// LoadConfig returns validated Config from JSON (.json) file.
+// YAML files are loaded by yamlconfig.Load of github.com/jlandowner/goline/config/yamlconfig,
+// not to make this package depend on a YAML library.
+//
+//	{"channelId": "1234567890", "channelSecret": "xxx", "redirectUri": "https://example.com/callback", "cache": {"ttl": "5m", "size": 10000}}
+func LoadConfig(path string) (*Config, error) {
+	switch ext := filepath.Ext(path); ext {
+	case ".json":
+	case ".yaml", ".yml":
+		return nil, fmt.Errorf("YAML config file %s must be loaded by github.com/jlandowner/goline/config/yamlconfig", path)
+	default:
+		return nil, fmt.Errorf("unsupported config file extension: %s", ext)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := ParseConfig(b, json.Unmarshal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config file %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// ParseConfig returns validated Config from the file content decoded by unmarshal, e.g. json.Unmarshal or yaml.Unmarshal.
+// The decoder must support "json" or "yaml" struct tags.
+func ParseConfig(b []byte, unmarshal func([]byte, interface{}) error) (*Config, error) {
+	f := &fileConfig{}
+	if err := unmarshal(b, f); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	c := &Config{
+		ChannelID:     f.ChannelID,
+		ChannelSecret: f.ChannelSecret,
+		RedirectURI:   f.RedirectURI,
+		CacheSize:     f.Cache.Size,
+	}
+	if f.Cache.TTL != "" {
+		d, err := time.ParseDuration(f.Cache.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache ttl: %w", err)
+		}
+		c.CacheTTL = d
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Validate validates the config
+func (c *Config) Validate() error {
+	if c.ChannelID == "" {
+		return errors.New("channel ID not found")
+	}
+	if _, err := strconv.ParseUint(c.ChannelID, 10, 64); err != nil {
+		return fmt.Errorf("channel ID must be numeric: %s", c.ChannelID)
+	}
+	if c.RedirectURI != "" {
+		u, err := url.Parse(c.RedirectURI)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("redirect URI must be an absolute http or https URL: %s", c.RedirectURI)
+		}
+	}
+	if c.CacheTTL < 0 {
+		return errors.New("cache ttl must not be negative")
+	}
+	if c.CacheSize < 0 {
+		return errors.New("cache size must not be negative")
+	}
+	return nil
+}
+
+// NewClient returns Client configured by the config
+func (c *Config) NewClient(client *http.Client, opts ...ClientOption) *Client {
+	if c.ChannelSecret != "" {
+		opts = append([]ClientOption{WithChannelSecret(c.ChannelSecret)}, opts...)
+	}
+	if c.CacheTTL > 0 {
+		opts = append([]ClientOption{WithCache(c.CacheTTL, c.CacheSize)}, opts...)
+	}
+	return NewClient(c.ChannelID, client, opts...)
+}
+
+// NewAuthorizer returns Authorizer configured by the config
+func (c *Config) NewAuthorizer(client *http.Client, log *slog.Logger, opts ...AuthorizerOption) *Authorizer {
+	return NewAuthorizer(c.NewClient(client), log, opts...)
+}
+
+// NewLoginHandler returns LoginHandler with the Client and the redirect URI configured by the config.
+// ChannelSecret and RedirectURI are required.
+func (c *Config) NewLoginHandler(client *http.Client, signer *StateSigner, onLogin LoginHook, opts ...LoginOption) (*LoginHandler, error) {
+	return NewLoginHandler(c.NewClient(client), signer, c.RedirectURI, onLogin, opts...)
+}
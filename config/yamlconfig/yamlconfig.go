@@ -0,0 +1,45 @@
+// Package yamlconfig loads goline.Config from YAML files.
+// It is separated from goline package not to make it depend on a YAML library.
+package yamlconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jlandowner/goline"
+)
+
+// Load returns validated goline.Config from YAML (.yaml, .yml) or JSON (.json) file
+//
+//	channelId: "1234567890"
+//	channelSecret: "xxx"
+//	redirectUri: https://example.com/callback
+//	cache:
+//	  ttl: 5m
+//	  size: 10000
+func Load(path string) (*goline.Config, error) {
+	switch ext := filepath.Ext(path); ext {
+	case ".yaml", ".yml":
+	case ".json":
+		return goline.LoadConfig(path)
+	default:
+		return nil, fmt.Errorf("unsupported config file extension: %s", ext)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config file %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Parse returns validated goline.Config from YAML
+func Parse(b []byte) (*goline.Config, error) {
+	return goline.ParseConfig(b, yaml.Unmarshal)
+}
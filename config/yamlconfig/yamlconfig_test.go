@@ -0,0 +1,41 @@
+package yamlconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "goline.yaml")
+	os.WriteFile(yamlPath, []byte(`channelId: "1234567890"
+channelSecret: xxx
+redirectUri: https://example.com/callback
+cache:
+  ttl: 5m
+  size: 10000
+`), 0o600)
+	jsonPath := filepath.Join(dir, "goline.json")
+	os.WriteFile(jsonPath, []byte(`{"channelId":"1234567890"}`), 0o600)
+
+	c, err := Load(yamlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ChannelID != "1234567890" || c.ChannelSecret != "xxx" || c.RedirectURI != "https://example.com/callback" ||
+		c.CacheTTL != 5*time.Minute || c.CacheSize != 10000 {
+		t.Errorf("Load(yaml) = %+v", c)
+	}
+
+	if c, err := Load(jsonPath); err != nil || c.ChannelID != "1234567890" {
+		t.Errorf("Load(json) = %+v, %v", c, err)
+	}
+	if _, err := Load(filepath.Join(dir, "goline.toml")); err == nil {
+		t.Error("want error for unsupported extension")
+	}
+	if _, err := Parse([]byte("channelId: [")); err == nil {
+		t.Error("want error for invalid yaml")
+	}
+}
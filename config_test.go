@@ -0,0 +1,116 @@
+package goline
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv(EnvChannelID, "1234567890")
+	t.Setenv(EnvChannelSecret, "secret")
+	t.Setenv(EnvRedirectURI, "https://example.com/callback")
+	t.Setenv(EnvCacheTTL, "5m")
+	t.Setenv(EnvCacheSize, "100")
+
+	c, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Config{ChannelID: "1234567890", ChannelSecret: "secret", RedirectURI: "https://example.com/callback", CacheTTL: 5 * time.Minute, CacheSize: 100}
+	if *c != want {
+		t.Errorf("ConfigFromEnv() = %+v, want %+v", *c, want)
+	}
+
+	t.Setenv(EnvCacheTTL, "5")
+	if _, err := ConfigFromEnv(); err == nil {
+		t.Error("want error for invalid cache ttl")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	c, err := LoadConfig(write("goline.json", `{"channelId":"1234567890","cache":{"ttl":"1m","size":10}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ChannelID != "1234567890" || c.CacheTTL != time.Minute || c.CacheSize != 10 {
+		t.Errorf("LoadConfig() = %+v", c)
+	}
+
+	if _, err := LoadConfig(write("goline.yaml", "channelId: \"1234567890\"\n")); err == nil || !strings.Contains(err.Error(), "yamlconfig") {
+		t.Errorf("LoadConfig(yaml) error = %v, want error pointing to yamlconfig", err)
+	}
+	if _, err := LoadConfig(write("goline.toml", "")); err == nil {
+		t.Error("want error for unsupported extension")
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		ok   bool
+	}{
+		{name: "valid", body: `{"channelId":"1234567890"}`, ok: true},
+		{name: "no channel ID", body: `{}`},
+		{name: "non numeric channel ID", body: `{"channelId":"abc"}`},
+		{name: "invalid ttl", body: `{"channelId":"1234567890","cache":{"ttl":"1"}}`},
+		{name: "negative ttl", body: `{"channelId":"1234567890","cache":{"ttl":"-1m"}}`},
+		{name: "negative size", body: `{"channelId":"1234567890","cache":{"size":-1}}`},
+		{name: "redirect URI", body: `{"channelId":"1234567890","redirectUri":"https://example.com/callback"}`, ok: true},
+		{name: "relative redirect URI", body: `{"channelId":"1234567890","redirectUri":"/callback"}`},
+		{name: "invalid json", body: `{`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(tt.body), json.Unmarshal)
+			if (err == nil) != tt.ok {
+				t.Errorf("ParseConfig() error = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestConfigNewLoginHandler(t *testing.T) {
+	signer, err := NewStateSigner([]byte(strings.Repeat("k", 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	onLogin := func(w http.ResponseWriter, r *http.Request, res *LoginResult) error { return nil }
+
+	c := &Config{ChannelID: "1234567890", ChannelSecret: "secret", RedirectURI: "https://example.com/callback"}
+	h, err := c.NewLoginHandler(nil, signer, onLogin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	h.Login().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/login", nil))
+	loc, _ := url.Parse(w.Header().Get("Location"))
+	if got := loc.Query().Get("redirect_uri"); got != c.RedirectURI {
+		t.Errorf("redirect_uri = %q, want %q", got, c.RedirectURI)
+	}
+
+	for _, c := range []*Config{
+		{ChannelID: "1234567890", ChannelSecret: "secret"},
+		{ChannelID: "1234567890", RedirectURI: "https://example.com/callback"},
+	} {
+		if _, err := c.NewLoginHandler(nil, signer, onLogin); err == nil {
+			t.Errorf("NewLoginHandler() of %+v, want error", c)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package goline
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// CookieOptions is the attributes of the cookies set by the package e.g. the login state cookie
+type CookieOptions struct {
+	// Name is the cookie name. The default name of each cookie is used when empty.
+	Name   string
+	Domain string
+	Path   string
+	// MaxAge is the lifetime of the cookie. The default lifetime of each cookie is used when zero.
+	MaxAge   time.Duration
+	Secure   bool
+	HttpOnly bool
+	SameSite http.SameSite
+}
+
+// DefaultCookieOptions is the secure-by-default cookie attributes: Secure, HttpOnly and SameSite=Lax on path "/".
+// SameSite=Lax is required for the login state cookie to be sent in the redirect from LINE to the callback.
+var DefaultCookieOptions = CookieOptions{
+	Path:     "/",
+	Secure:   true,
+	HttpOnly: true,
+	SameSite: http.SameSiteLaxMode,
+}
+
+// ForDevelopment returns the options without Secure attribute to use the cookies on http://localhost.
+// Do not use it in production.
+func (o CookieOptions) ForDevelopment() CookieOptions {
+	o.Secure = false
+	return o
+}
+
+// validate returns error for the combination rejected by browsers
+func (o CookieOptions) validate() error {
+	if o.SameSite == http.SameSiteNoneMode && !o.Secure {
+		return errors.New("SameSite=None cookie must be Secure")
+	}
+	return nil
+}
+
+// name returns the cookie name, or the default name when not set in the options
+func (o CookieOptions) name(defaultName string) string {
+	if o.Name != "" {
+		return o.Name
+	}
+	return defaultName
+}
+
+// cookie returns the cookie of the value. The default name and lifetime are used when not set in the options.
+func (o CookieOptions) cookie(defaultName, value string, defaultMaxAge time.Duration) *http.Cookie {
+	maxAge := o.MaxAge
+	if maxAge == 0 {
+		maxAge = defaultMaxAge
+	}
+	return &http.Cookie{
+		Name:     o.name(defaultName),
+		Value:    value,
+		Domain:   o.Domain,
+		Path:     o.Path,
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   o.Secure,
+		HttpOnly: o.HttpOnly,
+		SameSite: o.SameSite,
+	}
+}
+
+// expiredCookie returns the cookie deleting the cookie set by the options
+func (o CookieOptions) expiredCookie(defaultName string) *http.Cookie {
+	c := o.cookie(defaultName, "", 0)
+	c.MaxAge = -1
+	return c
+}
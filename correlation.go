@@ -0,0 +1,42 @@
+package goline
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+)
+
+// WithCorrelationIDHeader takes the correlation ID of the request from the header e.g. "X-Request-Id".
+// The ID is added to the log entries as "correlationId", set in AuditEvent.CorrelationID
+// and Exchange.CorrelationID of the calls to LINE, and available by CorrelationIDFromContext.
+func WithCorrelationIDHeader(name string) AuthorizerOption {
+	return func(a *Authorizer) {
+		a.correlationHeader = http.CanonicalHeaderKey(name)
+	}
+}
+
+type correlationIDContextKey struct{}
+
+// SetCorrelationID returns the context with the correlation ID.
+// Use it to correlate the calls of Client and Authorizer not in the middlewares, e.g. in gRPC servers.
+func SetCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDContextKey{}, id)
+}
+
+// CorrelationIDFromContext returns the correlation ID in the context, or empty when not set
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDContextKey{}).(string)
+	return id
+}
+
+// withCorrelationID sets the correlation ID of the request in the context and the logger
+func (a *Authorizer) withCorrelationID(r *http.Request, log *slog.Logger) (*http.Request, *slog.Logger) {
+	if a.correlationHeader == "" {
+		return r, log
+	}
+	id := r.Header.Get(a.correlationHeader)
+	if id == "" {
+		return r, log
+	}
+	return r.WithContext(SetCorrelationID(r.Context(), id)), log.With("correlationId", id)
+}
@@ -0,0 +1,132 @@
+package goline
+
+import (
+	"context"
+	"net/http"
+)
+
+const (
+	defaultCSRFCookieName = "goline_csrf"
+	// DefaultCSRFHeader is the default request header of the CSRF token
+	DefaultCSRFHeader = "X-CSRF-Token"
+)
+
+// CSRFMode is the protection mode of CSRFProtection
+type CSRFMode int
+
+const (
+	// CSRFModeDoubleSubmit requires the CSRF header equal to the CSRF cookie set by the middleware
+	CSRFModeDoubleSubmit CSRFMode = iota
+	// CSRFModeCustomHeader requires only the presence of the CSRF header, relying on that browsers do not send
+	// custom headers cross-site without CORS preflight. Use it with SameSite cookies and a strict CORS policy.
+	CSRFModeCustomHeader
+)
+
+// csrfOptions is the options of CSRFProtection
+type csrfOptions struct {
+	mode   CSRFMode
+	header string
+	cookie CookieOptions
+}
+
+// CSRFOption configures CSRFProtection
+type CSRFOption func(o *csrfOptions)
+
+// WithCSRFMode sets the protection mode. Default is CSRFModeDoubleSubmit.
+func WithCSRFMode(m CSRFMode) CSRFOption {
+	return func(o *csrfOptions) {
+		o.mode = m
+	}
+}
+
+// WithCSRFHeader sets the request header of the CSRF token. Default is X-CSRF-Token.
+func WithCSRFHeader(name string) CSRFOption {
+	return func(o *csrfOptions) {
+		o.header = name
+	}
+}
+
+// WithCSRFCookie sets the attributes of the CSRF cookie. Default is DefaultCookieOptions named "goline_csrf"
+// without HttpOnly, so that the scripts can read the token, and living for the browser session.
+func WithCSRFCookie(c CookieOptions) CSRFOption {
+	return func(o *csrfOptions) {
+		o.cookie = c
+	}
+}
+
+type csrfTokenContextKey struct{}
+
+// CSRFTokenFromContext returns the CSRF token set by CSRFProtection to embed in forms or to pass to scripts
+func CSRFTokenFromContext(ctx context.Context) string {
+	t, _ := ctx.Value(csrfTokenContextKey{}).(string)
+	return t
+}
+
+// CSRFProtection returns a middleware protecting the cookie-authenticated requests of unsafe methods
+// e.g. POST from CSRF. It responds 403 Forbidden when the CSRF header is missing or does not match.
+//
+// In CSRFModeDoubleSubmit, the middleware sets a random token in the CSRF cookie, and the clients send it back
+// in the CSRF header e.g. X-CSRF-Token or "csrf_token" form field. The token is available by CSRFTokenFromContext.
+// The requests with a bearer token in authorization header are not checked, as browsers do not attach it cross-site.
+// The other schemes such as Basic are checked, as browsers resend the cached credentials automatically.
+func CSRFProtection(opts ...CSRFOption) func(http.Handler) http.Handler {
+	o := &csrfOptions{header: DefaultCSRFHeader, cookie: DefaultCookieOptions}
+	o.cookie.HttpOnly = false
+	for _, opt := range opts {
+		opt(o)
+	}
+	cookieName := o.cookie.name(defaultCSRFCookieName)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := ""
+			if o.mode == CSRFModeDoubleSubmit {
+				if c, err := r.Cookie(cookieName); err == nil && c.Value != "" {
+					token = c.Value
+				} else {
+					t, err := NewNonce()
+					if err != nil {
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+					token = t
+					http.SetCookie(w, o.cookie.cookie(defaultCSRFCookieName, token, 0))
+				}
+				r = r.WithContext(context.WithValue(r.Context(), csrfTokenContextKey{}, token))
+			}
+
+			if !isSafeMethod(r.Method) && !hasBearerToken(r) && !o.valid(r, token) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// valid reports whether the request has the valid CSRF header or form field
+func (o *csrfOptions) valid(r *http.Request, token string) bool {
+	got := r.Header.Get(o.header)
+	if o.mode == CSRFModeCustomHeader {
+		return got != ""
+	}
+	if got == "" {
+		got = r.PostFormValue("csrf_token")
+	}
+	return got != "" && equalSecret(got, token)
+}
+
+// hasBearerToken reports whether the request has a well-formed bearer token in authorization header
+func hasBearerToken(r *http.Request) bool {
+	_, err := extractBearerToken(r.Header.Get(authHeader))
+	return err == nil
+}
+
+// isSafeMethod reports whether the method is safe by RFC 9110
+func isSafeMethod(m string) bool {
+	switch m {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package goline
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCSRFProtection(t *testing.T) {
+	h := CSRFProtection()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// Get the token by a safe request
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET status = %d", w.Code)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != defaultCSRFCookieName {
+		t.Fatalf("cookies = %v", cookies)
+	}
+	token := cookies[0].Value
+
+	tests := []struct {
+		name   string
+		header http.Header
+		form   url.Values
+		want   int
+	}{
+		{name: "header", header: http.Header{DefaultCSRFHeader: {token}}, want: http.StatusOK},
+		{name: "form", form: url.Values{"csrf_token": {token}}, want: http.StatusOK},
+		{name: "missing", want: http.StatusForbidden},
+		{name: "mismatch", header: http.Header{DefaultCSRFHeader: {"other"}}, want: http.StatusForbidden},
+		{name: "bearer", header: http.Header{"Authorization": {"Bearer token"}}, want: http.StatusOK},
+		{name: "bearer lower case", header: http.Header{"Authorization": {"bearer token"}}, want: http.StatusOK},
+		{name: "basic", header: http.Header{"Authorization": {"Basic dXNlcjpwYXNz"}}, want: http.StatusForbidden},
+		{name: "malformed bearer", header: http.Header{"Authorization": {"Bearer"}}, want: http.StatusForbidden},
+		{name: "basic with token", header: http.Header{"Authorization": {"Basic dXNlcjpwYXNz"}, DefaultCSRFHeader: {token}}, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r *http.Request
+			if tt.form != nil {
+				r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.form.Encode()))
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			} else {
+				r = httptest.NewRequest(http.MethodPost, "/", nil)
+			}
+			for k, v := range tt.header {
+				r.Header.Set(k, v[0])
+			}
+			r.AddCookie(cookies[0])
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package goline
+
+import (
+	"context"
+	"net/http"
+)
+
+// DegradationPolicy controls the middlewares of Authorizer when LINE APIs are unreachable,
+// on network errors, 429 Too Many Requests and 5xx.
+type DegradationPolicy int
+
+const (
+	// FailClosed rejects the requests with 401 Unauthorized. It is the default.
+	FailClosed DegradationPolicy = iota
+	// FailOpenWithLocalVerify verifies the ID tokens locally by the LocalVerifier of WithDegradationLocalVerifier,
+	// and falls back to FailOpenCachedOnly for the access tokens and when the local verification is unavailable.
+	FailOpenWithLocalVerify
+	// FailOpenCachedOnly accepts the tokens verified by LINE before and not expired yet
+	FailOpenCachedOnly
+)
+
+// String returns the name of the policy
+func (p DegradationPolicy) String() string {
+	switch p {
+	case FailClosed:
+		return "FailClosed"
+	case FailOpenWithLocalVerify:
+		return "FailOpenWithLocalVerify"
+	case FailOpenCachedOnly:
+		return "FailOpenCachedOnly"
+	}
+	return "Unknown"
+}
+
+// WithDegradationPolicy sets the default DegradationPolicy of the middlewares.
+// It also keeps the users verified by LINE until the token expiry, up to 10000 tokens in LRU order,
+// for FailOpenCachedOnly. Set FailClosed to enable the cache and relax it per route by DegradationPolicyMiddleware.
+func WithDegradationPolicy(p DegradationPolicy) AuthorizerOption {
+	return func(a *Authorizer) {
+		a.degradation = p
+		a.lastVerified = newVerificationCache(0)
+	}
+}
+
+// WithDegradationLocalVerifier sets LocalVerifier used by FailOpenWithLocalVerify
+func WithDegradationLocalVerifier(v *LocalVerifier) AuthorizerOption {
+	return func(a *Authorizer) {
+		a.degradationVerifier = v
+	}
+}
+
+type degradationPolicyContextKey struct{}
+
+// DegradationPolicyMiddleware returns a middleware overriding the DegradationPolicy of the middlewares of Authorizer
+// for the route, e.g. FailOpenCachedOnly for the read-only pages and FailClosed for the payments.
+// It must be run before the middlewares of Authorizer.
+//
+//	r.With(goline.DegradationPolicyMiddleware(goline.FailOpenCachedOnly), lineAuth.VerifyIDTokenMiddleware).Get("/feed", feedHandler)
+func DegradationPolicyMiddleware(p DegradationPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), degradationPolicyContextKey{}, p)))
+		})
+	}
+}
+
+// degradationPolicy returns the policy of the route or the default
+func (a *Authorizer) degradationPolicy(ctx context.Context) DegradationPolicy {
+	if p, ok := ctx.Value(degradationPolicyContextKey{}).(DegradationPolicy); ok {
+		return p
+	}
+	return a.degradation
+}
+
+// rememberVerified keeps the user verified by LINE for FailOpenCachedOnly
+func (a *Authorizer) rememberVerified(kind, token string, u *User) {
+	if a.lastVerified == nil || u.ExpiresAt.IsZero() {
+		return
+	}
+	// Keep a copy as u is completed by the validators and the enricher of the request
+	a.lastVerified.set(tokenKey(kind, token), u.clone(), nil, a.lineClient.clock.Now(), u.ExpiresAt)
+}
+
+// degrade returns the user authorized by the DegradationPolicy when LINE is unreachable.
+// ok is false when the request must be rejected.
+func (a *Authorizer) degrade(ctx context.Context, kind, token string, err error) (u *User, ok bool) {
+	p := a.degradationPolicy(ctx)
+	if p == FailClosed || !IsRetryable(err) {
+		return nil, false
+	}
+
+	if p == FailOpenWithLocalVerify && kind == "id_token" && a.degradationVerifier != nil {
+		if d, verr := a.degradationVerifier.Verify(ctx, token, nil); verr == nil {
+			u = userFromIDToken(d)
+		}
+	}
+	if u == nil && a.lastVerified != nil {
+		if v, _, found := a.lastVerified.get(tokenKey(kind, token), a.lineClient.clock.Now()); found {
+			// Copy not to share the user among requests
+			u = v.(*User).clone()
+		}
+	}
+	if u == nil {
+		return nil, false
+	}
+
+	u.Degraded = true
+	a.log.WarnContext(ctx, "authorized by degradation policy as LINE is unavailable",
+		"policy", p.String(), a.userGroup(u), "error", err)
+	return u, true
+}
@@ -0,0 +1,120 @@
+package goline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFlakyLINE returns http.Client of the test LINE server verifying any ID token of channel "123" while "down" is false
+func newFlakyLINE(t *testing.T, down *atomic.Bool) *http.Client {
+	_, hc := newTestLINE(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"iss": "https://access.line.me",
+			"sub": "U" + r.PostFormValue("id_token"),
+			"aud": "123",
+			"exp": time.Now().Add(time.Hour).Unix(),
+			"iat": time.Now().Unix(),
+		})
+	}))
+	return hc
+}
+
+func TestDegradationFailOpenCachedOnly(t *testing.T) {
+	var down atomic.Bool
+	enrichments := 0
+	a := NewAuthorizer(NewClient("123", newFlakyLINE(t, &down)), nil,
+		WithDegradationPolicy(FailOpenCachedOnly),
+		WithEnricher(EnricherFunc(func(ctx context.Context, u *User) (*Enrichment, error) {
+			enrichments++
+			return &Enrichment{InternalID: "internal-" + u.ID}, nil
+		}), 0))
+	ctx := context.Background()
+
+	u, err := a.AuthenticateIDToken(ctx, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Degraded || u.Enrichment == nil {
+		t.Fatalf("got %+v, want enriched user not degraded", u)
+	}
+	u.Claims["mutated"] = true
+
+	down.Store(true)
+	d, err := a.AuthenticateIDToken(ctx, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Degraded || d.ID != "Utoken" {
+		t.Errorf("got %+v, want degraded user Utoken", d)
+	}
+	if d == u {
+		t.Error("the user of the previous request is returned")
+	}
+	if _, ok := d.Claims["mutated"]; ok {
+		t.Error("the kept user is modified by the previous request")
+	}
+	if d.Enrichment == nil || enrichments != 2 {
+		t.Errorf("enrichment %+v called %d times, want enriched again", d.Enrichment, enrichments)
+	}
+
+	if _, err := a.AuthenticateIDToken(ctx, "unknown"); err == nil {
+		t.Error("want error for the token not verified before")
+	}
+}
+
+func TestDegradationConcurrentEnrichment(t *testing.T) {
+	// LINE fails every other request, so that the verified users are kept and degraded concurrently.
+	// The responses are made in memory not to synchronize the requests by the connection pool.
+	var n atomic.Int64
+	hc := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if n.Add(1)%2 == 0 {
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody, Request: r}, nil
+		}
+		b, _ := json.Marshal(map[string]interface{}{
+			"iss": "https://access.line.me", "sub": "U1", "aud": "123", "exp": time.Now().Add(time.Hour).Unix(), "iat": time.Now().Unix(),
+		})
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Type": {"application/json"}},
+			Body: io.NopCloser(bytes.NewReader(b)), Request: r}, nil
+	})}
+	a := NewAuthorizer(NewClient("123", hc), nil,
+		WithDegradationPolicy(FailOpenCachedOnly),
+		WithEnricher(EnricherFunc(func(ctx context.Context, u *User) (*Enrichment, error) {
+			return &Enrichment{InternalID: u.ID}, nil
+		}), 0))
+	ctx := context.Background()
+	if _, err := a.AuthenticateIDToken(ctx, "token"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Run with -race
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				u, err := a.AuthenticateIDToken(ctx, "token")
+				if err != nil {
+					t.Errorf("AuthenticateIDToken() error = %v", err)
+					return
+				}
+				if u.Enrichment == nil || u.Enrichment.InternalID != "U1" {
+					t.Errorf("got enrichment %+v, want U1", u.Enrichment)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,51 @@
+package goline
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+)
+
+// EnvDevAuthorizer is the environment variable which must be "true" to enable NewDevAuthorizer
+const EnvDevAuthorizer = "GOLINE_DEV_AUTHORIZER"
+
+// ErrDevAuthorizerDisabled is returned by NewDevAuthorizer when GOLINE_DEV_AUTHORIZER is not "true"
+var ErrDevAuthorizerDisabled = errors.New("dev authorizer is disabled: set " + EnvDevAuthorizer + "=true to enable it")
+
+// devUser is the fake user of the Authorizer for local development
+type devUser struct {
+	token string
+	user  User
+}
+
+// NewDevAuthorizer returns an Authorizer for local development accepting only the static token as both ID token and
+// access token, and authenticating it as the fake LINE user, so that frontend and backend can run without real LINE tokens.
+// No requests are sent to LINE. The validators and the enricher set by opts are applied as usual.
+//
+// To prevent enabling it in production by accident, it fails with ErrDevAuthorizerDisabled
+// unless the environment variable GOLINE_DEV_AUTHORIZER is "true", and it logs a warning on every authentication.
+func NewDevAuthorizer(token string, u User, log *slog.Logger, opts ...AuthorizerOption) (*Authorizer, error) {
+	if os.Getenv(EnvDevAuthorizer) != "true" {
+		return nil, ErrDevAuthorizerDisabled
+	}
+	if token == "" {
+		return nil, errors.New("dev token not found")
+	}
+	if u.ID == "" {
+		return nil, errors.New("dev user ID not found")
+	}
+	a := NewAuthorizer(NewClient("", nil), log, opts...)
+	a.dev = &devUser{token: token, user: u}
+	a.log.Warn("dev authorizer is enabled. Do not use it in production", "userId", u.ID)
+	return a, nil
+}
+
+// authenticateDev authenticates the static token as the fake user
+func (a *Authorizer) authenticateDev(token string) (*User, error) {
+	if !equalSecret(token, a.dev.token) {
+		return nil, ErrTokenRevoked
+	}
+	a.log.Warn("authenticated by dev authorizer", "userId", a.dev.user.ID)
+	// Copy not to share the user among requests
+	return a.dev.user.clone(), nil
+}
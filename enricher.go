@@ -0,0 +1,93 @@
+package goline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrEnrichmentFailed is wrapped in the error returned when Enricher fails.
+	// The middlewares respond 500 Internal Server Error for it since the token itself is valid.
+	ErrEnrichmentFailed = errors.New("enrichment failed")
+)
+
+// Enrichment is the application-specific data of the authenticated user added by Enricher.
+// It is shared among requests when cached, so it must not be modified.
+type Enrichment struct {
+	// InternalID is the user ID in the application mapped from LINE user ID
+	InternalID string
+	// Headers are set in the request headers by the middlewares. Only the names given to WithEnricher are set.
+	Headers map[string]string
+	// Values are any other data available by UserFromContext
+	Values map[string]interface{}
+}
+
+// Enricher adds the application-specific data to the user after successful verification and validation,
+// e.g. to map LINE user ID to the internal user ID without a second wrapper middleware.
+type Enricher interface {
+	Enrich(ctx context.Context, user *User) (*Enrichment, error)
+}
+
+// EnricherFunc is an adapter to use an ordinary function as Enricher
+type EnricherFunc func(ctx context.Context, user *User) (*Enrichment, error)
+
+// Enrich calls f(ctx, user)
+func (f EnricherFunc) Enrich(ctx context.Context, user *User) (*Enrichment, error) {
+	return f(ctx, user)
+}
+
+// WithEnricher sets Enricher. The enrichment is cached by LINE user ID for cacheTTL, or not cached when it is 0.
+// "headers" are the names of Enrichment.Headers. They are removed from the requests first not to be spoofed.
+func WithEnricher(e Enricher, cacheTTL time.Duration, headers ...string) AuthorizerOption {
+	return func(a *Authorizer) {
+		a.enricher = e
+		a.enrichmentTTL = cacheTTL
+		a.enrichmentHeaders = append([]string(nil), headers...)
+		if cacheTTL > 0 {
+			a.enrichments = newVerificationCache(0)
+		}
+	}
+}
+
+// enrich sets the enrichment to the user
+func (a *Authorizer) enrich(ctx context.Context, u *User) error {
+	if a.enricher == nil {
+		return nil
+	}
+	key := tokenKey("enrichment", u.ID)
+	if a.enrichments != nil {
+		if v, _, ok := a.enrichments.get(key, a.lineClient.clock.Now()); ok {
+			u.Enrichment = v.(*Enrichment)
+			return nil
+		}
+	}
+
+	e, err := a.enricher.Enrich(ctx, u)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrEnrichmentFailed, err)
+	}
+	if e == nil {
+		e = &Enrichment{}
+	}
+	if a.enrichments != nil {
+		now := a.lineClient.clock.Now()
+		a.enrichments.set(key, e, nil, now, now.Add(a.enrichmentTTL))
+	}
+	u.Enrichment = e
+	return nil
+}
+
+// setEnrichmentHeaders sets the headers of the enrichment declared in WithEnricher
+func (a *Authorizer) setEnrichmentHeaders(h http.Header, e *Enrichment) {
+	if e == nil {
+		return
+	}
+	for _, k := range a.enrichmentHeaders {
+		if v := e.Headers[k]; v != "" {
+			h.Set(k, a.encoding.Encode(v))
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package goline
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// IsRetryable reports whether the error of the Client is transient and the call may succeed when retried:
+// timeouts, connection errors (*net.OpError), connections closed unexpectedly, 429 Too Many Requests and 5xx.
+// It is false for the cancellation by the caller, and for the errors which retrying does not solve such as
+// invalid certificates, TLS handshake with a non-TLS server and unsupported URL schemes.
+// Respect APIError.RetryAfter when set. The Client retries by the same rules with WithRetryPolicy,
+// except when the context of the call is done.
+//
+//	if goline.IsRetryable(err) {
+//		// back off and retry, or serve from a fallback
+//	}
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	if isPermanentTransportError(err) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
+
+// isPermanentTransportError reports whether the error is caused by the certificates, the TLS handshake
+// or the configuration of the transport rather than the network
+func isPermanentTransportError(err error) bool {
+	var (
+		unknownAuthorityErr x509.UnknownAuthorityError
+		hostnameErr         x509.HostnameError
+		certInvalidErr      x509.CertificateInvalidError
+		verificationErr     *tls.CertificateVerificationError
+		recordHeaderErr     tls.RecordHeaderError
+	)
+	if errors.As(err, &unknownAuthorityErr) || errors.As(err, &hostnameErr) || errors.As(err, &certInvalidErr) ||
+		errors.As(err, &verificationErr) || errors.As(err, &recordHeaderErr) ||
+		errors.Is(err, ErrTransportNotConfigurable) {
+		return true
+	}
+	// http.Client returns the error without type for the unsupported schemes
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && strings.Contains(urlErr.Err.Error(), "unsupported protocol scheme")
+}
+
+// IsAuthError reports whether the error is a permanent rejection of the token or the credentials,
+// which is not solved by retrying: 400 Bad Request, 401 Unauthorized and 403 Forbidden of LINE including
+// ErrTokenExpired and ErrTokenRevoked, the tokens of other channels, invalid signatures and malformed bearer tokens.
+func IsAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+			return true
+		}
+		return false
+	}
+	return errors.Is(err, ErrClientIDMismatch) ||
+		errors.Is(err, errAudienceMismatch) ||
+		errors.Is(err, ErrInvalidSignature) ||
+		errors.Is(err, ErrBearerTokenNotFound) ||
+		errors.Is(err, ErrBearerTokenMalformed)
+}
@@ -0,0 +1,90 @@
+package goline
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"syscall"
+	"testing"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func urlError(err error) error {
+	return &url.Error{Op: "Get", URL: urlGetUserProfile, Err: err}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "canceled", err: urlError(context.Canceled), want: false},
+		{name: "deadline exceeded", err: urlError(context.DeadlineExceeded), want: true},
+		{name: "timeout", err: urlError(timeoutError{}), want: true},
+		{name: "connection refused", err: urlError(&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}), want: true},
+		{name: "unexpected EOF", err: urlError(io.ErrUnexpectedEOF), want: true},
+		{name: "EOF", err: urlError(io.EOF), want: true},
+		{name: "unknown authority", err: urlError(&tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}), want: false},
+		{name: "hostname", err: urlError(x509.HostnameError{Host: "api.line.me"}), want: false},
+		{name: "expired certificate", err: urlError(x509.CertificateInvalidError{Reason: x509.Expired}), want: false},
+		{name: "record header", err: urlError(tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}), want: false},
+		{name: "unsupported scheme", err: urlError(errors.New(`unsupported protocol scheme "ftp"`)), want: false},
+		{name: "transport not configurable", err: urlError(ErrTransportNotConfigurable), want: false},
+		{name: "other url error", err: urlError(errors.New("unknown")), want: false},
+		{name: "429", err: &APIError{StatusCode: http.StatusTooManyRequests}, want: true},
+		{name: "503 wrapped", err: fmt.Errorf("failed: %w", &APIError{StatusCode: http.StatusServiceUnavailable}), want: true},
+		{name: "400", err: &APIError{StatusCode: http.StatusBadRequest}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRetryableClientErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("untrusted certificate", func(t *testing.T) {
+		s := httptest.NewUnstartedServer(http.NotFoundHandler())
+		s.Config.ErrorLog = log.New(io.Discard, "", 0)
+		s.StartTLS()
+		t.Cleanup(s.Close)
+		hc := &http.Client{Transport: &rewriteTransport{host: s.Listener.Addr().String(), scheme: "https", base: http.DefaultTransport}}
+		_, err := NewClient("123", hc).GetProfile(ctx, "token")
+		if err == nil || IsRetryable(err) {
+			t.Errorf("IsRetryable(%v) = true, want false", err)
+		}
+	})
+
+	t.Run("connection refused", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr := l.Addr().String()
+		l.Close()
+		hc := &http.Client{Transport: &rewriteTransport{host: addr, base: http.DefaultTransport}}
+		_, err = NewClient("123", hc).GetProfile(ctx, "token")
+		if !IsRetryable(err) {
+			t.Errorf("IsRetryable(%v) = false, want true", err)
+		}
+	})
+}
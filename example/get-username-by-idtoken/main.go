@@ -19,7 +19,7 @@ func main() {
 
 	line := goline.NewClient(clientid, http.DefaultClient)
 
-	p, err := line.VerifyIDToken(ctx, idtoken, "", "")
+	p, err := line.VerifyIDToken(ctx, idtoken, nil)
 	if err != nil {
 		log.Fatalln(err)
 	}
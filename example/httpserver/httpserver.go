@@ -6,9 +6,9 @@ import (
 	"log"
 	"net/http"
 
-	"github.com/go-logr/zapr"
 	"github.com/gorilla/mux"
 	"github.com/jlandowner/goline"
+	"github.com/jlandowner/goline/logadapter"
 	"go.uber.org/zap"
 )
 
@@ -27,18 +27,18 @@ func main() {
 	router := mux.NewRouter()
 	router.HandleFunc("/", helloHandler)
 
-	// Setup logr
+	// Setup logger
 	zapLog, err := zap.NewDevelopment()
 	if err != nil {
 		panic(err)
 	}
-	log := zapr.NewLogger(zapLog)
+	log := logadapter.FromZap(zapLog)
 
 	// Setup Client
 	lineClient := goline.NewClient(*clientid, http.DefaultClient)
 
 	// Setup Authorizer
-	lineAuth := goline.NewAuthorizer(lineClient, zapr.NewLogger(zapLog))
+	lineAuth := goline.NewAuthorizer(lineClient, log)
 
 	// Use VerifyIDTokenMiddleware
 	router.Use(lineAuth.VerifyIDTokenMiddleware)
@@ -48,6 +48,6 @@ func main() {
 
 	err = http.ListenAndServe(":3000", router)
 	if !errors.Is(err, http.ErrServerClosed) {
-		log.Error(err, "unexpected err")
+		log.Error("unexpected err", "error", err)
 	}
 }
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/jlandowner/goline/messaging"
+	"github.com/jlandowner/goline/webhook"
+)
+
+func main() {
+	secret := flag.String("secret", "", "LINE Messaging API Channel secret https://developers.line.biz/ja/reference/messaging-api/#signature-validation")
+	token := flag.String("token", "", "LINE Messaging API Channel access token https://developers.line.biz/ja/reference/messaging-api/#send-reply-message")
+	flag.Parse()
+
+	handler := webhook.NewHandler(*secret,
+		webhook.WithReplyClient(messaging.NewClient(*token, http.DefaultClient)),
+		webhook.WithErrorHandler(func(e *webhook.Event, err error) {
+			if errors.Is(err, messaging.ErrInvalidReplyToken) {
+				log.Println("reply token expired", e.WebhookEventID)
+				return
+			}
+			log.Println("failed to handle event", e.WebhookEventID, err)
+		}),
+	)
+
+	// Echo the received text message
+	handler.OnMessageReply(func(ctx context.Context, e *webhook.Event) ([]messaging.Message, error) {
+		if e.Message.Type != "text" {
+			return nil, nil
+		}
+		return []messaging.Message{&messaging.TextMessage{Text: e.Message.Text}}, nil
+	})
+
+	handler.OnFollow(func(ctx context.Context, e *webhook.Event) error {
+		log.Println("followed by", e.Source.UserID)
+		return nil
+	})
+
+	http.Handle("/webhook", handler)
+
+	err := http.ListenAndServe(":3000", nil)
+	if !errors.Is(err, http.ErrServerClosed) {
+		log.Println("unexpected err", err)
+	}
+}
@@ -0,0 +1,135 @@
+// Package extauthz is an implementation of Envoy ext_authz v3 gRPC service backed by goline.Authorizer.
+// https://www.envoyproxy.io/docs/envoy/latest/api-v3/service/auth/v3/external_auth.proto
+package extauthz
+
+import (
+	"context"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/jlandowner/goline"
+)
+
+const (
+	// DefaultMetadataKey is the default key of the LINE user in dynamic metadata
+	DefaultMetadataKey = "line_user"
+)
+
+// Server is Envoy ext_authz v3 AuthorizationServer verifying LINE tokens in authorization header.
+// The authorized LINE user is injected in request headers and emitted as dynamic metadata.
+type Server struct {
+	authv3.UnimplementedAuthorizationServer
+
+	authorizer   *goline.Authorizer
+	authenticate func(ctx context.Context, token string) (*goline.User, error)
+	metadataKey  string
+}
+
+// Option configures Server
+type Option func(*Server)
+
+// WithAccessToken verifies access tokens instead of ID tokens
+func WithAccessToken() Option {
+	return func(s *Server) {
+		s.authenticate = s.authorizer.AuthenticateAccessToken
+	}
+}
+
+// WithMetadataKey sets the key of the LINE user in dynamic metadata. Default is "line_user".
+func WithMetadataKey(key string) Option {
+	return func(s *Server) {
+		s.metadataKey = key
+	}
+}
+
+// NewServer returns new Server. ID tokens are verified by default.
+func NewServer(a *goline.Authorizer, opts ...Option) *Server {
+	s := &Server{
+		authorizer:   a,
+		authenticate: a.AuthenticateIDToken,
+		metadataKey:  DefaultMetadataKey,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Register registers Server to the grpc server
+func (s *Server) Register(g *grpc.Server) {
+	authv3.RegisterAuthorizationServer(g, s)
+}
+
+// Check implements authv3.AuthorizationServer
+func (s *Server) Check(ctx context.Context, req *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+	// Envoy sends header keys in lowercase
+	authHeader := req.GetAttributes().GetRequest().GetHttp().GetHeaders()["authorization"]
+	if authHeader == "" {
+		return denied("bearer token not found in authorization header"), nil
+	}
+	token, err := goline.ParseBearerToken(authHeader)
+	if err != nil {
+		return denied("failed to extract token form bearer"), nil
+	}
+
+	u, err := s.authenticate(ctx, token)
+	if err != nil {
+		return denied("failed to verify token"), nil
+	}
+
+	metadata, err := userMetadata(s.metadataKey, u)
+	if err != nil {
+		return nil, err
+	}
+	return &authv3.CheckResponse{
+		Status: &status.Status{Code: int32(codes.OK)},
+		HttpResponse: &authv3.CheckResponse_OkResponse{
+			OkResponse: &authv3.OkHttpResponse{
+				Headers:         s.userHeaders(u),
+				HeadersToRemove: s.authorizer.HeaderNames().All(),
+			},
+		},
+		DynamicMetadata: metadata,
+	}, nil
+}
+
+func (s *Server) userHeaders(u *goline.User) []*corev3.HeaderValueOption {
+	h := s.authorizer.UserHeaders(u)
+	opts := make([]*corev3.HeaderValueOption, 0, len(h))
+	for k := range h {
+		opts = append(opts, &corev3.HeaderValueOption{
+			Header:       &corev3.HeaderValue{Key: k, Value: h.Get(k)},
+			AppendAction: corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
+		})
+	}
+	return opts
+}
+
+func userMetadata(key string, u *goline.User) (*structpb.Struct, error) {
+	return structpb.NewStruct(map[string]interface{}{
+		key: map[string]interface{}{
+			"userId":        u.ID,
+			"displayName":   u.DisplayName,
+			"pictureUrl":    u.PictureURL,
+			"email":         u.Email,
+			"statusMessage": u.StatusMessage,
+		},
+	})
+}
+
+func denied(message string) *authv3.CheckResponse {
+	return &authv3.CheckResponse{
+		Status: &status.Status{Code: int32(codes.Unauthenticated), Message: message},
+		HttpResponse: &authv3.CheckResponse_DeniedResponse{
+			DeniedResponse: &authv3.DeniedHttpResponse{
+				Status: &typev3.HttpStatus{Code: typev3.StatusCode_Unauthorized},
+			},
+		},
+	}
+}
@@ -0,0 +1,97 @@
+package goline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// ErrFingerprintMismatch is returned when the client fingerprint does not match the one bound to the session
+var ErrFingerprintMismatch = errors.New("client fingerprint does not match")
+
+// Fingerprinter hashes the client attributes, the user agent and the IP prefix, to bind the session tokens
+// to the client, mitigating replays of stolen cookies. Store Fingerprint of the login request in the session
+// e.g. in LoginHook, and check it in the later requests by Verify or RequireFingerprint.
+//
+// The IP prefix tolerates the address changes in the network, e.g. /24 for IPv4 and /48 for IPv6 by default.
+// The peer address is used and forwarded headers are not trusted. It is for high-security deployments,
+// as the users are logged out when they move between networks.
+type Fingerprinter struct {
+	ipv4Bits int
+	ipv6Bits int
+}
+
+// FingerprintOption configures Fingerprinter
+type FingerprintOption func(*Fingerprinter)
+
+// WithFingerprintIPPrefix sets the prefix lengths of the client IP address. 0 ignores the address.
+// Default is 24 for IPv4 and 48 for IPv6. NewFingerprinter returns error unless they are 0-32 and 0-128.
+func WithFingerprintIPPrefix(ipv4Bits, ipv6Bits int) FingerprintOption {
+	return func(f *Fingerprinter) {
+		f.ipv4Bits = ipv4Bits
+		f.ipv6Bits = ipv6Bits
+	}
+}
+
+// NewFingerprinter returns new Fingerprinter
+func NewFingerprinter(opts ...FingerprintOption) (*Fingerprinter, error) {
+	f := &Fingerprinter{ipv4Bits: 24, ipv6Bits: 48}
+	for _, opt := range opts {
+		opt(f)
+	}
+	// net.CIDRMask returns nil for the invalid lengths, which would make the fingerprints of all addresses the same
+	if f.ipv4Bits < 0 || f.ipv4Bits > 32 {
+		return nil, fmt.Errorf("invalid IPv4 prefix length: %d", f.ipv4Bits)
+	}
+	if f.ipv6Bits < 0 || f.ipv6Bits > 128 {
+		return nil, fmt.Errorf("invalid IPv6 prefix length: %d", f.ipv6Bits)
+	}
+	return f, nil
+}
+
+// Fingerprint returns the hex-encoded SHA-256 hash of the user agent and the IP prefix of the request
+func (f *Fingerprinter) Fingerprint(r *http.Request) string {
+	h := sha256.New()
+	h.Write([]byte(r.UserAgent()))
+	h.Write([]byte{0})
+	h.Write([]byte(f.ipPrefix(remoteIP(r))))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Verify returns ErrFingerprintMismatch when the fingerprint of the request does not match the bound fingerprint
+func (f *Fingerprinter) Verify(r *http.Request, fingerprint string) error {
+	if fingerprint == "" || !equalSecret(f.Fingerprint(r), fingerprint) {
+		return ErrFingerprintMismatch
+	}
+	return nil
+}
+
+// RequireFingerprint returns a middleware rejecting the requests with 401 Unauthorized when the fingerprint
+// does not match the one bound to the session. lookup returns the bound fingerprint of the session of the request,
+// and false when the request has no session, which is passed to the next handler as is.
+func (f *Fingerprinter) RequireFingerprint(lookup func(r *http.Request) (string, bool)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if fp, ok := lookup(r); ok && f.Verify(r, fp) != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ipPrefix returns the network of the IP address masked by the prefix length
+func (f *Fingerprinter) ipPrefix(addr string) string {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return addr
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(f.ipv4Bits, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(f.ipv6Bits, 128)).String()
+}
@@ -0,0 +1,73 @@
+package goline
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewFingerprinterPrefix(t *testing.T) {
+	tests := []struct {
+		v4, v6 int
+		ok     bool
+	}{
+		{24, 48, true},
+		{0, 0, true},
+		{32, 128, true},
+		{33, 48, false},
+		{-1, 48, false},
+		{24, 129, false},
+		{24, -1, false},
+	}
+	for _, tt := range tests {
+		_, err := NewFingerprinter(WithFingerprintIPPrefix(tt.v4, tt.v6))
+		if (err == nil) != tt.ok {
+			t.Errorf("NewFingerprinter(%d, %d) error = %v, want ok %v", tt.v4, tt.v6, err, tt.ok)
+		}
+	}
+}
+
+func TestFingerprinter(t *testing.T) {
+	f, err := NewFingerprinter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(addr, ua string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = addr
+		r.Header.Set("User-Agent", ua)
+		return r
+	}
+	fp := f.Fingerprint(request("192.0.2.1:1234", "ua"))
+
+	tests := []struct {
+		name string
+		r    *http.Request
+		want error
+	}{
+		{name: "same", r: request("192.0.2.1:5678", "ua")},
+		{name: "same prefix", r: request("192.0.2.200:1234", "ua")},
+		{name: "other network", r: request("198.51.100.1:1234", "ua"), want: ErrFingerprintMismatch},
+		{name: "other user agent", r: request("192.0.2.1:1234", "other"), want: ErrFingerprintMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := f.Verify(tt.r, fp); !errors.Is(err, tt.want) {
+				t.Errorf("Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if err := f.Verify(request("192.0.2.1:1234", "ua"), ""); err == nil {
+		t.Error("Verify() with empty fingerprint, want error")
+	}
+
+	// IPv6 by /48
+	v6 := f.Fingerprint(request("[2001:db8:1::1]:1234", "ua"))
+	if f.Fingerprint(request("[2001:db8:1:ffff::1]:1234", "ua")) != v6 {
+		t.Error("the fingerprints in the same /48 differ")
+	}
+	if f.Fingerprint(request("[2001:db8:2::1]:1234", "ua")) == v6 {
+		t.Error("the fingerprints in the other /48 are the same")
+	}
+}
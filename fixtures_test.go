@@ -0,0 +1,279 @@
+package goline_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jlandowner/goline"
+	"github.com/jlandowner/goline/internal/openapi"
+)
+
+// The recorded responses of LINE in testdata/fixtures are checked against the OpenAPI description
+// and the generated response types, to catch schema changes of LINE early.
+//
+// -record refreshes the fixtures against the live API with the credentials in the environment variables
+// LINE_CHANNEL_ID, LINE_ID_TOKEN and LINE_ACCESS_TOKEN. The personal data and the tokens are replaced by placeholders.
+// Review the diff before committing, as the values of new properties are kept as is.
+//
+//	LINE_CHANNEL_ID=1234567890 LINE_ID_TOKEN=xxx LINE_ACCESS_TOKEN=xxx go test -run TestFixtures -record
+var record = flag.Bool("record", false, "Refresh testdata/fixtures against the live LINE API")
+
+const (
+	fixturesDir = "testdata/fixtures"
+	specPath    = "openapi/line-login.yaml"
+)
+
+// fixtureTypes returns new value of the Go type of the schema
+var fixtureTypes = map[string]func() interface{}{
+	"IDTokenData":               func() interface{} { return &goline.IDTokenData{} },
+	"VerifyAccessTokenResponse": func() interface{} { return &goline.VerifyAccessTokenResponse{} },
+	"TokenResponse":             func() interface{} { return &goline.TokenResponse{} },
+	"LINEProfile":               func() interface{} { return &goline.LINEProfile{} },
+}
+
+// placeholders replace the personal data and the tokens in the recorded responses
+var placeholders = map[string]interface{}{
+	"sub":           "U00000000000000000000000000000000",
+	"userId":        "U00000000000000000000000000000000",
+	"aud":           "1234567890",
+	"client_id":     "1234567890",
+	"name":          "Taro Line",
+	"displayName":   "Taro Line",
+	"picture":       "https://profile.line-scdn.net/example",
+	"pictureUrl":    "https://profile.line-scdn.net/example",
+	"statusMessage": "Hello, LINE!",
+	"email":         "taro.line@example.com",
+	"nonce":         "nonce",
+	"access_token":  "access-token",
+	"refresh_token": "refresh-token",
+	"id_token":      "id-token",
+}
+
+func TestFixtures(t *testing.T) {
+	if *record {
+		if err := recordFixtures(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	schemas, err := openapi.LoadSchemas(specPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schemas) != len(fixtureTypes) {
+		t.Errorf("%d schemas in %s, %d Go types registered", len(schemas), specPath, len(fixtureTypes))
+	}
+	for _, s := range schemas {
+		t.Run(s.Name, func(t *testing.T) {
+			b, err := os.ReadFile(filepath.Join(fixturesDir, s.Name+".json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(b, &fields); err != nil {
+				t.Fatal(err)
+			}
+
+			// Against the OpenAPI description
+			for _, p := range s.Properties {
+				v, ok := fields[p.Name]
+				if !ok {
+					if s.Required[p.Name] {
+						t.Errorf("required property %q is missing", p.Name)
+					}
+					continue
+				}
+				if got := jsonType(v); got != p.Type && !(p.Type == "number" && got == "integer") {
+					t.Errorf("property %q is %s, want %s", p.Name, got, p.Type)
+				}
+			}
+			for name := range fields {
+				if s.Property(name) == nil {
+					t.Errorf("property %q is not in the OpenAPI description", name)
+				}
+			}
+
+			// Against the generated Go type
+			newType, ok := fixtureTypes[s.Name]
+			if !ok {
+				t.Fatal("no Go type registered")
+			}
+			v := newType()
+			if err := json.Unmarshal(b, v); err != nil {
+				t.Fatalf("failed to decode: %v", err)
+			}
+			setRaw(v, b)
+			extra, err := goline.ExtraFields(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name := range extra {
+				t.Errorf("property %q is not in the Go type, run go generate", name)
+			}
+		})
+	}
+}
+
+// TestFixturesDecodedByClient decodes the fixtures through the Client as the responses of LINE, with the strict decoding
+func TestFixturesDecodedByClient(t *testing.T) {
+	fixture := func(name string) []byte {
+		b, err := os.ReadFile(filepath.Join(fixturesDir, name+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	responses := map[string][]byte{
+		http.MethodPost + " /oauth2/v2.1/verify": fixture("IDTokenData"),
+		http.MethodGet + " /oauth2/v2.1/verify":  fixture("VerifyAccessTokenResponse"),
+		http.MethodGet + " /v2/profile":          fixture("LINEProfile"),
+	}
+	hc := newLINEClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, ok := responses[r.Method+" "+r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+	}))
+	c := goline.NewClient("1234567890", hc, goline.WithStrictDecoding())
+	ctx := context.Background()
+
+	d, err := c.VerifyIDToken(ctx, "id-token", nil)
+	if err != nil {
+		t.Fatalf("VerifyIDToken() error = %v", err)
+	}
+	if d.Sub != placeholders["sub"] || d.Exp <= 0 || d.Email != placeholders["email"] {
+		t.Errorf("VerifyIDToken() = %+v", d)
+	}
+
+	v, err := c.VerifyAccessToken(ctx, "access-token")
+	if err != nil {
+		t.Fatalf("VerifyAccessToken() error = %v", err)
+	}
+	if v.ClientID != placeholders["client_id"] || v.ExpiresIn <= 0 {
+		t.Errorf("VerifyAccessToken() = %+v", v)
+	}
+
+	p, err := c.GetProfile(ctx, "access-token")
+	if err != nil {
+		t.Fatalf("GetProfile() error = %v", err)
+	}
+	if p.UserID != placeholders["userId"] || p.DisplayName != placeholders["displayName"] {
+		t.Errorf("GetProfile() = %+v", p)
+	}
+}
+
+// setRaw sets the raw json to the embedded RawResponse as the Client does
+func setRaw(v interface{}, b []byte) {
+	switch r := v.(type) {
+	case *goline.IDTokenData:
+		r.Raw = b
+	case *goline.VerifyAccessTokenResponse:
+		r.Raw = b
+	case *goline.TokenResponse:
+		r.Raw = b
+	case *goline.LINEProfile:
+		r.Raw = b
+	}
+}
+
+// jsonType returns the OpenAPI type of the json value
+func jsonType(v json.RawMessage) string {
+	v = bytes.TrimSpace(v)
+	if len(v) == 0 {
+		return "null"
+	}
+	switch v[0] {
+	case '"':
+		return "string"
+	case '[':
+		return "array"
+	case '{':
+		return "object"
+	case 't', 'f':
+		return "boolean"
+	case 'n':
+		return "null"
+	}
+	if bytes.ContainsAny(v, ".eE") {
+		return "number"
+	}
+	return "integer"
+}
+
+// recordFixtures calls the live API and writes the sanitized responses
+func recordFixtures() error {
+	channelID := os.Getenv("LINE_CHANNEL_ID")
+	idToken := os.Getenv("LINE_ID_TOKEN")
+	accessToken := os.Getenv("LINE_ACCESS_TOKEN")
+	if channelID == "" || (idToken == "" && accessToken == "") {
+		return errors.New("LINE_CHANNEL_ID and LINE_ID_TOKEN or LINE_ACCESS_TOKEN are required to record")
+	}
+	hc := &http.Client{Timeout: 10 * time.Second}
+
+	if idToken != "" {
+		req, _ := http.NewRequest(http.MethodPost, "https://api.line.me/oauth2/v2.1/verify",
+			bytes.NewBufferString(url.Values{"id_token": {idToken}, "client_id": {channelID}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if err := recordResponse(hc, req, "IDTokenData"); err != nil {
+			return err
+		}
+	}
+	if accessToken != "" {
+		req, _ := http.NewRequest(http.MethodGet, "https://api.line.me/oauth2/v2.1/verify?"+
+			url.Values{"access_token": {accessToken}}.Encode(), nil)
+		if err := recordResponse(hc, req, "VerifyAccessTokenResponse"); err != nil {
+			return err
+		}
+		req, _ = http.NewRequest(http.MethodGet, "https://api.line.me/v2/profile", nil)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		if err := recordResponse(hc, req, "LINEProfile"); err != nil {
+			return err
+		}
+	}
+	// TokenResponse is not recorded as it requires an authorization code
+	return nil
+}
+
+func recordResponse(hc *http.Client, req *http.Request, name string) error {
+	res, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, res.Status, b)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	for k := range fields {
+		if p, ok := placeholders[k]; ok {
+			fields[k] = p
+		}
+	}
+	out, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(fixturesDir, name+".json"), append(out, '\n'), 0o644)
+}
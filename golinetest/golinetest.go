@@ -0,0 +1,134 @@
+// Package golinetest provides utilities to test the apps using goline without real LINE tokens,
+// e.g. minting ES256 signed ID tokens with arbitrary claims and serving the matching JWKS.
+package golinetest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+const (
+	// Issuer is "iss" claim of LINE ID tokens
+	Issuer = "https://access.line.me"
+)
+
+// Key is the ES256 key to sign test ID tokens
+type Key struct {
+	ID         string
+	PrivateKey *ecdsa.PrivateKey
+}
+
+// NewKey generates new P-256 Key with a random key ID
+func NewKey() (*Key, error) {
+	pk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	return &Key{ID: hex.EncodeToString(id), PrivateKey: pk}, nil
+}
+
+// Claims returns the standard claims of ID token issued for the channel to the user, valid for ttl from now.
+// Add or overwrite the claims e.g. "name", "nonce" or "amr" as needed.
+func Claims(channelID, userID string, ttl time.Duration) map[string]interface{} {
+	now := time.Now()
+	return map[string]interface{}{
+		"iss": Issuer,
+		"sub": userID,
+		"aud": channelID,
+		"iat": now.Unix(),
+		"exp": now.Add(ttl).Unix(),
+	}
+}
+
+// SignIDToken returns ES256 signed ID token of the claims by the key
+func SignIDToken(claims map[string]interface{}, key *Key) (string, error) {
+	if key == nil || key.PrivateKey == nil {
+		return "", errors.New("key not found")
+	}
+	header, err := json.Marshal(map[string]string{
+		"alg": "ES256",
+		"typ": "JWT",
+		"kid": key.ID,
+	})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	signingInput := enc.EncodeToString(header) + "." + enc.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, key.PrivateKey, digest[:])
+	if err != nil {
+		return "", err
+	}
+	// JWS signature of ES256 is R and S in 32 bytes each
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return signingInput + "." + enc.EncodeToString(sig), nil
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+}
+
+// JWKS is a JSON Web Key Set same as the response of LINE https://api.line.me/oauth2/v2.1/certs
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWKS returns JWKS of the public keys of the keys
+func NewJWKS(keys ...*Key) *JWKS {
+	enc := base64.RawURLEncoding
+	set := &JWKS{Keys: make([]JWK, 0, len(keys))}
+	for _, k := range keys {
+		x := make([]byte, 32)
+		y := make([]byte, 32)
+		k.PrivateKey.X.FillBytes(x)
+		k.PrivateKey.Y.FillBytes(y)
+		set.Keys = append(set.Keys, JWK{
+			Kty: "EC",
+			Crv: "P-256",
+			X:   enc.EncodeToString(x),
+			Y:   enc.EncodeToString(y),
+			Kid: k.ID,
+			Alg: "ES256",
+			Use: "sig",
+		})
+	}
+	return set
+}
+
+// NewJWKSServer returns started httptest.Server serving JWKS of the keys at any path.
+// Close it at the end of the test.
+func NewJWKSServer(keys ...*Key) *httptest.Server {
+	b, _ := json.Marshal(NewJWKS(keys...))
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+	}))
+}
@@ -0,0 +1,73 @@
+package goline
+
+import "net/http"
+
+// VerifyIDTokenFunc is VerifyIDTokenMiddleware for http.HandlerFunc, e.g.
+//
+//	http.HandleFunc("/hello", lineAuth.VerifyIDTokenFunc(helloHandler))
+func (a *Authorizer) VerifyIDTokenFunc(next http.HandlerFunc) http.HandlerFunc {
+	return a.VerifyIDTokenMiddleware(next).ServeHTTP
+}
+
+// VerifyAccessTokenFunc is VerifyAccessTokenMiddleware for http.HandlerFunc
+func (a *Authorizer) VerifyAccessTokenFunc(next http.HandlerFunc) http.HandlerFunc {
+	return a.VerifyAccessTokenMiddleware(next).ServeHTTP
+}
+
+// WrapFunc is Middleware for http.HandlerFunc
+func (c *AuthChain) WrapFunc(next http.HandlerFunc) http.HandlerFunc {
+	return c.Middleware(next).ServeHTTP
+}
+
+// handlerOptions is the options of Authorizer.Handler
+type handlerOptions struct {
+	accessToken bool
+	degradation *DegradationPolicy
+	middlewares []func(http.Handler) http.Handler
+}
+
+// HandlerOption configures Authorizer.Handler
+type HandlerOption func(o *handlerOptions)
+
+// WithHandlerAccessToken verifies the access token instead of the ID token in Authorizer.Handler
+func WithHandlerAccessToken() HandlerOption {
+	return func(o *handlerOptions) {
+		o.accessToken = true
+	}
+}
+
+// WithHandlerDegradationPolicy overrides DegradationPolicy in Authorizer.Handler
+func WithHandlerDegradationPolicy(p DegradationPolicy) HandlerOption {
+	return func(o *handlerOptions) {
+		o.degradation = &p
+	}
+}
+
+// WithHandlerMiddlewares adds the middlewares run in order after the token is verified in Authorizer.Handler,
+// e.g. RequireMaxAge or RequireRole.
+func WithHandlerMiddlewares(middlewares ...func(http.Handler) http.Handler) HandlerOption {
+	return func(o *handlerOptions) {
+		o.middlewares = append(o.middlewares, middlewares...)
+	}
+}
+
+// Handler wraps the handler by VerifyIDTokenMiddleware and the middlewares of the options at once,
+// for the servers not using a router with Use(), e.g.
+//
+//	http.Handle("/pay", lineAuth.Handler(payHandler, goline.WithHandlerMiddlewares(lineAuth.RequireMaxAge(10*time.Minute))))
+func (a *Authorizer) Handler(h http.Handler, opts ...HandlerOption) http.Handler {
+	o := &handlerOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	verify := a.VerifyIDTokenMiddleware
+	if o.accessToken {
+		verify = a.VerifyAccessTokenMiddleware
+	}
+	middlewares := append([]func(http.Handler) http.Handler{verify}, o.middlewares...)
+	if o.degradation != nil {
+		middlewares = append([]func(http.Handler) http.Handler{DegradationPolicyMiddleware(*o.degradation)}, middlewares...)
+	}
+	return RequireAll(middlewares...)(h)
+}
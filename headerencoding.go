@@ -0,0 +1,97 @@
+package goline
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// HeaderEncoding is an encoding of the injected header values which can contain non-ASCII characters
+// such as display name and status message. Downstream services decode them by Decode or DecodeHeader.
+type HeaderEncoding string
+
+const (
+	// HeaderEncodingNone injects values as they are. It is the default.
+	HeaderEncodingNone HeaderEncoding = ""
+	// HeaderEncodingRFC8187 encodes values as ext-value of RFC 8187 like "UTF-8''%E3%81%82".
+	HeaderEncodingRFC8187 HeaderEncoding = "rfc8187"
+	// HeaderEncodingBase64 encodes values by URL-safe base64 without padding
+	HeaderEncodingBase64 HeaderEncoding = "base64"
+)
+
+const rfc8187Prefix = "UTF-8''"
+
+// WithHeaderEncoding sets the encoding of the injected display name and status message.
+// Non-ASCII characters are invalid in HTTP/1.1 header values and can be mangled by proxies.
+func WithHeaderEncoding(e HeaderEncoding) AuthorizerOption {
+	return func(a *Authorizer) {
+		a.encoding = e
+	}
+}
+
+// Encode encodes the header value
+func (e HeaderEncoding) Encode(v string) string {
+	switch e {
+	case HeaderEncodingRFC8187:
+		return rfc8187Prefix + rfc8187Escape(v)
+	case HeaderEncodingBase64:
+		return base64.RawURLEncoding.EncodeToString([]byte(v))
+	default:
+		return v
+	}
+}
+
+// Decode decodes the header value encoded by Encode
+func (e HeaderEncoding) Decode(v string) (string, error) {
+	switch e {
+	case HeaderEncodingRFC8187:
+		if !strings.HasPrefix(strings.ToUpper(v), rfc8187Prefix) {
+			return "", errors.New("not UTF-8 ext-value")
+		}
+		return url.PathUnescape(v[len(rfc8187Prefix):])
+	case HeaderEncodingBase64:
+		b, err := base64.RawURLEncoding.DecodeString(v)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	case HeaderEncodingNone:
+		return v, nil
+	default:
+		return "", fmt.Errorf("unknown header encoding: %s", e)
+	}
+}
+
+// DecodeHeader returns the decoded value of the request header injected by Authorizer
+func DecodeHeader(r *http.Request, key string, e HeaderEncoding) (string, error) {
+	return e.Decode(r.Header.Get(key))
+}
+
+// rfc8187Escape percent-encodes all bytes except attr-char of RFC 8187
+func rfc8187Escape(v string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	b.Grow(len(v))
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if isAttrChar(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0x0f])
+	}
+	return b.String()
+}
+
+func isAttrChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
@@ -0,0 +1,55 @@
+package goline
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	// See https://developers.line.biz/ja/docs/line-login/verify-id-token/#signature
+	urlOpenIDConfiguration = "https://access.line.me/.well-known/openid-configuration"
+)
+
+// HealthCheck checks the connectivity to LINE by fetching the OpenID Connect discovery document,
+// which requires neither tokens nor credentials.
+func (c *Client) HealthCheck(ctx context.Context) error {
+	// Prepare http request
+	req, err := newRequest(http.MethodGet, urlOpenIDConfiguration).build(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Do http request
+	res, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to LINE: %w", err)
+	}
+	defer res.Body.Close()
+	// Check the status before draining, as CheckResponse reads the message of the error response
+	err = CheckResponse(res)
+	// Drain the body to reuse the connection
+	io.CopyN(io.Discard, res.Body, maxDrainSize)
+	if err != nil {
+		return fmt.Errorf("unexpected response from LINE: %w", err)
+	}
+	return nil
+}
+
+// HealthCheckHandler returns a handler responding 200 OK when HealthCheck succeeds, or 503 Service Unavailable.
+// Use it as Kubernetes readiness probe to detect outbound access to LINE is broken.
+//
+//	http.Handle("/readyz", lineClient.HealthCheckHandler())
+func (c *Client) HealthCheckHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := c.HealthCheck(r.Context()); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintln(w, err.Error())
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+}
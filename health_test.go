@@ -0,0 +1,47 @@
+package goline
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHealthCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantErr    error
+		wantInBody string
+	}{
+		{name: "ok", status: http.StatusOK, body: `{"issuer":"https://access.line.me"}`, wantInBody: "ok"},
+		{name: "error message", status: http.StatusServiceUnavailable, body: `{"message":"under maintenance"}`, wantErr: ErrServiceUnavailable, wantInBody: "under maintenance"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, hc := newTestLINE(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			c := NewClient("123", hc)
+
+			err := c.HealthCheck(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("HealthCheck() error = %v, want %v", err, tt.wantErr)
+			}
+
+			w := httptest.NewRecorder()
+			c.HealthCheckHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if want := map[bool]int{true: http.StatusOK, false: http.StatusServiceUnavailable}[tt.wantErr == nil]; w.Code != want {
+				t.Errorf("status = %d, want %d", w.Code, want)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantInBody) {
+				t.Errorf("body = %q, want to contain %q", w.Body.String(), tt.wantInBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package goline
+
+import (
+	"context"
+	"time"
+)
+
+// WithHedging enables hedged requests of VerifyIDToken and VerifyAccessToken to reduce the tail latency of authentication.
+// When LINE does not respond within delay, e.g. the p95 latency, a second request is sent and the first response is taken.
+// It increases the requests to LINE by up to the rate of the slow responses. Zero or less disables hedging.
+func WithHedging(delay time.Duration) ClientOption {
+	return func(c *Client) {
+		c.hedgeDelay = delay
+	}
+}
+
+type hedgeResult struct {
+	val interface{}
+	err error
+}
+
+// hedge calls fn, and calls it again when the first call does not return within the hedging delay.
+// The first success is returned and the other call is canceled.
+// An error is returned when both calls fail, or immediately when it is a definitive rejection.
+func (c *Client) hedge(ctx context.Context, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if c.hedgeDelay <= 0 {
+		return fn(ctx)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult, 2)
+	call := func() {
+		v, err := fn(ctx)
+		results <- hedgeResult{val: v, err: err}
+	}
+	go call()
+
+	timer := time.NewTimer(c.hedgeDelay)
+	defer timer.Stop()
+
+	pending := 1
+	hedged := false
+	for {
+		select {
+		case <-timer.C:
+			if !hedged {
+				hedged = true
+				pending++
+				go call()
+			}
+		case r := <-results:
+			pending--
+			if r.err == nil || isDefinitiveRejection(r.err) {
+				return r.val, r.err
+			}
+			// An error before the hedging delay is returned as is, not to be a retry
+			if !hedged || pending == 0 {
+				return nil, r.err
+			}
+		}
+	}
+}
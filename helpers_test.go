@@ -0,0 +1,40 @@
+package goline
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// rewriteTransport sends the requests to LINE to the test server, by http unless scheme is set
+type rewriteTransport struct {
+	host   string
+	scheme string
+	base   http.RoundTripper
+}
+
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = "http"
+	if t.scheme != "" {
+		req.URL.Scheme = t.scheme
+	}
+	req.URL.Host = t.host
+	return t.base.RoundTrip(req)
+}
+
+// roundTripperFunc is an adapter to use a function as http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// newTestLINE starts the test server of the handler and returns http.Client sending the requests to LINE to it
+func newTestLINE(t testing.TB, h http.Handler) (*httptest.Server, *http.Client) {
+	t.Helper()
+	s := httptest.NewServer(h)
+	t.Cleanup(s.Close)
+	return s, &http.Client{Transport: &rewriteTransport{host: strings.TrimPrefix(s.URL, "http://"), base: s.Client().Transport}}
+}
@@ -0,0 +1,213 @@
+// Command apigen generates the Go response types from components.schemas of the OpenAPI description of LINE endpoints.
+// The hand-written methods of the types stay in the package, so that only the wire format is generated.
+//
+//	go run ./internal/apigen -spec openapi/line-login.yaml -out apitypes_gen.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jlandowner/goline/internal/openapi"
+)
+
+// initialisms are the words written in upper case in Go names
+var initialisms = map[string]bool{"ID": true, "URL": true, "URI": true}
+
+type schema struct {
+	Name         string
+	Description  string
+	DocURL       string
+	Properties   []property
+	ExtraFields  []extraField
+	NeedsTimePkg bool
+}
+
+type property struct {
+	Name        string
+	GoName      string
+	Type        string
+	Description string
+	OmitEmpty   bool
+}
+
+type extraField struct {
+	Name        string `yaml:"name"`
+	Type        string `yaml:"type"`
+	Description string `yaml:"description"`
+}
+
+func main() {
+	var specPath, out, pkg string
+	flag.StringVar(&specPath, "spec", "openapi/line-login.yaml", "OpenAPI description")
+	flag.StringVar(&out, "out", "apitypes_gen.go", "Output Go file")
+	flag.StringVar(&pkg, "package", "goline", "Package name of the output")
+	flag.Parse()
+
+	if err := run(specPath, out, pkg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(specPath, out, pkg string) error {
+	schemas, err := openapi.LoadSchemas(specPath)
+	if err != nil {
+		return err
+	}
+	var types []*schema
+	for _, s := range schemas {
+		t, err := parseSchema(s)
+		if err != nil {
+			return err
+		}
+		types = append(types, t)
+	}
+
+	src, err := generate(pkg, specPath, types)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
+
+func parseSchema(src *openapi.Schema) (*schema, error) {
+	n := src.Node
+	s := &schema{
+		Name:        src.Name,
+		Description: openapi.Scalar(n, "description"),
+		DocURL:      openapi.Scalar(openapi.Lookup(n, "externalDocs"), "url"),
+	}
+	for _, op := range src.Properties {
+		typ, err := goType(op.Node)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", src.Name, op.Name, err)
+		}
+		p := property{
+			Name:        op.Name,
+			GoName:      openapi.Scalar(op.Node, "x-go-name"),
+			Type:        typ,
+			Description: openapi.Scalar(op.Node, "description"),
+			OmitEmpty:   !src.Required[op.Name] && openapi.Scalar(op.Node, "x-omitempty") != "false",
+		}
+		if p.GoName == "" {
+			p.GoName = goName(op.Name)
+		}
+		s.Properties = append(s.Properties, p)
+	}
+
+	if extra := openapi.Lookup(n, "x-go-extra-fields"); extra != nil {
+		if err := extra.Decode(&s.ExtraFields); err != nil {
+			return nil, fmt.Errorf("%s: x-go-extra-fields: %w", src.Name, err)
+		}
+		for _, f := range s.ExtraFields {
+			if strings.HasPrefix(f.Type, "time.") {
+				s.NeedsTimePkg = true
+			}
+		}
+	}
+	return s, nil
+}
+
+// goType returns the Go type of the property schema
+func goType(n *yaml.Node) (string, error) {
+	switch t := openapi.Scalar(n, "type"); t {
+	case "string":
+		return "string", nil
+	case "boolean":
+		return "bool", nil
+	case "integer":
+		if openapi.Scalar(n, "format") == "int64" {
+			return "int64", nil
+		}
+		return "int", nil
+	case "number":
+		return "float64", nil
+	case "array":
+		item, err := goType(openapi.Lookup(n, "items"))
+		if err != nil {
+			return "", err
+		}
+		return "[]" + item, nil
+	default:
+		return "", fmt.Errorf("unsupported type %q", t)
+	}
+}
+
+// goName returns the exported Go name of the json property name in snake_case or camelCase, e.g. client_id -> ClientID
+func goName(name string) string {
+	var words []string
+	start := 0
+	for i, r := range name {
+		switch {
+		case r == '_':
+			words = append(words, name[start:i])
+			start = i + 1
+		case unicode.IsUpper(r) && i > start:
+			words = append(words, name[start:i])
+			start = i
+		}
+	}
+	words = append(words, name[start:])
+
+	var b strings.Builder
+	for _, w := range words {
+		if w == "" {
+			continue
+		}
+		if u := strings.ToUpper(w); initialisms[u] {
+			b.WriteString(u)
+			continue
+		}
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return b.String()
+}
+
+func generate(pkg, specPath string, types []*schema) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by internal/apigen from %s. DO NOT EDIT.\n\n", specPath)
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	for _, s := range types {
+		if s.NeedsTimePkg {
+			b.WriteString("import \"time\"\n\n")
+			break
+		}
+	}
+
+	for _, s := range types {
+		if s.Description != "" {
+			fmt.Fprintf(&b, "// %s\n", s.Description)
+		}
+		if s.DocURL != "" {
+			fmt.Fprintf(&b, "// %s\n", s.DocURL)
+		}
+		fmt.Fprintf(&b, "type %s struct {\n", s.Name)
+		for _, p := range s.Properties {
+			if p.Description != "" {
+				fmt.Fprintf(&b, "// %s\n", p.Description)
+			}
+			tag := p.Name
+			if p.OmitEmpty {
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(&b, "%s %s `json:%q`\n", p.GoName, p.Type, tag)
+		}
+		for _, f := range s.ExtraFields {
+			b.WriteString("\n")
+			if f.Description != "" {
+				fmt.Fprintf(&b, "// %s\n", f.Description)
+			}
+			fmt.Fprintf(&b, "%s %s\n", f.Name, f.Type)
+		}
+		b.WriteString("}\n\n")
+	}
+	return format.Source(b.Bytes())
+}
@@ -0,0 +1,100 @@
+// Package openapi reads components.schemas of the OpenAPI description of LINE endpoints
+// for the code generator and the fixture checker. Only the subset used in openapi/line-login.yaml is supported.
+package openapi
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema is an object schema in components.schemas
+type Schema struct {
+	Name       string
+	Node       *yaml.Node
+	Required   map[string]bool
+	Properties []*Property
+}
+
+// Property is a property of Schema
+type Property struct {
+	Name string
+	Node *yaml.Node
+	// Type is "type" of the property schema e.g. "string" or "array"
+	Type string
+}
+
+// Property returns the property of the name, or nil when not found
+func (s *Schema) Property(name string) *Property {
+	for _, p := range s.Properties {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// LoadSchemas returns the schemas in components.schemas of the file in the order of the definitions
+func LoadSchemas(path string) ([]*Schema, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	schemas := Lookup(Lookup(&doc, "components"), "schemas")
+	if schemas == nil {
+		return nil, fmt.Errorf("components.schemas not found in %s", path)
+	}
+
+	var res []*Schema
+	for i := 0; i+1 < len(schemas.Content); i += 2 {
+		name, n := schemas.Content[i].Value, schemas.Content[i+1]
+		s := &Schema{Name: name, Node: n, Required: make(map[string]bool)}
+		if req := Lookup(n, "required"); req != nil {
+			for _, r := range req.Content {
+				s.Required[r.Value] = true
+			}
+		}
+		props := Lookup(n, "properties")
+		if props == nil {
+			return nil, fmt.Errorf("%s: properties not found", name)
+		}
+		for j := 0; j+1 < len(props.Content); j += 2 {
+			pn := props.Content[j+1]
+			s.Properties = append(s.Properties, &Property{Name: props.Content[j].Value, Node: pn, Type: Scalar(pn, "type")})
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+// Lookup returns the value node of the key in the mapping node, or nil when not found
+func Lookup(n *yaml.Node, key string) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// Scalar returns the scalar value of the key in the mapping node, or empty when not found
+func Scalar(n *yaml.Node, key string) string {
+	if v := Lookup(n, key); v != nil && v.Kind == yaml.ScalarNode {
+		return v.Value
+	}
+	return ""
+}
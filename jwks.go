@@ -0,0 +1,209 @@
+package goline
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// See https://developers.line.biz/ja/docs/line-login/verify-id-token/#signature
+	urlJWKS = "https://api.line.me/oauth2/v2.1/certs"
+
+	defaultJWKSTTL             = time.Hour
+	defaultJWKSRefreshCooldown = time.Minute
+	defaultJWKSStaleGrace      = 6 * time.Hour
+)
+
+// ErrUnknownKeyID is returned when the key of "kid" in the ID token is not found in JWKS of LINE
+var ErrUnknownKeyID = errors.New("unknown key ID")
+
+// JWKSOption configures the JWKS cache of LocalVerifier
+type JWKSOption func(*jwksCache)
+
+// WithJWKSURL sets the URL of JWKS e.g. the server of golinetest.NewJWKSServer in tests. Default is LINE's.
+func WithJWKSURL(url string) JWKSOption {
+	return func(j *jwksCache) {
+		j.url = url
+	}
+}
+
+// WithJWKSTTL sets the duration to cache JWKS. Default is 1 hour.
+// The keys are refreshed in background before expiry while LocalVerifier is started.
+func WithJWKSTTL(ttl time.Duration) JWKSOption {
+	return func(j *jwksCache) {
+		j.ttl = ttl
+	}
+}
+
+// WithJWKSRefreshCooldown sets the minimum interval to refresh JWKS on unknown key IDs. Default is 1 minute.
+// It prevents tokens with random key IDs from making requests to LINE.
+func WithJWKSRefreshCooldown(d time.Duration) JWKSOption {
+	return func(j *jwksCache) {
+		j.cooldown = d
+	}
+}
+
+// WithJWKSStaleGrace sets the period to keep using the expired keys while JWKS cannot be refreshed,
+// e.g. during an outage of LINE. Default is 6 hours. 0 disables it, then the verification fails once the TTL has passed.
+func WithJWKSStaleGrace(d time.Duration) JWKSOption {
+	return func(j *jwksCache) {
+		j.staleGrace = d
+	}
+}
+
+// jwk is a public key of JWKS
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+}
+
+// jwksCache caches the public keys of JWKS. It is safe for concurrent use.
+// The keys are refreshed synchronously when expired, and on unknown key IDs at most once per cooldown.
+// When the refresh fails, the expired keys are used until the stale grace period has passed.
+// The expiry is checked by the Clock of the Client.
+type jwksCache struct {
+	client     *Client
+	url        string
+	ttl        time.Duration
+	cooldown   time.Duration
+	staleGrace time.Duration
+	flights    flightGroup
+
+	mu          sync.RWMutex
+	keys        map[string]*ecdsa.PublicKey
+	expiresAt   time.Time
+	lastRefresh time.Time
+	lastFailure time.Time
+}
+
+func newJWKSCache(client *Client, opts ...JWKSOption) *jwksCache {
+	j := &jwksCache{
+		client:     client,
+		url:        urlJWKS,
+		ttl:        defaultJWKSTTL,
+		cooldown:   defaultJWKSRefreshCooldown,
+		staleGrace: defaultJWKSStaleGrace,
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j
+}
+
+// key returns the public key of kid
+func (j *jwksCache) key(ctx context.Context, kid string) (*ecdsa.PublicKey, error) {
+	now := j.client.clock.Now()
+	j.mu.RLock()
+	k, found := j.keys[kid]
+	expiresAt := j.expiresAt
+	expired := !now.Before(expiresAt)
+	stale := found && now.Before(expiresAt.Add(j.staleGrace))
+	canRefresh := now.Sub(j.lastRefresh) >= j.cooldown
+	failedRecently := now.Sub(j.lastFailure) < j.cooldown
+	j.mu.RUnlock()
+
+	if found && !expired {
+		return k, nil
+	}
+	if !expired && !canRefresh {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKeyID, kid)
+	}
+	// Do not call LINE on every verification while it is failing
+	if stale && failedRecently {
+		return k, nil
+	}
+	if err := j.refresh(ctx); err != nil {
+		j.mu.Lock()
+		j.lastFailure = now
+		j.mu.Unlock()
+		if stale {
+			j.client.log.Warn("failed to refresh JWKS, using the expired keys", "error", err, "expiredAt", expiresAt)
+			return k, nil
+		}
+		return nil, err
+	}
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	if k, ok := j.keys[kid]; ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownKeyID, kid)
+}
+
+// refresh fetches JWKS. Concurrent calls share one request.
+func (j *jwksCache) refresh(ctx context.Context) error {
+	_, _, err := j.flights.do(ctx, "jwks", func(ctx context.Context) (interface{}, error) {
+		req, err := newRequest(http.MethodGet, j.url).build(ctx)
+		if err != nil {
+			return nil, err
+		}
+		res := struct {
+			Keys []jwk `json:"keys"`
+		}{}
+		if err := j.client.doRequestGetBody(req, &res); err != nil {
+			return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+		}
+
+		keys := make(map[string]*ecdsa.PublicKey, len(res.Keys))
+		for _, k := range res.Keys {
+			pk, err := k.publicKey()
+			if err != nil {
+				// Skip the keys of unsupported types
+				continue
+			}
+			keys[k.Kid] = pk
+		}
+
+		now := j.client.clock.Now()
+		j.mu.Lock()
+		j.keys = keys
+		j.expiresAt = now.Add(j.ttl)
+		j.lastRefresh = now
+		j.mu.Unlock()
+		return nil, nil
+	})
+	return err
+}
+
+// refreshIn returns the duration to refresh in background, before expiry by 10% of TTL
+func (j *jwksCache) refreshIn() time.Duration {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.expiresAt.Add(-j.ttl / 10).Sub(j.client.clock.Now())
+}
+
+func (k *jwk) publicKey() (*ecdsa.PublicKey, error) {
+	if k.Kty != "EC" || k.Crv != "P-256" {
+		return nil, fmt.Errorf("unsupported key type %s %s", k.Kty, k.Crv)
+	}
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return nil, err
+	}
+	if len(x) != 32 || len(y) != 32 {
+		return nil, errors.New("invalid key size")
+	}
+	// Check the point is on the curve
+	if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+}
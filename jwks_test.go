@@ -0,0 +1,124 @@
+package goline_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jlandowner/goline"
+	"github.com/jlandowner/goline/golinetest"
+)
+
+// fakeClock is a goline.Clock advanced by the tests
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestJWKSStaleGrace(t *testing.T) {
+	key, err := golinetest.NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := golinetest.SignIDToken(golinetest.Claims(channelID, "U1", 24*time.Hour), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var failing atomic.Bool
+	var requests atomic.Int32
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(golinetest.NewJWKS(key))
+	}))
+	t.Cleanup(jwks.Close)
+
+	clock := &fakeClock{now: time.Now()}
+	c := goline.NewClient(channelID, nil, goline.WithClock(clock))
+	lv := goline.NewLocalVerifier(c, goline.WithJWKSURL(jwks.URL),
+		goline.WithJWKSTTL(time.Hour), goline.WithJWKSRefreshCooldown(time.Minute), goline.WithJWKSStaleGrace(2*time.Hour))
+	ctx := context.Background()
+
+	verify := func() error {
+		_, err := lv.Verify(ctx, token, nil)
+		return err
+	}
+	if err := verify(); err != nil {
+		t.Fatal(err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("%d requests, want 1", n)
+	}
+
+	// Cached by the clock of the Client, not the system clock
+	clock.Advance(30 * time.Minute)
+	if err := verify(); err != nil || requests.Load() != 1 {
+		t.Fatalf("Verify() = %v after %d requests, want the cached keys", err, requests.Load())
+	}
+
+	// Expired and LINE is failing: the expired keys are used
+	failing.Store(true)
+	clock.Advance(time.Hour)
+	if err := verify(); err != nil {
+		t.Errorf("Verify() in stale grace period error = %v", err)
+	}
+	// Not refreshed again within the cooldown
+	n := requests.Load()
+	if err := verify(); err != nil || requests.Load() != n {
+		t.Errorf("Verify() = %v, %d requests, want no refresh within the cooldown", err, requests.Load()-n)
+	}
+
+	// After the stale grace period
+	clock.Advance(2 * time.Hour)
+	if err := verify(); err == nil {
+		t.Error("Verify() after stale grace period, want error")
+	}
+
+	// Recovered
+	failing.Store(false)
+	if err := verify(); err != nil {
+		t.Errorf("Verify() after recovery error = %v", err)
+	}
+}
+
+func TestJWKSStaleGraceDisabled(t *testing.T) {
+	key, _ := golinetest.NewKey()
+	token, _ := golinetest.SignIDToken(golinetest.Claims(channelID, "U1", 24*time.Hour), key)
+	jwks := golinetest.NewJWKSServer(key)
+	t.Cleanup(jwks.Close)
+
+	clock := &fakeClock{now: time.Now()}
+	c := goline.NewClient(channelID, nil, goline.WithClock(clock))
+	lv := goline.NewLocalVerifier(c, goline.WithJWKSURL(jwks.URL), goline.WithJWKSTTL(time.Hour), goline.WithJWKSStaleGrace(0))
+	if _, err := lv.Verify(context.Background(), token, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	jwks.Close()
+	clock.Advance(time.Hour)
+	if _, err := lv.Verify(context.Background(), token, nil); err == nil {
+		t.Error("Verify() with expired keys, want error")
+	}
+}
@@ -0,0 +1,154 @@
+// Package lambdaauthz is an adapter of AWS API Gateway Lambda authorizer backed by goline.Authorizer.
+//
+//	lambda.Start(lambdaauthz.NewAuthorizer(lineAuth).HandleToken)
+//
+// The LINE user is returned in the authorizer context and available in the backend
+// e.g. $context.authorizer.userId for REST API or $context.authorizer.lambda.userId for HTTP API.
+//
+// The policies of REST API authorizers allow all the methods of the stage, because API Gateway caches the policy
+// by the token and reuses it for the other methods while the authorizer caching is enabled.
+// https://docs.aws.amazon.com/apigateway/latest/developerguide/apigateway-use-lambda-authorizer.html
+package lambdaauthz
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/jlandowner/goline"
+)
+
+var (
+	// ErrUnauthorized is returned to API Gateway to respond 401 Unauthorized
+	ErrUnauthorized = errors.New("Unauthorized")
+)
+
+// Authorizer handles API Gateway authorizer events
+type Authorizer struct {
+	authorizer   *goline.Authorizer
+	authenticate func(ctx context.Context, token string) (*goline.User, error)
+}
+
+// Option configures Authorizer
+type Option func(*Authorizer)
+
+// WithAccessToken verifies access tokens instead of ID tokens
+func WithAccessToken() Option {
+	return func(l *Authorizer) {
+		l.authenticate = l.authorizer.AuthenticateAccessToken
+	}
+}
+
+// NewAuthorizer returns new Authorizer. ID tokens are verified by default.
+func NewAuthorizer(a *goline.Authorizer, opts ...Option) *Authorizer {
+	l := &Authorizer{authorizer: a, authenticate: a.AuthenticateIDToken}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// HandleToken handles the event of REST API TOKEN authorizer.
+// It returns the IAM policy allowing the methods of the stage, or ErrUnauthorized.
+func (l *Authorizer) HandleToken(ctx context.Context, e events.APIGatewayCustomAuthorizerRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+	u, err := l.verify(ctx, e.AuthorizationToken)
+	if err != nil {
+		return events.APIGatewayCustomAuthorizerResponse{}, err
+	}
+	return allowPolicy(u, e.MethodArn), nil
+}
+
+// HandleRequest handles the event of REST API REQUEST authorizer with authorization header as identity source.
+// It returns the IAM policy allowing the methods of the stage, or ErrUnauthorized.
+func (l *Authorizer) HandleRequest(ctx context.Context, e events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+	u, err := l.verify(ctx, header(e.Headers, "authorization"))
+	if err != nil {
+		return events.APIGatewayCustomAuthorizerResponse{}, err
+	}
+	return allowPolicy(u, e.MethodArn), nil
+}
+
+// HandleHTTP handles the event of HTTP API authorizer with payload format version 2.0 and simple responses enabled
+func (l *Authorizer) HandleHTTP(ctx context.Context, e events.APIGatewayV2CustomAuthorizerV2Request) (events.APIGatewayV2CustomAuthorizerSimpleResponse, error) {
+	u, err := l.verify(ctx, header(e.Headers, "authorization"))
+	if err != nil {
+		return events.APIGatewayV2CustomAuthorizerSimpleResponse{IsAuthorized: false}, nil
+	}
+	return events.APIGatewayV2CustomAuthorizerSimpleResponse{
+		IsAuthorized: true,
+		Context:      userContext(u),
+	}, nil
+}
+
+func (l *Authorizer) verify(ctx context.Context, authHeader string) (*goline.User, error) {
+	if authHeader == "" {
+		return nil, ErrUnauthorized
+	}
+	token, err := goline.ParseBearerToken(authHeader)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	u, err := l.authenticate(ctx, token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	return u, nil
+}
+
+// header returns the header value by case-insensitive key
+func header(headers map[string]string, key string) string {
+	if v, ok := headers[key]; ok {
+		return v
+	}
+	for k, v := range headers {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
+
+func allowPolicy(u *goline.User, methodArn string) events.APIGatewayCustomAuthorizerResponse {
+	return events.APIGatewayCustomAuthorizerResponse{
+		PrincipalID: u.ID,
+		PolicyDocument: events.APIGatewayCustomAuthorizerPolicy{
+			Version: "2012-10-17",
+			Statement: []events.IAMPolicyStatement{
+				{
+					Action:   []string{"execute-api:Invoke"},
+					Effect:   "Allow",
+					Resource: []string{stageArn(methodArn)},
+				},
+			},
+		},
+		Context: userContext(u),
+	}
+}
+
+// stageArn returns the ARN of all the methods of the stage from the method ARN,
+// e.g. arn:aws:execute-api:region:account:apiId/stage/*/* from arn:aws:execute-api:region:account:apiId/stage/GET/path.
+// The method ARN is returned as is when it is not in the format.
+func stageArn(methodArn string) string {
+	i := strings.LastIndex(methodArn, ":")
+	if i < 0 {
+		return methodArn
+	}
+	parts := strings.SplitN(methodArn[i+1:], "/", 3)
+	if len(parts) < 3 || parts[0] == "" || parts[1] == "" {
+		return methodArn
+	}
+	return methodArn[:i+1] + parts[0] + "/" + parts[1] + "/*/*"
+}
+
+// userContext returns the authorizer context. Only primitive values are allowed in it.
+func userContext(u *goline.User) map[string]interface{} {
+	return map[string]interface{}{
+		"userId":        u.ID,
+		"displayName":   u.DisplayName,
+		"pictureUrl":    u.PictureURL,
+		"email":         u.Email,
+		"statusMessage": u.StatusMessage,
+	}
+}
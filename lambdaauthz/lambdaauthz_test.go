@@ -0,0 +1,41 @@
+package lambdaauthz
+
+import (
+	"testing"
+
+	"github.com/jlandowner/goline"
+)
+
+func TestStageArn(t *testing.T) {
+	tests := []struct {
+		methodArn string
+		want      string
+	}{
+		{
+			methodArn: "arn:aws:execute-api:ap-northeast-1:123456789012:abcdef123/prod/GET/users/me",
+			want:      "arn:aws:execute-api:ap-northeast-1:123456789012:abcdef123/prod/*/*",
+		},
+		{
+			methodArn: "arn:aws:execute-api:ap-northeast-1:123456789012:abcdef123/prod/POST/",
+			want:      "arn:aws:execute-api:ap-northeast-1:123456789012:abcdef123/prod/*/*",
+		},
+		{methodArn: "arn:aws:execute-api:ap-northeast-1:123456789012:abcdef123", want: "arn:aws:execute-api:ap-northeast-1:123456789012:abcdef123"},
+		{methodArn: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := stageArn(tt.methodArn); got != tt.want {
+			t.Errorf("stageArn(%q) = %q, want %q", tt.methodArn, got, tt.want)
+		}
+	}
+}
+
+func TestAllowPolicy(t *testing.T) {
+	p := allowPolicy(&goline.User{ID: "U1"}, "arn:aws:execute-api:ap-northeast-1:123456789012:abcdef123/prod/GET/users/me")
+	if p.PrincipalID != "U1" || p.Context["userId"] != "U1" {
+		t.Errorf("principal = %s, context = %v", p.PrincipalID, p.Context)
+	}
+	want := "arn:aws:execute-api:ap-northeast-1:123456789012:abcdef123/prod/*/*"
+	if r := p.PolicyDocument.Statement[0].Resource; len(r) != 1 || r[0] != want {
+		t.Errorf("resource = %v, want %s", r, want)
+	}
+}
@@ -0,0 +1,97 @@
+package goline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// The optional claims of LIFF ID tokens, present only when the LIFF app has the scope and the user consented to it.
+// https://developers.line.biz/ja/reference/liff/#get-decoded-id-token
+const (
+	// LIFFClaimName is the display name of the user, with "profile" scope
+	LIFFClaimName = "name"
+	// LIFFClaimPicture is the profile image URL of the user, with "profile" scope
+	LIFFClaimPicture = "picture"
+	// LIFFClaimEmail is the email address of the user, with "email" scope
+	LIFFClaimEmail = "email"
+)
+
+// ErrLIFFClaimMissing is returned when the ID token has no claim required by WithRequiredLIFFClaims
+var ErrLIFFClaimMissing = errors.New("LIFF claim missing")
+
+// WithRequiredLIFFClaims rejects the ID tokens without the optional LIFF claims in VerifyLIFFIDTokenMiddleware
+// and AuthenticateLIFFIDToken, e.g. LIFFClaimEmail when the app cannot work without the email of the user.
+// The rejection is AuthFailureValidationFailed. By default, the missing optional claims are empty fields of User.
+func WithRequiredLIFFClaims(names ...string) AuthorizerOption {
+	return func(a *Authorizer) {
+		a.liffClaims = append(a.liffClaims, names...)
+	}
+}
+
+// LIFFChannelID returns the channel ID of LINE Login channel which the LIFF app belongs to.
+// LIFF ID is formatted as "{channel ID}-{random string}" like "1234567890-AbcdEfgh".
+func LIFFChannelID(liffID string) (string, error) {
+	arr := strings.SplitN(liffID, "-", 2)
+	if len(arr) != 2 || arr[0] == "" || arr[1] == "" {
+		return "", fmt.Errorf("invalid LIFF ID: %s", liffID)
+	}
+	return arr[0], nil
+}
+
+// NewLIFFClient returns Client preconfigured to verify ID tokens of the LIFF app.
+// The ID token is verified with aud = the channel ID of the LIFF app.
+func NewLIFFClient(liffID string, client *http.Client) (*Client, error) {
+	channelID, err := LIFFChannelID(liffID)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(channelID, client), nil
+}
+
+// NewLIFFAuthorizer returns Authorizer preconfigured to verify ID tokens of the LIFF app.
+// Use it with VerifyLIFFIDTokenMiddleware.
+func NewLIFFAuthorizer(liffID string, client *http.Client, log *slog.Logger, opts ...AuthorizerOption) (*Authorizer, error) {
+	lineClient, err := NewLIFFClient(liffID, client)
+	if err != nil {
+		return nil, err
+	}
+	return NewAuthorizer(lineClient, log, opts...), nil
+}
+
+// AuthenticateLIFFIDToken verifies the ID token of the LIFF app same as AuthenticateIDToken,
+// and checks the optional LIFF claims required by WithRequiredLIFFClaims.
+func (a *Authorizer) AuthenticateLIFFIDToken(ctx context.Context, idToken string) (*User, error) {
+	u, err := a.AuthenticateIDToken(ctx, idToken)
+	if err != nil {
+		return nil, err
+	}
+	var errs ValidationErrors
+	for _, name := range a.liffClaims {
+		if v, ok := u.Claims[name]; !ok || v == "" || v == nil {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrLIFFClaimMissing, name))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return u, nil
+}
+
+// VerifyLIFFIDTokenMiddleware is a middleware of http handler for the backend of LIFF apps.
+// LIFF apps obtain the ID token by liff.getIDToken() and send it in authorization header.
+//
+//	await liff.init({ liffId: "1234567890-AbcdEfgh" });
+//	const idToken = liff.getIDToken();
+//	await fetch("/api", { headers: { Authorization: `Bearer ${idToken}` } });
+//
+// Requesting "openid" scope in the LIFF app settings is required to get ID token, "profile" scope to get the name
+// and the picture, and "email" scope to get email. The tokens without the claims required by WithRequiredLIFFClaims
+// are rejected. The authorized LINE user info is set in request headers same as VerifyIDTokenMiddleware.
+// https://developers.line.biz/ja/reference/liff/#get-id-token
+func (a *Authorizer) VerifyLIFFIDTokenMiddleware(next http.Handler) http.Handler {
+	return a.middleware("VerifyLIFFIDTokenMiddleware", a.AuthenticateLIFFIDToken, next)
+}
@@ -0,0 +1,205 @@
+// Package liff is a client of LIFF server API to manage LIFF apps under a LINE Login channel.
+package liff
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/jlandowner/goline"
+)
+
+const (
+	// See https://developers.line.biz/ja/reference/liff-server/#add-liff-app
+	urlApps = "https://api.line.me/liff/v1/apps"
+)
+
+// ViewType is the size of LIFF app view
+type ViewType string
+
+const (
+	ViewTypeCompact ViewType = "compact"
+	ViewTypeTall    ViewType = "tall"
+	ViewTypeFull    ViewType = "full"
+)
+
+// BotPrompt is the setting of add friend option
+type BotPrompt string
+
+const (
+	BotPromptNormal     BotPrompt = "normal"
+	BotPromptAggressive BotPrompt = "aggressive"
+	BotPromptNone       BotPrompt = "none"
+)
+
+// App is a LIFF app object.
+// Pointer fields can be nil to keep the current value in UpdateApp.
+// https://developers.line.biz/ja/reference/liff-server/#add-liff-app
+type App struct {
+	LiffID               string    `json:"liffId,omitempty"`
+	View                 *View     `json:"view,omitempty"`
+	Description          string    `json:"description,omitempty"`
+	Features             *Features `json:"features,omitempty"`
+	PermanentLinkPattern string    `json:"permanentLinkPattern,omitempty"`
+	Scope                []string  `json:"scope,omitempty"`
+	BotPrompt            BotPrompt `json:"botPrompt,omitempty"`
+}
+
+// View is the view setting of LIFF app
+type View struct {
+	Type       ViewType `json:"type"`
+	URL        string   `json:"url"`
+	ModuleMode bool     `json:"moduleMode,omitempty"`
+}
+
+// Features is the features setting of LIFF app
+type Features struct {
+	BLE    bool `json:"ble,omitempty"`
+	QRCode bool `json:"qrCode,omitempty"`
+}
+
+// Client is an http client access to LIFF server API
+type Client struct {
+	channelAccessToken string
+	client             *http.Client
+}
+
+// NewClient returns LIFF server API Client. "channelAccessToken" is the channel access token of LINE Login channel.
+func NewClient(channelAccessToken string, client *http.Client) *Client {
+	return &Client{
+		channelAccessToken: channelAccessToken,
+		client:             client,
+	}
+}
+
+// AddApp is a function to call add-liff-app API. It returns the LIFF ID of the added app.
+// https://developers.line.biz/ja/reference/liff-server/#add-liff-app
+func (c *Client) AddApp(ctx context.Context, app *App) (string, error) {
+	// Check paramaters
+	if app == nil || app.View == nil {
+		return "", errors.New("app view not found")
+	}
+
+	// Prepare http request
+	req, err := newJSONRequest(ctx, http.MethodPost, urlApps, app)
+	if err != nil {
+		return "", err
+	}
+
+	// Do http request and get response body
+	res := struct {
+		LiffID string `json:"liffId"`
+	}{}
+	if err := c.doRequestGetBody(req, &res); err != nil {
+		return "", err
+	}
+	return res.LiffID, nil
+}
+
+// UpdateApp is a function to call update-liff-app API. Only the set fields are updated.
+// https://developers.line.biz/ja/reference/liff-server/#update-liff-app
+func (c *Client) UpdateApp(ctx context.Context, liffID string, app *App) error {
+	// Check paramaters
+	if liffID == "" {
+		return errors.New("liff ID not found")
+	}
+	if app == nil {
+		return errors.New("app is nil")
+	}
+
+	// Prepare http request
+	req, err := newJSONRequest(ctx, http.MethodPut, urlApps+"/"+url.PathEscape(liffID), app)
+	if err != nil {
+		return err
+	}
+
+	// Do http request
+	return c.doRequestGetBody(req, nil)
+}
+
+// GetApps is a function to call get-all-liff-apps API.
+// It returns empty list when no LIFF app is added to the channel.
+// https://developers.line.biz/ja/reference/liff-server/#get-all-liff-apps
+func (c *Client) GetApps(ctx context.Context) ([]*App, error) {
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlApps, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	res := struct {
+		Apps []*App `json:"apps"`
+	}{}
+	if err := c.doRequestGetBody(req, &res); err != nil {
+		if errors.Is(err, errNotFound) {
+			return []*App{}, nil
+		}
+		return nil, err
+	}
+	return res.Apps, nil
+}
+
+// DeleteApp is a function to call delete-liff-app API
+// https://developers.line.biz/ja/reference/liff-server/#delete-liff-app
+func (c *Client) DeleteApp(ctx context.Context, liffID string) error {
+	// Check paramaters
+	if liffID == "" {
+		return errors.New("liff ID not found")
+	}
+
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, urlApps+"/"+url.PathEscape(liffID), nil)
+	if err != nil {
+		return err
+	}
+
+	// Do http request
+	return c.doRequestGetBody(req, nil)
+}
+
+// get-all-liff-apps API returns 404 when there is no LIFF app
+var errNotFound = errors.New("404 Not Found")
+
+func newJSONRequest(ctx context.Context, method, url string, body interface{}) (*http.Request, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+func (c *Client) doRequestGetBody(req *http.Request, resbody interface{}) error {
+	if req == nil {
+		return errors.New("request is nil")
+	}
+	req.Header.Set("Authorization", "Bearer "+c.channelAccessToken)
+
+	// Do http request
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	// Check Status Code
+	if res.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if err := goline.CheckResponse(res); err != nil {
+		return err
+	}
+
+	if resbody == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(resbody)
+}
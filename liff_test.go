@@ -0,0 +1,80 @@
+package goline
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLIFFChannelID(t *testing.T) {
+	if id, err := LIFFChannelID("1234567890-AbcdEfgh"); err != nil || id != "1234567890" {
+		t.Errorf("LIFFChannelID() = %s, %v", id, err)
+	}
+	for _, liffID := range []string{"", "1234567890", "-AbcdEfgh", "1234567890-"} {
+		if _, err := LIFFChannelID(liffID); err == nil {
+			t.Errorf("LIFFChannelID(%q), want error", liffID)
+		}
+	}
+}
+
+func TestVerifyLIFFIDTokenMiddleware(t *testing.T) {
+	// The ID token "no-email" is of the user who did not consent to "email" scope
+	_, hc := newTestLINE(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := `{"iss":"https://access.line.me","sub":"U1","aud":"1234567890","exp":4102444800,"iat":1700000000,"name":"Taro","picture":"https://example.com/p","email":"taro@example.com"}`
+		if r.PostFormValue("id_token") == "no-email" {
+			body = `{"iss":"https://access.line.me","sub":"U1","aud":"1234567890","exp":4102444800,"iat":1700000000,"name":"Taro"}`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, body)
+	}))
+
+	tests := []struct {
+		name     string
+		required []string
+		token    string
+		want     int
+	}{
+		{name: "optional", token: "no-email", want: http.StatusOK},
+		{name: "required", required: []string{LIFFClaimEmail}, token: "with-email", want: http.StatusOK},
+		{name: "required missing", required: []string{LIFFClaimEmail}, token: "no-email", want: http.StatusUnauthorized},
+		{name: "required missing picture", required: []string{LIFFClaimName, LIFFClaimPicture}, token: "no-email", want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewLIFFAuthorizer("1234567890-AbcdEfgh", hc, nil, WithRequiredLIFFClaims(tt.required...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var user *User
+			h := a.VerifyLIFFIDTokenMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				user, _ = UserFromContext(r.Context())
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/api", nil)
+			r.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if tt.want == http.StatusOK && (user == nil || user.ID != "U1") {
+				t.Errorf("user = %+v", user)
+			}
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		a, _ := NewLIFFAuthorizer("1234567890-AbcdEfgh", hc, nil, WithRequiredLIFFClaims(LIFFClaimEmail))
+		_, err := a.AuthenticateLIFFIDToken(context.Background(), "no-email")
+		if !errors.Is(err, ErrLIFFClaimMissing) || !errors.Is(err, ErrValidationFailed) {
+			t.Errorf("AuthenticateLIFFIDToken() error = %v, want ErrLIFFClaimMissing and ErrValidationFailed", err)
+		}
+		// The other middlewares do not require the LIFF claims
+		if _, err := a.AuthenticateIDToken(context.Background(), "no-email"); err != nil {
+			t.Errorf("AuthenticateIDToken() error = %v", err)
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package goline_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// lineTransport sends the requests to LINE to the test server and the others as is
+type lineTransport struct {
+	host string
+	base http.RoundTripper
+}
+
+func (t *lineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(req.URL.Host, "line.me") {
+		req = req.Clone(req.Context())
+		req.URL.Scheme = "http"
+		req.URL.Host = t.host
+	}
+	return t.base.RoundTrip(req)
+}
+
+// newLINEClient starts the test server of the handler and returns http.Client sending the requests to LINE to it
+func newLINEClient(t *testing.T, h http.Handler) *http.Client {
+	t.Helper()
+	s := httptest.NewServer(h)
+	t.Cleanup(s.Close)
+	return &http.Client{Transport: &lineTransport{host: strings.TrimPrefix(s.URL, "http://"), base: http.DefaultTransport}}
+}
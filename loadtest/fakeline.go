@@ -0,0 +1,92 @@
+package loadtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// fakeLINE is a fake LINE server responding the verify and the profile APIs for any token
+type fakeLINE struct {
+	*httptest.Server
+	latency time.Duration
+	n       atomic.Int64
+}
+
+func newFakeLINE(latency time.Duration) *fakeLINE {
+	f := &fakeLINE{latency: latency}
+	f.Server = httptest.NewServer(f)
+	return f
+}
+
+func (f *fakeLINE) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.n.Add(1)
+	if f.latency > 0 {
+		time.Sleep(f.latency)
+	}
+
+	var res interface{}
+	switch {
+	case r.URL.Path == "/oauth2/v2.1/verify" && r.Method == http.MethodPost:
+		now := time.Now()
+		res = map[string]interface{}{
+			"iss":   "https://access.line.me",
+			"sub":   userID(r.PostFormValue("id_token")),
+			"aud":   r.PostFormValue("client_id"),
+			"exp":   now.Add(time.Hour).Unix(),
+			"iat":   now.Unix(),
+			"nonce": r.PostFormValue("nonce"),
+			"name":  "loadtest",
+		}
+	case r.URL.Path == "/oauth2/v2.1/verify":
+		res = map[string]interface{}{
+			"scope":      "profile",
+			"client_id":  channelID,
+			"expires_in": 3600,
+		}
+	case r.URL.Path == "/v2/profile":
+		res = map[string]interface{}{
+			"userId":      userID(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")),
+			"displayName": "loadtest",
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// calls returns the number of the requests to the server
+func (f *fakeLINE) calls() int64 {
+	return f.n.Load()
+}
+
+// transport returns http.RoundTripper sending the requests to LINE to the server
+// keeping up to maxIdle connections not to exhaust the ports under load
+func (f *fakeLINE) transport(maxIdle int) http.RoundTripper {
+	base := f.Client().Transport.(*http.Transport).Clone()
+	base.MaxIdleConns = maxIdle
+	base.MaxIdleConnsPerHost = maxIdle
+	return &rewriteTransport{host: strings.TrimPrefix(f.URL, "http://"), base: base}
+}
+
+type rewriteTransport struct {
+	host string
+	base http.RoundTripper
+}
+
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = "http"
+	req.URL.Host = t.host
+	return t.base.RoundTrip(req)
+}
+
+// userID returns the fake user ID of the token
+func userID(token string) string {
+	return "U" + token
+}
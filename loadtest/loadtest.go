@@ -0,0 +1,195 @@
+// Package loadtest drives the middleware of goline.Authorizer against a fake LINE server
+// at the configured rate, and reports the latency percentiles and the allocations per request,
+// for the capacity planning of the auth layer and catching performance regressions.
+//
+//	res, err := loadtest.Run(ctx, loadtest.Config{RPS: 5000, Duration: 10 * time.Second, CacheTTL: time.Minute})
+//	fmt.Println(res)
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jlandowner/goline"
+)
+
+const channelID = "1234567890"
+
+// Config is the configuration of the load test
+type Config struct {
+	// RPS is the rate of the requests per second
+	RPS int
+	// Duration is the duration to send the requests
+	Duration time.Duration
+	// Concurrency is the number of the workers sending the requests. Default is 64.
+	Concurrency int
+	// Tokens is the number of the distinct tokens sent in rotation. Default is 1000.
+	Tokens int
+	// CacheTTL enables the verification cache of the Client when positive
+	CacheTTL time.Duration
+	// UpstreamLatency is the latency of the fake LINE server
+	UpstreamLatency time.Duration
+	// AccessToken verifies access tokens instead of ID tokens
+	AccessToken bool
+}
+
+// Result is the result of the load test
+type Result struct {
+	// Requests is the number of the completed requests
+	Requests int
+	// Failures is the number of the responses other than 200 OK
+	Failures int
+	// Dropped is the number of the requests not sent as all the workers were busy
+	Dropped  int
+	Elapsed  time.Duration
+	P50      time.Duration
+	P95      time.Duration
+	P99      time.Duration
+	Max      time.Duration
+	Upstream int64
+	// AllocsPerRequest and BytesPerRequest are the heap allocations per request including the fake LINE server
+	AllocsPerRequest float64
+	BytesPerRequest  float64
+}
+
+// String returns the human readable report
+func (r *Result) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "requests: %d (%.0f/s), failures: %d, dropped: %d, upstream calls: %d\n",
+		r.Requests, float64(r.Requests)/r.Elapsed.Seconds(), r.Failures, r.Dropped, r.Upstream)
+	fmt.Fprintf(&b, "latency: p50=%s p95=%s p99=%s max=%s\n", r.P50, r.P95, r.P99, r.Max)
+	fmt.Fprintf(&b, "allocs: %.1f allocs/req, %.0f B/req", r.AllocsPerRequest, r.BytesPerRequest)
+	return b.String()
+}
+
+// Run runs the load test until the duration passes or ctx is done
+func Run(ctx context.Context, cfg Config) (*Result, error) {
+	if cfg.RPS <= 0 || cfg.Duration <= 0 {
+		return nil, errors.New("RPS and duration must be positive")
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 64
+	}
+	if cfg.Tokens <= 0 {
+		cfg.Tokens = 1000
+	}
+
+	line := newFakeLINE(cfg.UpstreamLatency)
+	defer line.Close()
+
+	var opts []goline.ClientOption
+	if cfg.CacheTTL > 0 {
+		opts = append(opts, goline.WithCache(cfg.CacheTTL, cfg.Tokens))
+	}
+	client := goline.NewClient(channelID, &http.Client{Transport: line.transport(cfg.Concurrency)}, opts...)
+	a := goline.NewAuthorizer(client, nil)
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := a.VerifyIDTokenMiddleware(ok)
+	if cfg.AccessToken {
+		handler = a.VerifyAccessTokenMiddleware(ok)
+	}
+
+	tokens := make([]string, cfg.Tokens)
+	for i := range tokens {
+		tokens[i] = "Bearer token-" + strconv.Itoa(i)
+	}
+
+	var (
+		mu        sync.Mutex
+		latencies = make([]time.Duration, 0, cfg.RPS*int(cfg.Duration/time.Second+1))
+		failures  int
+		wg        sync.WaitGroup
+	)
+	jobs := make(chan string, cfg.Concurrency)
+	for i := 0; i < cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for token := range jobs {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set("Authorization", token)
+				w := httptest.NewRecorder()
+				start := time.Now()
+				handler.ServeHTTP(w, req)
+				d := time.Since(start)
+
+				mu.Lock()
+				latencies = append(latencies, d)
+				if w.Code != http.StatusOK {
+					failures++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	dropped := 0
+	total := int(cfg.Duration.Seconds() * float64(cfg.RPS))
+	interval := time.Second / time.Duration(cfg.RPS)
+	start := time.Now()
+	next := start
+loop:
+	for i := 0; i < total; i++ {
+		if wait := time.Until(next); wait > 0 {
+			select {
+			case <-ctx.Done():
+				break loop
+			case <-time.After(wait):
+			}
+		}
+		select {
+		case jobs <- tokens[i%len(tokens)]:
+		default:
+			dropped++
+		}
+		next = next.Add(interval)
+	}
+	close(jobs)
+	wg.Wait()
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	res := &Result{
+		Requests: len(latencies),
+		Failures: failures,
+		Dropped:  dropped,
+		Elapsed:  elapsed,
+		Upstream: line.calls(),
+	}
+	if res.Requests == 0 {
+		return res, nil
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	res.P50 = percentile(latencies, 0.50)
+	res.P95 = percentile(latencies, 0.95)
+	res.P99 = percentile(latencies, 0.99)
+	res.Max = latencies[len(latencies)-1]
+	res.AllocsPerRequest = float64(after.Mallocs-before.Mallocs) / float64(res.Requests)
+	res.BytesPerRequest = float64(after.TotalAlloc-before.TotalAlloc) / float64(res.Requests)
+	return res, nil
+}
+
+// percentile returns the percentile of the sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(float64(len(sorted))*p+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
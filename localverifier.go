@@ -0,0 +1,180 @@
+package goline
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidSignature is returned by LocalVerifier when the signature of the ID token is invalid
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// LocalVerifier verifies ID tokens locally by the signature and the claims without calling verify-id-token API.
+// ES256 tokens are verified by the public keys of LINE JWKS, and HS256 tokens by the channel secret of the Client.
+// It is safe for concurrent use.
+//
+// The JWKS is fetched on the first verification and cached. Start it as a Component to refresh the keys in background
+// before the cache expires, so that verifications do not wait for LINE when the keys are refreshed or rotated.
+type LocalVerifier struct {
+	client    *Client
+	jwks      *jwksCache
+	clockSkew time.Duration
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewLocalVerifier returns new LocalVerifier of the channel of the client.
+// The JWKS is fetched by the http client of the client.
+func NewLocalVerifier(client *Client, opts ...JWKSOption) *LocalVerifier {
+	return &LocalVerifier{
+		client:    client,
+		jwks:      newJWKSCache(client, opts...),
+		clockSkew: defaultClockSkew,
+	}
+}
+
+// Start fetches JWKS and starts refreshing it in background. It returns error when already started.
+// The failure of the first fetch does not fail Start, as the keys are fetched again on verification.
+func (v *LocalVerifier) Start(ctx context.Context) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.cancel != nil {
+		return errors.New("local verifier already started")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	v.cancel = cancel
+	v.done = make(chan struct{})
+	go v.run(ctx, v.done)
+	return nil
+}
+
+// Stop stops refreshing JWKS in background
+func (v *LocalVerifier) Stop() {
+	v.mu.Lock()
+	cancel, done := v.cancel, v.done
+	v.cancel, v.done = nil, nil
+	v.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+func (v *LocalVerifier) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	for {
+		wait := v.jwks.cooldown
+		if err := v.jwks.refresh(ctx); err == nil {
+			wait = v.jwks.refreshIn()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Verify verifies the signature, "iss", "aud", "exp", and "sub" and "nonce" of the options when not empty, and returns the claims.
+// "aud" is checked against the channel ID of the Client unless overridden by the options.
+// ErrTokenExpired is returned when the token is expired.
+func (v *LocalVerifier) Verify(ctx context.Context, idToken string, opts *VerifyIDTokenOptions) (*IDTokenData, error) {
+	o := v.client.idTokenOptions(opts)
+
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("token is not JWT")
+	}
+	header := struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}{}
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode JWT signature: %w", err)
+	}
+	if err := v.verifySignature(ctx, header.Alg, header.Kid, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	d := &IDTokenData{}
+	if err := decodeJWTSegment(parts[1], d); err != nil {
+		return nil, err
+	}
+	if d.Iss != idTokenIssuer {
+		return nil, fmt.Errorf("iss does not match: got %s want %s", d.Iss, idTokenIssuer)
+	}
+	if d.Aud != o.ChannelID {
+		return nil, fmt.Errorf("%w: got %s want %s", errAudienceMismatch, d.Aud, o.ChannelID)
+	}
+	if !v.client.clock.Now().Before(time.Unix(d.Exp, 0).Add(v.clockSkew)) {
+		return nil, ErrTokenExpired
+	}
+	if o.UserID != "" && d.Sub != o.UserID {
+		return nil, fmt.Errorf("sub does not match: got %s want %s", d.Sub, o.UserID)
+	}
+	if o.Nonce != "" && !equalSecret(d.Nonce, o.Nonce) {
+		return nil, errors.New("nonce does not match")
+	}
+	return d, nil
+}
+
+func (v *LocalVerifier) verifySignature(ctx context.Context, alg, kid, signingInput string, sig []byte) error {
+	digest := sha256.Sum256([]byte(signingInput))
+	switch alg {
+	case "ES256":
+		pk, err := v.jwks.key(ctx, kid)
+		if err != nil {
+			return err
+		}
+		if len(sig) != 64 ||
+			!ecdsa.Verify(pk, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])) {
+			return ErrInvalidSignature
+		}
+		return nil
+	case "HS256":
+		if v.client.clientSecret == "" {
+			return errors.New("channel secret is not set to verify HS256 token")
+		}
+		mac := hmac.New(sha256.New, []byte(v.client.clientSecret))
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return ErrInvalidSignature
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported alg %s", alg)
+	}
+}
+
+// decodeJWTSegment decodes the base64url encoded json segment of JWT into v
+func decodeJWTSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(seg, "="))
+	if err != nil {
+		return fmt.Errorf("failed to decode JWT: %w", err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("failed to parse JWT: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+// Package logadapter converts logr and zap loggers to *slog.Logger for goline.Authorizer,
+// so that the core package depends only on the standard library.
+//
+//	lineAuth := goline.NewAuthorizer(lineClient, logadapter.FromZap(zapLog))
+package logadapter
+
+import (
+	"log/slog"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/zapr"
+	"go.uber.org/zap"
+)
+
+// FromLogr returns *slog.Logger writing to the logr.Logger
+func FromLogr(log logr.Logger) *slog.Logger {
+	return slog.New(logr.ToSlogHandler(log))
+}
+
+// FromZap returns *slog.Logger writing to the zap.Logger
+func FromZap(log *zap.Logger) *slog.Logger {
+	return FromLogr(zapr.NewLogger(log))
+}
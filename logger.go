@@ -0,0 +1,81 @@
+package goline
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// WithLogger sets the logger of the Client. The requests to LINE are logged at debug level
+// with "line" and "http" groups. Logs are discarded by default.
+func WithLogger(log *slog.Logger) ClientOption {
+	return func(c *Client) {
+		c.log = log
+	}
+}
+
+// newLogger returns the logger, or the logger discarding all logs when nil
+func newLogger(log *slog.Logger) *slog.Logger {
+	if log == nil {
+		return slog.New(discardHandler{})
+	}
+	return log
+}
+
+// discardHandler is slog.Handler discarding all logs
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// lineGroup returns "line" group of the channel and the user. The user ID is omitted when empty.
+func lineGroup(channelID, userID string) slog.Attr {
+	attrs := []any{slog.String("channel_id", channelID)}
+	if userID != "" {
+		attrs = append(attrs, slog.String("user_id", userID))
+	}
+	return slog.Group("line", attrs...)
+}
+
+// userGroup returns "line" group of the user. The email and the display name are added only when allowed by RedactionPolicy.
+func (a *Authorizer) userGroup(u *User) slog.Attr {
+	p := a.lineClient.redaction
+	attrs := []any{slog.String("channel_id", a.lineClient.clientid), slog.String("user_id", u.ID)}
+	if p.AllowDisplayNames && u.DisplayName != "" {
+		attrs = append(attrs, slog.String("display_name", u.DisplayName))
+	}
+	if p.AllowEmails && u.Email != "" {
+		attrs = append(attrs, slog.String("email", u.Email))
+	}
+	return slog.Group("line", attrs...)
+}
+
+// recordDecision logs the authentication decision with "line" and "auth" groups and emits the metrics.
+// Allowed requests are logged at debug level, denied requests at warn level and the internal failures at error level.
+func (a *Authorizer) recordDecision(ctx context.Context, log *slog.Logger, u *User, f AuthFailure, err error, start time.Time) {
+	level, msg, decision := slog.LevelDebug, "authentication allowed", AuditDecisionAllow
+	auth := []any{}
+	switch {
+	case err != nil && f == "":
+		level, msg, decision = slog.LevelError, "authentication failed", AuditDecisionDeny
+	case err != nil:
+		level, msg, decision = slog.LevelWarn, "authentication denied", AuditDecisionDeny
+		auth = append(auth, slog.String("reason", string(f)))
+	}
+	latency := a.lineClient.clock.Now().Sub(start)
+	a.observeDecision(decision, f, latency)
+	auth = append([]any{slog.String("decision", string(decision))}, auth...)
+	auth = append(auth, slog.Int64("latency_ms", latency.Milliseconds()))
+
+	line := lineGroup(a.lineClient.clientid, "")
+	if u != nil {
+		line = a.userGroup(u)
+	}
+	attrs := []slog.Attr{line, slog.Group("auth", auth...)}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	log.LogAttrs(ctx, level, msg, attrs...)
+}
@@ -0,0 +1,209 @@
+package goline
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// See https://developers.line.biz/ja/docs/line-login/integrate-line-login/#making-an-authorization-request
+	urlAuthorize = "https://access.line.me/oauth2/v2.1/authorize"
+
+	defaultStateCookieName = "goline_state"
+	// Query parameter of the redirect path in the login request
+	redirectParam = "redirect"
+)
+
+// ErrLoginDenied is returned in the callback when the user canceled the login or the consent
+var ErrLoginDenied = errors.New("login denied by user")
+
+// LoginResult is the result of the successful login passed to the login hook
+type LoginResult struct {
+	Token *TokenResponse
+	// IDToken is the verified ID token. It is nil when "openid" scope is not requested.
+	IDToken *IDTokenData
+	// RedirectPath is the path to redirect after the hook returns
+	RedirectPath string
+}
+
+// LoginHook is called in the callback after the login succeeded, e.g. to start the session or to save the tokens.
+// The user is redirected to the path of the result after it returns nil. The hook can change the path.
+type LoginHook func(w http.ResponseWriter, r *http.Request, res *LoginResult) error
+
+// LoginHandler serves the login and the callback of LINE Login.
+// The originally requested path is embedded in the signed state and the user is redirected back to it after login.
+// The redirect paths are restricted by StateSigner to prevent open redirects.
+type LoginHandler struct {
+	client      *Client
+	signer      *StateSigner
+	redirectURI string
+	onLogin     LoginHook
+
+	scopes          []string
+	defaultRedirect string
+	cookie          CookieOptions
+	onError         func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// LoginOption configures LoginHandler
+type LoginOption func(*LoginHandler)
+
+// WithLoginScopes sets the scopes of the authorization request. Default is "openid" and "profile".
+func WithLoginScopes(scopes ...string) LoginOption {
+	return func(h *LoginHandler) {
+		h.scopes = scopes
+	}
+}
+
+// WithDefaultRedirectPath sets the path to redirect after login when no path is requested. Default is "/".
+func WithDefaultRedirectPath(p string) LoginOption {
+	return func(h *LoginHandler) {
+		h.defaultRedirect = p
+	}
+}
+
+// WithLoginErrorHandler sets the function responding the errors of the login and the callback.
+// By default it responds 400 Bad Request for invalid requests, 401 Unauthorized when the user denied the login,
+// or 500 Internal Server Error.
+func WithLoginErrorHandler(fn func(w http.ResponseWriter, r *http.Request, err error)) LoginOption {
+	return func(h *LoginHandler) {
+		h.onError = fn
+	}
+}
+
+// WithStateCookie sets the attributes of the login state cookie. Default is DefaultCookieOptions named "goline_state"
+// and living for the lifetime of the state. Use DefaultCookieOptions.ForDevelopment() on http://localhost.
+// SameSite=Strict is not recommended as the cookie is not sent in the redirect from LINE to the callback.
+func WithStateCookie(o CookieOptions) LoginOption {
+	return func(h *LoginHandler) {
+		h.cookie = o
+	}
+}
+
+// NewLoginHandler returns LoginHandler. redirectURI is the callback URL registered in the channel,
+// and the Client must be created with WithChannelSecret to issue the tokens.
+func NewLoginHandler(client *Client, signer *StateSigner, redirectURI string, onLogin LoginHook, opts ...LoginOption) (*LoginHandler, error) {
+	if client.clientSecret == "" {
+		return nil, errors.New("channel secret is not set")
+	}
+	if redirectURI == "" {
+		return nil, errors.New("redirect URI not found")
+	}
+	if signer == nil || onLogin == nil {
+		return nil, errors.New("state signer and login hook are required")
+	}
+	h := &LoginHandler{
+		client:          client,
+		signer:          signer,
+		redirectURI:     redirectURI,
+		onLogin:         onLogin,
+		scopes:          []string{"openid", "profile"},
+		defaultRedirect: "/",
+		cookie:          DefaultCookieOptions,
+		onError:         defaultLoginError,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if err := h.cookie.validate(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Login returns a handler redirecting to LINE Login.
+// The path to redirect back after login is taken from "redirect" query parameter e.g. "/login?redirect=/orders".
+func (h *LoginHandler) Login() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.redirectToLogin(w, r, r.URL.Query().Get(redirectParam))
+	})
+}
+
+// RedirectToLogin redirects to LINE Login capturing the requested URL to redirect back after login.
+// Call it in the handlers requiring login when the user is not logged in.
+func (h *LoginHandler) RedirectToLogin(w http.ResponseWriter, r *http.Request) {
+	h.redirectToLogin(w, r, r.URL.RequestURI())
+}
+
+func (h *LoginHandler) redirectToLogin(w http.ResponseWriter, r *http.Request, redirectPath string) {
+	state, st, err := h.signer.Sign(redirectPath)
+	if err != nil {
+		h.onError(w, r, err)
+		return
+	}
+	http.SetCookie(w, h.cookie.cookie(defaultStateCookieName, st.CSRFToken, h.signer.ttl))
+	http.Redirect(w, r, h.authorizeURL(state, st.Nonce), http.StatusFound)
+}
+
+// authorizeURL returns the URL of the authorization request
+func (h *LoginHandler) authorizeURL(state, nonce string) string {
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", h.client.clientid)
+	q.Set("redirect_uri", h.redirectURI)
+	q.Set("state", state)
+	q.Set("scope", strings.Join(h.scopes, " "))
+	q.Set("nonce", nonce)
+	return urlAuthorize + "?" + q.Encode()
+}
+
+// Callback returns a handler of the redirect URI. It verifies the state with the cookie set in the login,
+// issues the tokens by the authorization code, verifies the ID token with the nonce, calls the login hook
+// and redirects to the path embedded in the state.
+func (h *LoginHandler) Callback() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		csrf := ""
+		if c, err := r.Cookie(h.cookie.name(defaultStateCookieName)); err == nil {
+			csrf = c.Value
+		}
+		// The state cookie is used only once
+		http.SetCookie(w, h.cookie.expiredCookie(defaultStateCookieName))
+
+		st, err := h.signer.Verify(q.Get("state"), csrf)
+		if err != nil {
+			h.onError(w, r, err)
+			return
+		}
+		if q.Get("error") != "" {
+			h.onError(w, r, ErrLoginDenied)
+			return
+		}
+
+		tok, err := h.client.IssueAccessToken(r.Context(), IssueAccessTokenOptions{Code: q.Get("code"), RedirectURI: h.redirectURI})
+		if err != nil {
+			h.onError(w, r, err)
+			return
+		}
+		res := &LoginResult{Token: tok, RedirectPath: st.RedirectPath}
+		if tok.IDToken != "" {
+			if res.IDToken, err = h.client.VerifyIDToken(r.Context(), tok.IDToken, &VerifyIDTokenOptions{Nonce: st.Nonce}); err != nil {
+				h.onError(w, r, err)
+				return
+			}
+		}
+		if res.RedirectPath == "" {
+			res.RedirectPath = h.defaultRedirect
+		}
+
+		if err := h.onLogin(w, r, res); err != nil {
+			h.onError(w, r, err)
+			return
+		}
+		http.Redirect(w, r, res.RedirectPath, http.StatusFound)
+	})
+}
+
+func defaultLoginError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidState), errors.Is(err, ErrStateExpired), errors.Is(err, ErrInvalidRedirectPath),
+		errors.Is(err, ErrBadRequest):
+		w.WriteHeader(http.StatusBadRequest)
+	case errors.Is(err, ErrLoginDenied):
+		w.WriteHeader(http.StatusUnauthorized)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,52 @@
+package goline
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const defaultClockSkew = time.Minute
+
+// WithClockSkew sets the tolerance of clock skew between LINE and the server in time checks. Default is 1 minute.
+func WithClockSkew(d time.Duration) AuthorizerOption {
+	return func(a *Authorizer) {
+		a.clockSkew = d
+	}
+}
+
+// authTime returns auth_time of the ID token, or iat when auth_time is not set
+func authTime(d *IDTokenData) time.Time {
+	switch {
+	case d.AuthTime != 0:
+		return time.Unix(d.AuthTime, 0)
+	case d.Iat != 0:
+		return time.Unix(d.Iat, 0)
+	default:
+		return time.Time{}
+	}
+}
+
+// RequireMaxAge returns a middleware rejecting ID tokens authenticated more than maxAge ago,
+// e.g. to require re-authentication within the last 10 minutes for payment endpoints.
+// The age is calculated from auth_time, or iat when auth_time is not in the token. Use it after VerifyIDTokenMiddleware.
+// It responds 401 Unauthorized with error="insufficient_user_authentication" and max_age (RFC 9470).
+func (a *Authorizer) RequireMaxAge(maxAge time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			u, ok := UserFromContext(r.Context())
+			if !ok {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if u.AuthTime.IsZero() || a.lineClient.clock.Now().Sub(u.AuthTime) > maxAge+a.clockSkew {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+					`Bearer error="insufficient_user_authentication", error_description="authentication is too old", max_age=%d`,
+					int64(maxAge.Seconds())))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package messaging
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ActionType is a type of action object
+type ActionType string
+
+const (
+	ActionTypeURI      ActionType = "uri"
+	ActionTypePostback ActionType = "postback"
+	ActionTypeMessage  ActionType = "message"
+)
+
+// Action is an action object performed when the user taps buttons, images or rich menu areas.
+// https://developers.line.biz/ja/reference/messaging-api/#action-objects
+type Action interface {
+	Type() ActionType
+}
+
+// URIAction is an action to open the URI.
+// https://developers.line.biz/ja/reference/messaging-api/#uri-action
+type URIAction struct {
+	Label string
+	URI   string
+}
+
+// NewURIAction returns new URIAction
+func NewURIAction(label, uri string) *URIAction {
+	return &URIAction{Label: label, URI: uri}
+}
+
+// Type implements Action
+func (a *URIAction) Type() ActionType {
+	return ActionTypeURI
+}
+
+// MarshalJSON implements json.Marshaler
+func (a *URIAction) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type  ActionType `json:"type"`
+		Label string     `json:"label,omitempty"`
+		URI   string     `json:"uri"`
+	}{
+		Type:  a.Type(),
+		Label: a.Label,
+		URI:   a.URI,
+	})
+}
+
+// PostbackAction is an action to return a postback event with the data to the webhook.
+// https://developers.line.biz/ja/reference/messaging-api/#postback-action
+type PostbackAction struct {
+	Label       string
+	Data        string
+	DisplayText string
+}
+
+// NewPostbackAction returns new PostbackAction
+func NewPostbackAction(label, data string) *PostbackAction {
+	return &PostbackAction{Label: label, Data: data}
+}
+
+// Type implements Action
+func (a *PostbackAction) Type() ActionType {
+	return ActionTypePostback
+}
+
+// MarshalJSON implements json.Marshaler
+func (a *PostbackAction) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type        ActionType `json:"type"`
+		Label       string     `json:"label,omitempty"`
+		Data        string     `json:"data"`
+		DisplayText string     `json:"displayText,omitempty"`
+	}{
+		Type:        a.Type(),
+		Label:       a.Label,
+		Data:        a.Data,
+		DisplayText: a.DisplayText,
+	})
+}
+
+// MessageAction is an action to send the text as a message from the user.
+// https://developers.line.biz/ja/reference/messaging-api/#message-action
+type MessageAction struct {
+	Label string
+	Text  string
+}
+
+// NewMessageAction returns new MessageAction
+func NewMessageAction(label, text string) *MessageAction {
+	return &MessageAction{Label: label, Text: text}
+}
+
+// Type implements Action
+func (a *MessageAction) Type() ActionType {
+	return ActionTypeMessage
+}
+
+// MarshalJSON implements json.Marshaler
+func (a *MessageAction) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type  ActionType `json:"type"`
+		Label string     `json:"label,omitempty"`
+		Text  string     `json:"text"`
+	}{
+		Type:  a.Type(),
+		Label: a.Label,
+		Text:  a.Text,
+	})
+}
+
+// unmarshalAction decodes the action object into the concrete Action type
+func unmarshalAction(b []byte) (Action, error) {
+	if len(b) == 0 || string(b) == "null" {
+		return nil, nil
+	}
+	v := struct {
+		Type ActionType `json:"type"`
+	}{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+
+	var a Action
+	switch v.Type {
+	case ActionTypeURI:
+		a = &URIAction{}
+	case ActionTypePostback:
+		a = &PostbackAction{}
+	case ActionTypeMessage:
+		a = &MessageAction{}
+	default:
+		return nil, fmt.Errorf("unsupported action type %s", v.Type)
+	}
+	if err := json.Unmarshal(b, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
@@ -0,0 +1,143 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const (
+	// See https://developers.line.biz/ja/reference/messaging-api/#create-upload-audience-group
+	urlAudienceGroupUpload = "https://api.line.me/v2/bot/audienceGroup/upload"
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-audience-group
+	urlAudienceGroup = "https://api.line.me/v2/bot/audienceGroup/%d"
+
+	// Maximum number of user IDs uploaded in one request
+	maxAudiences = 10000
+)
+
+// AudienceGroup is an audience group object
+// https://developers.line.biz/ja/reference/messaging-api/#get-audience-group
+type AudienceGroup struct {
+	AudienceGroupID int64  `json:"audienceGroupId"`
+	Type            string `json:"type"`
+	Description     string `json:"description"`
+	Status          string `json:"status"`
+	AudienceCount   int64  `json:"audienceCount"`
+	Created         int64  `json:"created"`
+	Permission      string `json:"permission"`
+	CreateRoute     string `json:"createRoute"`
+	ExpireTimestamp int64  `json:"expireTimestamp,omitempty"`
+	IsIfaAudience   bool   `json:"isIfaAudience"`
+}
+
+type audience struct {
+	ID string `json:"id"`
+}
+
+func toAudiences(userIDs []string) ([]audience, error) {
+	if len(userIDs) == 0 || len(userIDs) > maxAudiences {
+		return nil, fmt.Errorf("number of user IDs must be 1 to %d: got %d", maxAudiences, len(userIDs))
+	}
+	a := make([]audience, len(userIDs))
+	for i, id := range userIDs {
+		a[i] = audience{ID: id}
+	}
+	return a, nil
+}
+
+// CreateAudienceGroup is a function to call create-upload-audience-group API with user IDs
+// https://developers.line.biz/ja/reference/messaging-api/#create-upload-audience-group
+func (c *Client) CreateAudienceGroup(ctx context.Context, description string, userIDs []string) (*AudienceGroup, error) {
+	// Check paramaters
+	if description == "" {
+		return nil, errors.New("description not found")
+	}
+	audiences, err := toAudiences(userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	body := struct {
+		Description string     `json:"description"`
+		Audiences   []audience `json:"audiences"`
+	}{
+		Description: description,
+		Audiences:   audiences,
+	}
+
+	// Prepare http request
+	req, err := mutating(c.newJSONRequest(ctx, http.MethodPost, urlAudienceGroupUpload, body))
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	g := &AudienceGroup{}
+	if err := c.doRequestGetBody(req, g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// AddAudiences is a function to call update-upload-audience-group API to add user IDs to the audience group
+// https://developers.line.biz/ja/reference/messaging-api/#update-upload-audience-group
+func (c *Client) AddAudiences(ctx context.Context, audienceGroupID int64, userIDs []string) error {
+	audiences, err := toAudiences(userIDs)
+	if err != nil {
+		return err
+	}
+
+	body := struct {
+		AudienceGroupID int64      `json:"audienceGroupId"`
+		Audiences       []audience `json:"audiences"`
+	}{
+		AudienceGroupID: audienceGroupID,
+		Audiences:       audiences,
+	}
+
+	// Prepare http request
+	req, err := mutating(c.newJSONRequest(ctx, http.MethodPut, urlAudienceGroupUpload, body))
+	if err != nil {
+		return err
+	}
+
+	// Do http request
+	return c.doRequestGetBody(req, nil)
+}
+
+// GetAudienceGroup is a function to call get-audience-group API
+// https://developers.line.biz/ja/reference/messaging-api/#get-audience-group
+func (c *Client) GetAudienceGroup(ctx context.Context, audienceGroupID int64) (*AudienceGroup, error) {
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(urlAudienceGroup, audienceGroupID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	res := struct {
+		AudienceGroup *AudienceGroup `json:"audienceGroup"`
+	}{}
+	if err := c.doRequestGetBody(req, &res); err != nil {
+		return nil, err
+	}
+	if res.AudienceGroup == nil {
+		return nil, fmt.Errorf("audience group %d not found in response", audienceGroupID)
+	}
+	return res.AudienceGroup, nil
+}
+
+// DeleteAudienceGroup is a function to call delete-audience-group API
+// https://developers.line.biz/ja/reference/messaging-api/#delete-audience-group
+func (c *Client) DeleteAudienceGroup(ctx context.Context, audienceGroupID int64) error {
+	// Prepare http request
+	req, err := mutating(http.NewRequestWithContext(ctx, http.MethodDelete, fmt.Sprintf(urlAudienceGroup, audienceGroupID), nil))
+	if err != nil {
+		return err
+	}
+
+	// Do http request
+	return c.doRequestGetBody(req, nil)
+}
@@ -0,0 +1,48 @@
+package messaging
+
+import (
+	"context"
+	"net/http"
+)
+
+const (
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-bot-info
+	urlBotInfo = "https://api.line.me/v2/bot/info"
+)
+
+// ChatMode is the chat mode of LINE Official Account
+type ChatMode string
+
+const (
+	ChatModeChat ChatMode = "chat"
+	ChatModeBot  ChatMode = "bot"
+)
+
+// BotInfo is the response json struct of get-bot-info API
+// https://developers.line.biz/ja/reference/messaging-api/#get-bot-info
+type BotInfo struct {
+	UserID         string   `json:"userId"`
+	BasicID        string   `json:"basicId"`
+	PremiumID      string   `json:"premiumId,omitempty"`
+	DisplayName    string   `json:"displayName"`
+	PictureURL     string   `json:"pictureUrl,omitempty"`
+	ChatMode       ChatMode `json:"chatMode"`
+	MarkAsReadMode string   `json:"markAsReadMode"`
+}
+
+// GetBotInfo is a function to call get-bot-info API
+// https://developers.line.biz/ja/reference/messaging-api/#get-bot-info
+func (c *Client) GetBotInfo(ctx context.Context) (*BotInfo, error) {
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlBotInfo, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	b := &BotInfo{}
+	if err := c.doRequestGetBody(req, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,124 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jlandowner/goline"
+)
+
+const (
+	defaultBulkPushMaxRetries = 5
+	defaultBulkPushBackoff    = time.Second
+	maxBulkPushBackoff        = time.Minute
+)
+
+// BulkPushFailure is the recipient failed to send by BulkPush
+type BulkPushFailure struct {
+	UserID string
+	Err    error
+}
+
+// BulkPushResult is the result of BulkPush
+type BulkPushResult struct {
+	Sent   int
+	Failed []BulkPushFailure
+}
+
+// BulkPushOption configures BulkPush
+type BulkPushOption func(*bulkPushOptions)
+
+type bulkPushOptions struct {
+	maxRetries int
+	backoff    time.Duration
+	onProgress func(done, total int)
+}
+
+// WithBulkPushMaxRetries sets the maximum number of retries of each batch on 429 Too Many Requests. Default is 5.
+func WithBulkPushMaxRetries(n int) BulkPushOption {
+	return func(o *bulkPushOptions) {
+		o.maxRetries = n
+	}
+}
+
+// WithBulkPushBackoff sets the initial backoff of retries when the response has no Retry-After header. Default is 1 second.
+// The backoff is doubled on each retry up to 1 minute.
+func WithBulkPushBackoff(d time.Duration) BulkPushOption {
+	return func(o *bulkPushOptions) {
+		o.backoff = d
+	}
+}
+
+// WithBulkPushProgress sets a function called after each batch with the number of processed recipients
+func WithBulkPushProgress(fn func(done, total int)) BulkPushOption {
+	return func(o *bulkPushOptions) {
+		o.onProgress = fn
+	}
+}
+
+// BulkPush sends the messages to any number of users by batching multicast calls of up to 500 recipients.
+// Each batch is retried on 429 Too Many Requests honoring Retry-After header with the same X-Line-Retry-Key.
+// The recipients of failed batches are reported in BulkPushResult.Failed and the other batches are continued.
+// The error is returned only when ctx is done.
+func (c *Client) BulkPush(ctx context.Context, to []string, messages []Message, opts ...BulkPushOption) (*BulkPushResult, error) {
+	o := &bulkPushOptions{
+		maxRetries: defaultBulkPushMaxRetries,
+		backoff:    defaultBulkPushBackoff,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	result := &BulkPushResult{}
+	for start := 0; start < len(to); start += maxMulticastRecipients {
+		end := start + maxMulticastRecipients
+		if end > len(to) {
+			end = len(to)
+		}
+		batch := to[start:end]
+
+		if err := c.multicastWithRetry(ctx, batch, messages, o); err != nil {
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+			for _, id := range batch {
+				result.Failed = append(result.Failed, BulkPushFailure{UserID: id, Err: err})
+			}
+		} else {
+			result.Sent += len(batch)
+		}
+
+		if o.onProgress != nil {
+			o.onProgress(end, len(to))
+		}
+	}
+	return result, nil
+}
+
+func (c *Client) multicastWithRetry(ctx context.Context, to []string, messages []Message, o *bulkPushOptions) error {
+	// Retry with the same key not to send the batch twice
+	ctx = WithRetryKey(ctx, NewRetryKey())
+
+	backoff := o.backoff
+	for i := 0; ; i++ {
+		err := c.Multicast(ctx, to, messages...)
+		if err == nil || errors.Is(err, ErrAlreadyAccepted) {
+			return nil
+		}
+		if !errors.Is(err, goline.ErrTooManyRequests) || i >= o.maxRetries {
+			return err
+		}
+
+		wait := backoff
+		if d, ok := RetryAfter(err); ok {
+			wait = d
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+		if backoff *= 2; backoff > maxBulkPushBackoff {
+			backoff = maxBulkPushBackoff
+		}
+	}
+}
@@ -0,0 +1,233 @@
+// Package messaging is a client of LINE Messaging API.
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/jlandowner/goline"
+)
+
+const (
+	// See https://developers.line.biz/ja/reference/messaging-api/#send-reply-message
+	urlReplyMessage = "https://api.line.me/v2/bot/message/reply"
+
+	// Maximum number of messages sent in one request
+	maxMessages = 5
+
+	// Maximum size of the error response body to read
+	maxErrorResponseSize = 64 << 10
+
+	// Error message of reply API when the reply token is expired or already used
+	messageInvalidReplyToken = "Invalid reply token"
+)
+
+var (
+	// ErrInvalidReplyToken is returned when the reply token is expired or already used.
+	// Reply tokens can be used only once and must be used within a certain period after receiving the webhook.
+	ErrInvalidReplyToken = errors.New("invalid reply token")
+)
+
+// Client is an http client access to LINE Messaging API
+type Client struct {
+	channelAccessToken string
+	client             *http.Client
+	dryRun             func(r *DryRunRequest)
+	dryRunSeq          atomic.Int64
+}
+
+// ClientOption configures Client
+type ClientOption func(*Client)
+
+// NewClient returns LINE Messaging API Client. "channelAccessToken" is the channel access token of Messaging API channel.
+// nil client means http.DefaultClient.
+func NewClient(channelAccessToken string, client *http.Client, opts ...ClientOption) *Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	c := &Client{
+		channelAccessToken: channelAccessToken,
+		client:             client,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ErrorResponse is the error response json struct of Messaging API.
+// https://developers.line.biz/ja/reference/messaging-api/#error-responses
+type ErrorResponse struct {
+	Message string        `json:"message"`
+	Details []ErrorDetail `json:"details,omitempty"`
+}
+
+// ErrorDetail is the detail of ErrorResponse
+type ErrorDetail struct {
+	Message  string `json:"message"`
+	Property string `json:"property"`
+}
+
+// ReplyMessage is a function to call send-reply-message API.
+// ErrInvalidReplyToken is returned when the reply token is expired or already used.
+// https://developers.line.biz/ja/reference/messaging-api/#send-reply-message
+func (c *Client) ReplyMessage(ctx context.Context, replyToken string, messages ...Message) error {
+	// Check paramaters
+	if replyToken == "" {
+		return errors.New("reply token not found")
+	}
+	if len(messages) == 0 || len(messages) > maxMessages {
+		return fmt.Errorf("number of messages must be 1 to %d: got %d", maxMessages, len(messages))
+	}
+
+	body := struct {
+		ReplyToken string    `json:"replyToken"`
+		Messages   []Message `json:"messages"`
+	}{
+		ReplyToken: replyToken,
+		Messages:   messages,
+	}
+
+	// Prepare http request
+	req, err := mutating(c.newJSONRequest(ctx, http.MethodPost, urlReplyMessage, body))
+	if err != nil {
+		return err
+	}
+
+	// Do http request
+	if err := c.doRequestGetBody(req, nil); err != nil {
+		if errors.Is(err, goline.ErrBadRequest) && isInvalidReplyToken(err) {
+			return ErrInvalidReplyToken
+		}
+		return err
+	}
+	return nil
+}
+
+func (c *Client) newJSONRequest(ctx context.Context, method, url string, body interface{}) (*http.Request, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// doRequestGetBody does the request and decodes the response body into resbody.
+// resbody can be nil when the response body is not used.
+func (c *Client) doRequestGetBody(req *http.Request, resbody interface{}) error {
+	_, err := c.doRequest(req, resbody)
+	return err
+}
+
+// doRequest does the request and decodes the response body into resbody.
+// It returns the response header for the APIs returning values in headers.
+func (c *Client) doRequest(req *http.Request, resbody interface{}) (http.Header, error) {
+	res, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if resbody == nil {
+		return res.Header, nil
+	}
+	return res.Header, json.NewDecoder(res.Body).Decode(resbody)
+}
+
+// do does the request with the channel access token and checks the response status.
+// The caller must close the response body when the error is nil.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if req == nil {
+		return nil, errors.New("request is nil")
+	}
+	req.Header.Set("Authorization", "Bearer "+c.channelAccessToken)
+
+	if c.dryRun != nil && isMutating(req) {
+		return c.dryRunResponse(req)
+	}
+
+	// Do http request
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check Status Code
+	if id := res.Header.Get(headerAcceptedRequestID); res.StatusCode == http.StatusConflict && id != "" {
+		res.Body.Close()
+		return nil, &alreadyAcceptedError{requestID: id}
+	}
+	if err := goline.CheckResponse(res); err != nil {
+		defer res.Body.Close()
+		e, decodeErr := decodeErrorResponse(res.Body)
+		if decodeErr != nil {
+			err = fmt.Errorf("%w: failed to read error response: %v", err, decodeErr)
+		}
+		return nil, &apiError{err: err, res: e, statusCode: res.StatusCode}
+	}
+	return res, nil
+}
+
+// decodeErrorResponse decodes the error response body.
+// The raw body is set to Message when it is not the json of ErrorResponse, e.g. an HTML error page of a proxy.
+func decodeErrorResponse(body io.Reader) (*ErrorResponse, error) {
+	b, err := io.ReadAll(io.LimitReader(body, maxErrorResponseSize))
+	if err != nil {
+		return &ErrorResponse{}, err
+	}
+	e := &ErrorResponse{}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return e, nil
+	}
+	if err := json.Unmarshal(b, e); err != nil {
+		e.Message = string(bytes.TrimSpace(b))
+	}
+	return e, nil
+}
+
+// apiError wraps the status error with ErrorResponse
+type apiError struct {
+	err        error
+	res        *ErrorResponse
+	statusCode int
+}
+
+func (e *apiError) Error() string {
+	// The message is included by goline.APIError
+	return e.err.Error()
+}
+
+func (e *apiError) Unwrap() error {
+	return e.err
+}
+
+// RetryAfter returns the duration of Retry-After header in the error response, e.g. of 429 Too Many Requests
+func RetryAfter(err error) (time.Duration, bool) {
+	var e *goline.APIError
+	if errors.As(err, &e) && e.RetryAfter > 0 {
+		return e.RetryAfter, true
+	}
+	return 0, false
+}
+
+func isNotFound(err error) bool {
+	var e *apiError
+	return errors.As(err, &e) && e.statusCode == http.StatusNotFound
+}
+
+func isInvalidReplyToken(err error) bool {
+	var e *apiError
+	return errors.As(err, &e) && e.res.Message == messageInvalidReplyToken
+}
@@ -0,0 +1,76 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jlandowner/goline"
+)
+
+func respond(status int, body string) *http.Client {
+	return &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body)), Request: r}, nil
+	})}
+}
+
+func TestNewClientNilHTTPClient(t *testing.T) {
+	if c := NewClient("token", nil); c.client != http.DefaultClient {
+		t.Errorf("http client = %v, want http.DefaultClient", c.client)
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	ctx := context.Background()
+	msg := &TextMessage{Text: "hi"}
+
+	t.Run("invalid reply token", func(t *testing.T) {
+		c := NewClient("token", respond(http.StatusBadRequest, `{"message":"Invalid reply token"}`))
+		if err := c.ReplyMessage(ctx, "reply-token", msg); !errors.Is(err, ErrInvalidReplyToken) {
+			t.Errorf("ReplyMessage() error = %v, want ErrInvalidReplyToken", err)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		c := NewClient("token", respond(http.StatusBadRequest, `{"message":"The request body has 1 error(s)","details":[{"message":"May not be empty","property":"messages[0].text"}]}`))
+		err := c.PushMessage(ctx, "U1", msg)
+		var e *apiError
+		if !errors.As(err, &e) || e.res.Message != "The request body has 1 error(s)" || len(e.res.Details) != 1 {
+			t.Errorf("PushMessage() error = %v", err)
+		}
+		if !errors.Is(err, goline.ErrBadRequest) {
+			t.Errorf("errors.Is(%v, goline.ErrBadRequest) = false", err)
+		}
+	})
+
+	t.Run("not json", func(t *testing.T) {
+		c := NewClient("token", respond(http.StatusBadGateway, "<html>502 Bad Gateway</html>\n"))
+		err := c.PushMessage(ctx, "U1", msg)
+		var e *apiError
+		if !errors.As(err, &e) || e.res.Message != "<html>502 Bad Gateway</html>" {
+			t.Errorf("PushMessage() error = %v, want the raw body in the message", err)
+		}
+		if !errors.Is(err, goline.ErrBadGateway) {
+			t.Errorf("errors.Is(%v, goline.ErrBadGateway) = false", err)
+		}
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		readErr := errors.New("connection reset")
+		hc := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusInternalServerError, Header: http.Header{},
+				Body: io.NopCloser(io.MultiReader(strings.NewReader("{"), errReader{readErr})), Request: r}, nil
+		})}
+		err := NewClient("token", hc).PushMessage(ctx, "U1", msg)
+		if !errors.Is(err, goline.ErrInternalServerError) || !strings.Contains(err.Error(), "connection reset") {
+			t.Errorf("PushMessage() error = %v, want the read error", err)
+		}
+	})
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
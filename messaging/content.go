@@ -0,0 +1,114 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+const (
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-content
+	urlMessageContent = "https://api-data.line.me/v2/bot/message/%s/content"
+)
+
+var (
+	// ErrContentTooLarge is returned when the content size exceeds the limit set by WithMaxContentSize
+	ErrContentTooLarge = errors.New("content too large")
+)
+
+// ContentOption configures GetMessageContent
+type ContentOption func(*contentOptions)
+
+type contentOptions struct {
+	offset  int64
+	maxSize int64
+}
+
+// WithContentOffset resumes the download from the offset byte by Range request.
+// It is useful to continue the interrupted download.
+func WithContentOffset(offset int64) ContentOption {
+	return func(o *contentOptions) {
+		o.offset = offset
+	}
+}
+
+// WithMaxContentSize limits the size of content to read.
+// ErrContentTooLarge is returned when Content-Length exceeds the limit
+// or while reading the body when the body exceeds the limit.
+func WithMaxContentSize(n int64) ContentOption {
+	return func(o *contentOptions) {
+		o.maxSize = n
+	}
+}
+
+// GetMessageContent is a function to call get-content API to download image, video, audio and file sent by users.
+// It returns the content body and the content type. The caller must close the body.
+// https://developers.line.biz/ja/reference/messaging-api/#get-content
+func (c *Client) GetMessageContent(ctx context.Context, messageID string, opts ...ContentOption) (io.ReadCloser, string, error) {
+	// Check paramaters
+	if messageID == "" {
+		return nil, "", errors.New("message ID not found")
+	}
+	o := &contentOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(urlMessageContent, url.PathEscape(messageID)), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if o.offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", o.offset))
+	}
+
+	// Do http request
+	res, err := c.do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	if o.offset > 0 && res.StatusCode != http.StatusPartialContent {
+		res.Body.Close()
+		return nil, "", fmt.Errorf("range request is not supported: status %d", res.StatusCode)
+	}
+
+	body := res.Body
+	if o.maxSize > 0 {
+		if res.ContentLength > o.maxSize {
+			res.Body.Close()
+			return nil, "", fmt.Errorf("%w: %d bytes", ErrContentTooLarge, res.ContentLength)
+		}
+		body = &limitedReadCloser{rc: res.Body, n: o.maxSize}
+	}
+	return body, res.Header.Get("Content-Type"), nil
+}
+
+// limitedReadCloser returns ErrContentTooLarge when reading more than n bytes
+type limitedReadCloser struct {
+	rc io.ReadCloser
+	n  int64
+}
+
+func (l *limitedReadCloser) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, ErrContentTooLarge
+	}
+	// Read one more byte than the limit to detect the overflow
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.rc.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n + int(l.n), ErrContentTooLarge
+	}
+	return n, err
+}
+
+func (l *limitedReadCloser) Close() error {
+	return l.rc.Close()
+}
@@ -0,0 +1,104 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// failTransport fails the test when any request is sent
+func failTransport(t *testing.T) *http.Client {
+	return &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		t.Errorf("request sent in dry-run mode: %s %s", r.Method, r.URL)
+		return nil, errors.New("sent")
+	})}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestDryRunPlaceholderIDs(t *testing.T) {
+	ctx := context.Background()
+	q := &DryRunQueue{}
+	c := NewClient("token", failTransport(t), WithDryRun(q.Record))
+
+	richMenuID, err := c.CreateRichMenu(ctx, &RichMenu{Name: "menu"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(richMenuID, "richmenu-dryrun-") {
+		t.Errorf("CreateRichMenu() = %q, want placeholder ID", richMenuID)
+	}
+
+	g, err := c.CreateAudienceGroup(ctx, "audience", []string{"U1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.AudienceGroupID == 0 || g.Description != "audience" {
+		t.Errorf("CreateAudienceGroup() = %+v, want placeholder ID", g)
+	}
+
+	requestID, err := c.Narrowcast(ctx, &NarrowcastRequest{Messages: []Message{&TextMessage{Text: "hi"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(requestID, "dryrun-") {
+		t.Errorf("Narrowcast() = %q, want placeholder ID", requestID)
+	}
+
+	// The placeholder IDs are unique
+	other, _ := c.CreateRichMenu(ctx, &RichMenu{Name: "menu"})
+	if other == richMenuID {
+		t.Errorf("CreateRichMenu() returned the same ID %q twice", other)
+	}
+
+	if err := c.PushMessage(ctx, "U1", &TextMessage{Text: "hi"}); err != nil {
+		t.Errorf("PushMessage() error = %v", err)
+	}
+	if n := len(q.Requests()); n != 5 {
+		t.Errorf("%d requests recorded, want 5", n)
+	}
+}
+
+func TestDryRunSendsNonMutatingRequests(t *testing.T) {
+	ctx := context.Background()
+	var sent []string
+	hc := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		sent = append(sent, r.Method+" "+r.URL.Path)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"success":true,"statusCode":200,"reason":"OK"}`)),
+			Request:    r,
+		}, nil
+	})}
+	q := &DryRunQueue{}
+	c := NewClient("token", hc, WithDryRun(q.Record))
+
+	// test-webhook-endpoint is POST but changes nothing
+	res, err := c.TestWebhookEndpoint(ctx, "https://example.com/webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Success || res.StatusCode != http.StatusOK {
+		t.Errorf("TestWebhookEndpoint() = %+v, want the result of LINE", res)
+	}
+
+	if err := c.SetWebhookEndpoint(ctx, "https://example.com/webhook"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteRichMenu(ctx, "richmenu-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sent) != 1 || sent[0] != "POST /v2/bot/channel/webhook/test" {
+		t.Errorf("sent requests = %v, want only test-webhook-endpoint", sent)
+	}
+	if n := len(q.Requests()); n != 2 {
+		t.Errorf("%d requests recorded, want 2", n)
+	}
+}
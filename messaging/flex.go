@@ -0,0 +1,664 @@
+package messaging
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Flex Message is built by the fluent builders in this file.
+// Containers and components are sealed interfaces so that only valid structures can be compiled,
+// e.g. a carousel accepts only bubbles and a box accepts only components.
+//
+//	msg := messaging.NewFlexMessage("Hello",
+//		messaging.NewBubble().Body(
+//			messaging.NewBox(messaging.FlexLayoutVertical,
+//				messaging.NewText("Hello").Weight(messaging.FlexWeightBold),
+//				messaging.NewButton(messaging.NewURIAction("Open", "https://example.com")),
+//			),
+//		),
+//	)
+//
+// https://developers.line.biz/ja/reference/messaging-api/#flex-message
+
+const (
+	// Maximum number of bubbles in a carousel
+	maxCarouselBubbles = 12
+)
+
+// FlexLayout is the layout of box
+type FlexLayout string
+
+const (
+	FlexLayoutHorizontal FlexLayout = "horizontal"
+	FlexLayoutVertical   FlexLayout = "vertical"
+	FlexLayoutBaseline   FlexLayout = "baseline"
+)
+
+// FlexSpacing is the size of spacing and margin
+type FlexSpacing string
+
+const (
+	FlexSpacingNone FlexSpacing = "none"
+	FlexSpacingXs   FlexSpacing = "xs"
+	FlexSpacingSm   FlexSpacing = "sm"
+	FlexSpacingMd   FlexSpacing = "md"
+	FlexSpacingLg   FlexSpacing = "lg"
+	FlexSpacingXl   FlexSpacing = "xl"
+	FlexSpacingXxl  FlexSpacing = "xxl"
+)
+
+// FlexSize is the size of text and image
+type FlexSize string
+
+const (
+	FlexSizeXxs  FlexSize = "xxs"
+	FlexSizeXs   FlexSize = "xs"
+	FlexSizeSm   FlexSize = "sm"
+	FlexSizeMd   FlexSize = "md"
+	FlexSizeLg   FlexSize = "lg"
+	FlexSizeXl   FlexSize = "xl"
+	FlexSizeXxl  FlexSize = "xxl"
+	FlexSize3xl  FlexSize = "3xl"
+	FlexSize4xl  FlexSize = "4xl"
+	FlexSize5xl  FlexSize = "5xl"
+	FlexSizeFull FlexSize = "full"
+)
+
+// FlexWeight is the font weight of text
+type FlexWeight string
+
+const (
+	FlexWeightRegular FlexWeight = "regular"
+	FlexWeightBold    FlexWeight = "bold"
+)
+
+// FlexAlign is the horizontal alignment
+type FlexAlign string
+
+const (
+	FlexAlignStart  FlexAlign = "start"
+	FlexAlignEnd    FlexAlign = "end"
+	FlexAlignCenter FlexAlign = "center"
+)
+
+// FlexGravity is the vertical alignment
+type FlexGravity string
+
+const (
+	FlexGravityTop    FlexGravity = "top"
+	FlexGravityBottom FlexGravity = "bottom"
+	FlexGravityCenter FlexGravity = "center"
+)
+
+// FlexButtonStyle is the style of button
+type FlexButtonStyle string
+
+const (
+	FlexButtonStylePrimary   FlexButtonStyle = "primary"
+	FlexButtonStyleSecondary FlexButtonStyle = "secondary"
+	FlexButtonStyleLink      FlexButtonStyle = "link"
+)
+
+// FlexButtonHeight is the height of button
+type FlexButtonHeight string
+
+const (
+	FlexButtonHeightSm FlexButtonHeight = "sm"
+	FlexButtonHeightMd FlexButtonHeight = "md"
+)
+
+// FlexAspectMode is the aspect mode of image
+type FlexAspectMode string
+
+const (
+	FlexAspectModeCover FlexAspectMode = "cover"
+	FlexAspectModeFit   FlexAspectMode = "fit"
+)
+
+// FlexBubbleSize is the size of bubble
+type FlexBubbleSize string
+
+const (
+	FlexBubbleSizeNano  FlexBubbleSize = "nano"
+	FlexBubbleSizeMicro FlexBubbleSize = "micro"
+	FlexBubbleSizeKilo  FlexBubbleSize = "kilo"
+	FlexBubbleSizeMega  FlexBubbleSize = "mega"
+	FlexBubbleSizeGiga  FlexBubbleSize = "giga"
+)
+
+// FlexMessage is a flex message object.
+// https://developers.line.biz/ja/reference/messaging-api/#flex-message
+type FlexMessage struct {
+	AltText  string
+	Contents FlexContainer
+}
+
+// NewFlexMessage returns new FlexMessage
+func NewFlexMessage(altText string, contents FlexContainer) *FlexMessage {
+	return &FlexMessage{AltText: altText, Contents: contents}
+}
+
+// Type implements Message
+func (m *FlexMessage) Type() MessageType {
+	return MessageTypeFlex
+}
+
+// MarshalJSON implements json.Marshaler
+func (m *FlexMessage) MarshalJSON() ([]byte, error) {
+	if m.AltText == "" {
+		return nil, fmt.Errorf("flex message altText is required")
+	}
+	if m.Contents == nil {
+		return nil, fmt.Errorf("flex message contents is required")
+	}
+	return json.Marshal(struct {
+		Type     MessageType   `json:"type"`
+		AltText  string        `json:"altText"`
+		Contents FlexContainer `json:"contents"`
+	}{
+		Type:     m.Type(),
+		AltText:  m.AltText,
+		Contents: m.Contents,
+	})
+}
+
+// FlexContainer is a container of flex message. Implemented by *Bubble and *Carousel.
+type FlexContainer interface {
+	flexContainer()
+}
+
+// FlexComponent is a component in a box. Implemented by *Box, *Text, *Button, *Image and *Separator.
+type FlexComponent interface {
+	flexComponent()
+}
+
+// FlexHeroComponent is a component of bubble hero block. Implemented by *Box and *Image.
+type FlexHeroComponent interface {
+	flexHeroComponent()
+}
+
+// Bubble is a bubble container.
+// https://developers.line.biz/ja/reference/messaging-api/#bubble
+type Bubble struct {
+	size   FlexBubbleSize
+	header *Box
+	hero   FlexHeroComponent
+	body   *Box
+	footer *Box
+}
+
+// NewBubble returns new Bubble
+func NewBubble() *Bubble {
+	return &Bubble{}
+}
+
+func (*Bubble) flexContainer() {}
+
+// Size sets the size of bubble
+func (b *Bubble) Size(s FlexBubbleSize) *Bubble {
+	b.size = s
+	return b
+}
+
+// Header sets the header block
+func (b *Bubble) Header(box *Box) *Bubble {
+	b.header = box
+	return b
+}
+
+// Hero sets the hero block
+func (b *Bubble) Hero(c FlexHeroComponent) *Bubble {
+	b.hero = c
+	return b
+}
+
+// Body sets the body block
+func (b *Bubble) Body(box *Box) *Bubble {
+	b.body = box
+	return b
+}
+
+// Footer sets the footer block
+func (b *Bubble) Footer(box *Box) *Bubble {
+	b.footer = box
+	return b
+}
+
+// MarshalJSON implements json.Marshaler
+func (b *Bubble) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type   string            `json:"type"`
+		Size   FlexBubbleSize    `json:"size,omitempty"`
+		Header *Box              `json:"header,omitempty"`
+		Hero   FlexHeroComponent `json:"hero,omitempty"`
+		Body   *Box              `json:"body,omitempty"`
+		Footer *Box              `json:"footer,omitempty"`
+	}{
+		Type:   "bubble",
+		Size:   b.size,
+		Header: b.header,
+		Hero:   b.hero,
+		Body:   b.body,
+		Footer: b.footer,
+	})
+}
+
+// Carousel is a carousel container.
+// https://developers.line.biz/ja/reference/messaging-api/#f-carousel
+type Carousel struct {
+	bubbles []*Bubble
+}
+
+// NewCarousel returns new Carousel
+func NewCarousel(bubbles ...*Bubble) *Carousel {
+	return &Carousel{bubbles: bubbles}
+}
+
+func (*Carousel) flexContainer() {}
+
+// Add appends bubbles to the carousel
+func (c *Carousel) Add(bubbles ...*Bubble) *Carousel {
+	c.bubbles = append(c.bubbles, bubbles...)
+	return c
+}
+
+// MarshalJSON implements json.Marshaler
+func (c *Carousel) MarshalJSON() ([]byte, error) {
+	if len(c.bubbles) == 0 || len(c.bubbles) > maxCarouselBubbles {
+		return nil, fmt.Errorf("number of bubbles in carousel must be 1 to %d: got %d", maxCarouselBubbles, len(c.bubbles))
+	}
+	return json.Marshal(struct {
+		Type     string    `json:"type"`
+		Contents []*Bubble `json:"contents"`
+	}{
+		Type:     "carousel",
+		Contents: c.bubbles,
+	})
+}
+
+// Box is a box component.
+// https://developers.line.biz/ja/reference/messaging-api/#box
+type Box struct {
+	layout          FlexLayout
+	contents        []FlexComponent
+	flex            *int
+	spacing         FlexSpacing
+	margin          FlexSpacing
+	paddingAll      FlexSpacing
+	backgroundColor string
+	action          Action
+}
+
+// NewBox returns new Box
+func NewBox(layout FlexLayout, contents ...FlexComponent) *Box {
+	return &Box{layout: layout, contents: contents}
+}
+
+func (*Box) flexComponent()     {}
+func (*Box) flexHeroComponent() {}
+
+// Add appends components to the box
+func (b *Box) Add(contents ...FlexComponent) *Box {
+	b.contents = append(b.contents, contents...)
+	return b
+}
+
+// Flex sets the ratio of the width or height in the parent box
+func (b *Box) Flex(n int) *Box {
+	b.flex = &n
+	return b
+}
+
+// Spacing sets the minimum space between components
+func (b *Box) Spacing(s FlexSpacing) *Box {
+	b.spacing = s
+	return b
+}
+
+// Margin sets the space before the box in the parent box
+func (b *Box) Margin(s FlexSpacing) *Box {
+	b.margin = s
+	return b
+}
+
+// PaddingAll sets the padding of all sides
+func (b *Box) PaddingAll(s FlexSpacing) *Box {
+	b.paddingAll = s
+	return b
+}
+
+// BackgroundColor sets the background color in hex color code like #RRGGBB
+func (b *Box) BackgroundColor(color string) *Box {
+	b.backgroundColor = color
+	return b
+}
+
+// Action sets the action performed when the box is tapped
+func (b *Box) Action(a Action) *Box {
+	b.action = a
+	return b
+}
+
+// MarshalJSON implements json.Marshaler
+func (b *Box) MarshalJSON() ([]byte, error) {
+	contents := b.contents
+	if contents == nil {
+		contents = []FlexComponent{}
+	}
+	return json.Marshal(struct {
+		Type            string          `json:"type"`
+		Layout          FlexLayout      `json:"layout"`
+		Contents        []FlexComponent `json:"contents"`
+		Flex            *int            `json:"flex,omitempty"`
+		Spacing         FlexSpacing     `json:"spacing,omitempty"`
+		Margin          FlexSpacing     `json:"margin,omitempty"`
+		PaddingAll      FlexSpacing     `json:"paddingAll,omitempty"`
+		BackgroundColor string          `json:"backgroundColor,omitempty"`
+		Action          Action          `json:"action,omitempty"`
+	}{
+		Type:            "box",
+		Layout:          b.layout,
+		Contents:        contents,
+		Flex:            b.flex,
+		Spacing:         b.spacing,
+		Margin:          b.margin,
+		PaddingAll:      b.paddingAll,
+		BackgroundColor: b.backgroundColor,
+		Action:          b.action,
+	})
+}
+
+// Text is a text component.
+// https://developers.line.biz/ja/reference/messaging-api/#f-text
+type Text struct {
+	text    string
+	flex    *int
+	size    FlexSize
+	weight  FlexWeight
+	color   string
+	align   FlexAlign
+	gravity FlexGravity
+	wrap    bool
+	margin  FlexSpacing
+	action  Action
+}
+
+// NewText returns new Text
+func NewText(text string) *Text {
+	return &Text{text: text}
+}
+
+func (*Text) flexComponent() {}
+
+// Flex sets the ratio of the width or height in the parent box
+func (t *Text) Flex(n int) *Text {
+	t.flex = &n
+	return t
+}
+
+// Size sets the font size
+func (t *Text) Size(s FlexSize) *Text {
+	t.size = s
+	return t
+}
+
+// Weight sets the font weight
+func (t *Text) Weight(w FlexWeight) *Text {
+	t.weight = w
+	return t
+}
+
+// Color sets the font color in hex color code like #RRGGBB
+func (t *Text) Color(color string) *Text {
+	t.color = color
+	return t
+}
+
+// Align sets the horizontal alignment
+func (t *Text) Align(a FlexAlign) *Text {
+	t.align = a
+	return t
+}
+
+// Gravity sets the vertical alignment
+func (t *Text) Gravity(g FlexGravity) *Text {
+	t.gravity = g
+	return t
+}
+
+// Wrap enables wrapping text
+func (t *Text) Wrap() *Text {
+	t.wrap = true
+	return t
+}
+
+// Margin sets the space before the text in the parent box
+func (t *Text) Margin(s FlexSpacing) *Text {
+	t.margin = s
+	return t
+}
+
+// Action sets the action performed when the text is tapped
+func (t *Text) Action(a Action) *Text {
+	t.action = a
+	return t
+}
+
+// MarshalJSON implements json.Marshaler
+func (t *Text) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type    string      `json:"type"`
+		Text    string      `json:"text"`
+		Flex    *int        `json:"flex,omitempty"`
+		Size    FlexSize    `json:"size,omitempty"`
+		Weight  FlexWeight  `json:"weight,omitempty"`
+		Color   string      `json:"color,omitempty"`
+		Align   FlexAlign   `json:"align,omitempty"`
+		Gravity FlexGravity `json:"gravity,omitempty"`
+		Wrap    bool        `json:"wrap,omitempty"`
+		Margin  FlexSpacing `json:"margin,omitempty"`
+		Action  Action      `json:"action,omitempty"`
+	}{
+		Type:    "text",
+		Text:    t.text,
+		Flex:    t.flex,
+		Size:    t.size,
+		Weight:  t.weight,
+		Color:   t.color,
+		Align:   t.align,
+		Gravity: t.gravity,
+		Wrap:    t.wrap,
+		Margin:  t.margin,
+		Action:  t.action,
+	})
+}
+
+// Button is a button component. Action is required.
+// https://developers.line.biz/ja/reference/messaging-api/#button
+type Button struct {
+	action Action
+	flex   *int
+	style  FlexButtonStyle
+	height FlexButtonHeight
+	color  string
+	margin FlexSpacing
+}
+
+// NewButton returns new Button
+func NewButton(action Action) *Button {
+	return &Button{action: action}
+}
+
+func (*Button) flexComponent() {}
+
+// Flex sets the ratio of the width or height in the parent box
+func (b *Button) Flex(n int) *Button {
+	b.flex = &n
+	return b
+}
+
+// Style sets the style of button
+func (b *Button) Style(s FlexButtonStyle) *Button {
+	b.style = s
+	return b
+}
+
+// Height sets the height of button
+func (b *Button) Height(h FlexButtonHeight) *Button {
+	b.height = h
+	return b
+}
+
+// Color sets the color in hex color code like #RRGGBB
+func (b *Button) Color(color string) *Button {
+	b.color = color
+	return b
+}
+
+// Margin sets the space before the button in the parent box
+func (b *Button) Margin(s FlexSpacing) *Button {
+	b.margin = s
+	return b
+}
+
+// MarshalJSON implements json.Marshaler
+func (b *Button) MarshalJSON() ([]byte, error) {
+	if b.action == nil {
+		return nil, fmt.Errorf("button action is required")
+	}
+	return json.Marshal(struct {
+		Type   string           `json:"type"`
+		Action Action           `json:"action"`
+		Flex   *int             `json:"flex,omitempty"`
+		Style  FlexButtonStyle  `json:"style,omitempty"`
+		Height FlexButtonHeight `json:"height,omitempty"`
+		Color  string           `json:"color,omitempty"`
+		Margin FlexSpacing      `json:"margin,omitempty"`
+	}{
+		Type:   "button",
+		Action: b.action,
+		Flex:   b.flex,
+		Style:  b.style,
+		Height: b.height,
+		Color:  b.color,
+		Margin: b.margin,
+	})
+}
+
+// Image is an image component. The URL must be HTTPS.
+// https://developers.line.biz/ja/reference/messaging-api/#f-image
+type Image struct {
+	url         string
+	flex        *int
+	size        FlexSize
+	aspectRatio string
+	aspectMode  FlexAspectMode
+	margin      FlexSpacing
+	action      Action
+}
+
+// NewImage returns new Image
+func NewImage(url string) *Image {
+	return &Image{url: url}
+}
+
+func (*Image) flexComponent()     {}
+func (*Image) flexHeroComponent() {}
+
+// Flex sets the ratio of the width or height in the parent box
+func (i *Image) Flex(n int) *Image {
+	i.flex = &n
+	return i
+}
+
+// Size sets the width of image
+func (i *Image) Size(s FlexSize) *Image {
+	i.size = s
+	return i
+}
+
+// AspectRatio sets the aspect ratio of image as width and height
+func (i *Image) AspectRatio(width, height int) *Image {
+	i.aspectRatio = fmt.Sprintf("%d:%d", width, height)
+	return i
+}
+
+// AspectMode sets the aspect mode of image
+func (i *Image) AspectMode(m FlexAspectMode) *Image {
+	i.aspectMode = m
+	return i
+}
+
+// Margin sets the space before the image in the parent box
+func (i *Image) Margin(s FlexSpacing) *Image {
+	i.margin = s
+	return i
+}
+
+// Action sets the action performed when the image is tapped
+func (i *Image) Action(a Action) *Image {
+	i.action = a
+	return i
+}
+
+// MarshalJSON implements json.Marshaler
+func (i *Image) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type        string         `json:"type"`
+		URL         string         `json:"url"`
+		Flex        *int           `json:"flex,omitempty"`
+		Size        FlexSize       `json:"size,omitempty"`
+		AspectRatio string         `json:"aspectRatio,omitempty"`
+		AspectMode  FlexAspectMode `json:"aspectMode,omitempty"`
+		Margin      FlexSpacing    `json:"margin,omitempty"`
+		Action      Action         `json:"action,omitempty"`
+	}{
+		Type:        "image",
+		URL:         i.url,
+		Flex:        i.flex,
+		Size:        i.size,
+		AspectRatio: i.aspectRatio,
+		AspectMode:  i.aspectMode,
+		Margin:      i.margin,
+		Action:      i.action,
+	})
+}
+
+// Separator is a separator component.
+// https://developers.line.biz/ja/reference/messaging-api/#separator
+type Separator struct {
+	margin FlexSpacing
+	color  string
+}
+
+// NewSeparator returns new Separator
+func NewSeparator() *Separator {
+	return &Separator{}
+}
+
+func (*Separator) flexComponent() {}
+
+// Margin sets the space before the separator in the parent box
+func (s *Separator) Margin(m FlexSpacing) *Separator {
+	s.margin = m
+	return s
+}
+
+// Color sets the color in hex color code like #RRGGBB
+func (s *Separator) Color(color string) *Separator {
+	s.color = color
+	return s
+}
+
+// MarshalJSON implements json.Marshaler
+func (s *Separator) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type   string      `json:"type"`
+		Margin FlexSpacing `json:"margin,omitempty"`
+		Color  string      `json:"color,omitempty"`
+	}{
+		Type:   "separator",
+		Margin: s.margin,
+		Color:  s.color,
+	})
+}
@@ -0,0 +1,125 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jlandowner/goline"
+)
+
+const (
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-follower-ids
+	urlFollowerIDs = "https://api.line.me/v2/bot/followers/ids"
+
+	// Maximum number of user IDs in a page of get-follower-ids API
+	maxFollowerIDsLimit = 1000
+
+	// Retries and initial backoff when get-follower-ids API responds 429 Too Many Requests
+	maxFollowerIDsRetries  = 5
+	followerIDsBackoffBase = time.Second
+)
+
+// FollowerIDs is the response json struct of get-follower-ids API.
+// Next is the continuation token to get the next page. It is empty at the last page.
+// https://developers.line.biz/ja/reference/messaging-api/#get-follower-ids
+type FollowerIDs struct {
+	UserIDs []string `json:"userIds"`
+	Next    string   `json:"next,omitempty"`
+}
+
+// GetFollowerIDsPage is a function to call get-follower-ids API.
+// Pass the continuation token returned as FollowerIDs.Next to "start" to get the next page, or empty for the first page.
+// https://developers.line.biz/ja/reference/messaging-api/#get-follower-ids
+func (c *Client) GetFollowerIDsPage(ctx context.Context, start string) (*FollowerIDs, error) {
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlFollowerIDs, nil)
+	if err != nil {
+		return nil, err
+	}
+	params := req.URL.Query()
+	params.Set("limit", strconv.Itoa(maxFollowerIDsLimit))
+	if start != "" {
+		params.Set("start", start)
+	}
+	req.URL.RawQuery = params.Encode()
+
+	// Do http request and get response body
+	f := &FollowerIDs{}
+	if err := c.doRequestGetBody(req, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// IterateFollowerIDs returns Iterator of the user IDs of all followers.
+// Pages are retried with exponential backoff when the API responds 429 Too Many Requests.
+func (c *Client) IterateFollowerIDs() *Iterator[string] {
+	return NewIterator(func(ctx context.Context, next string) ([]string, string, error) {
+		backoff := followerIDsBackoffBase
+		for i := 0; ; i++ {
+			f, err := c.GetFollowerIDsPage(ctx, next)
+			if err == nil {
+				return f.UserIDs, f.Next, nil
+			}
+			if !errors.Is(err, goline.ErrTooManyRequests) || i >= maxFollowerIDsRetries {
+				return nil, "", err
+			}
+			if err := sleep(ctx, backoff); err != nil {
+				return nil, "", err
+			}
+			backoff *= 2
+		}
+	})
+}
+
+// GetFollowerIDs streams the user IDs of all followers over the channel, e.g. to sync the follower list into a database.
+// The channel is closed when all IDs are sent or an error occurs. The error is sent to the error channel.
+//
+//	ids, errc := c.GetFollowerIDs(ctx)
+//	for id := range ids {
+//		// save id
+//	}
+//	if err := <-errc; err != nil {
+//		return err
+//	}
+func (c *Client) GetFollowerIDs(ctx context.Context) (<-chan string, <-chan error) {
+	ids := make(chan string, maxFollowerIDsLimit)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(ids)
+		it := c.IterateFollowerIDs()
+		for {
+			id, err := it.Next(ctx)
+			if errors.Is(err, ErrIteratorDone) {
+				return
+			}
+			if err != nil {
+				errc <- err
+				return
+			}
+			select {
+			case ids <- id:
+			case <-ctx.Done():
+				errc <- ctx.Err()
+				return
+			}
+		}
+	}()
+	return ids, errc
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,208 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const (
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-group-summary
+	urlGroupSummary = "https://api.line.me/v2/bot/group/%s/summary"
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-members-group-count
+	urlGroupMemberCount = "https://api.line.me/v2/bot/group/%s/members/count"
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-group-member-user-ids
+	urlGroupMemberIDs = "https://api.line.me/v2/bot/group/%s/members/ids"
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-group-member-profile
+	urlGroupMemberProfile = "https://api.line.me/v2/bot/group/%s/member/%s"
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-members-room-count
+	urlRoomMemberCount = "https://api.line.me/v2/bot/room/%s/members/count"
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-room-member-user-ids
+	urlRoomMemberIDs = "https://api.line.me/v2/bot/room/%s/members/ids"
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-room-member-profile
+	urlRoomMemberProfile = "https://api.line.me/v2/bot/room/%s/member/%s"
+)
+
+// GroupSummary is the response json struct of get-group-summary API
+// https://developers.line.biz/ja/reference/messaging-api/#get-group-summary
+type GroupSummary struct {
+	GroupID    string `json:"groupId"`
+	GroupName  string `json:"groupName"`
+	PictureURL string `json:"pictureUrl,omitempty"`
+}
+
+// MemberIDs is the response json struct of get-group-member-user-ids and get-room-member-user-ids API.
+// Next is the continuation token to get the next page. It is empty at the last page.
+type MemberIDs struct {
+	MemberIDs []string `json:"memberIds"`
+	Next      string   `json:"next,omitempty"`
+}
+
+// MemberProfile is the response json struct of get-group-member-profile and get-room-member-profile API
+type MemberProfile struct {
+	UserID      string `json:"userId"`
+	DisplayName string `json:"displayName"`
+	PictureURL  string `json:"pictureUrl,omitempty"`
+}
+
+type memberCount struct {
+	Count int `json:"count"`
+}
+
+// GetGroupSummary is a function to call get-group-summary API
+// https://developers.line.biz/ja/reference/messaging-api/#get-group-summary
+func (c *Client) GetGroupSummary(ctx context.Context, groupID string) (*GroupSummary, error) {
+	// Check paramaters
+	if groupID == "" {
+		return nil, errors.New("group ID not found")
+	}
+
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(urlGroupSummary, url.PathEscape(groupID)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	s := &GroupSummary{}
+	if err := c.doRequestGetBody(req, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetGroupMemberCount is a function to call get-members-group-count API
+// https://developers.line.biz/ja/reference/messaging-api/#get-members-group-count
+func (c *Client) GetGroupMemberCount(ctx context.Context, groupID string) (int, error) {
+	if groupID == "" {
+		return 0, errors.New("group ID not found")
+	}
+	return c.getMemberCount(ctx, fmt.Sprintf(urlGroupMemberCount, url.PathEscape(groupID)))
+}
+
+// GetGroupMemberIDs is a function to call get-group-member-user-ids API.
+// Pass the continuation token returned as MemberIDs.Next to "start" to get the next page, or empty for the first page.
+// https://developers.line.biz/ja/reference/messaging-api/#get-group-member-user-ids
+func (c *Client) GetGroupMemberIDs(ctx context.Context, groupID, start string) (*MemberIDs, error) {
+	if groupID == "" {
+		return nil, errors.New("group ID not found")
+	}
+	return c.getMemberIDs(ctx, fmt.Sprintf(urlGroupMemberIDs, url.PathEscape(groupID)), start)
+}
+
+// GetGroupMemberProfile is a function to call get-group-member-profile API
+// https://developers.line.biz/ja/reference/messaging-api/#get-group-member-profile
+func (c *Client) GetGroupMemberProfile(ctx context.Context, groupID, userID string) (*MemberProfile, error) {
+	if groupID == "" {
+		return nil, errors.New("group ID not found")
+	}
+	if userID == "" {
+		return nil, errors.New("user ID not found")
+	}
+	return c.getMemberProfile(ctx, fmt.Sprintf(urlGroupMemberProfile, url.PathEscape(groupID), url.PathEscape(userID)))
+}
+
+// GetRoomMemberCount is a function to call get-members-room-count API
+// https://developers.line.biz/ja/reference/messaging-api/#get-members-room-count
+func (c *Client) GetRoomMemberCount(ctx context.Context, roomID string) (int, error) {
+	if roomID == "" {
+		return 0, errors.New("room ID not found")
+	}
+	return c.getMemberCount(ctx, fmt.Sprintf(urlRoomMemberCount, url.PathEscape(roomID)))
+}
+
+// GetRoomMemberIDs is a function to call get-room-member-user-ids API.
+// Pass the continuation token returned as MemberIDs.Next to "start" to get the next page, or empty for the first page.
+// https://developers.line.biz/ja/reference/messaging-api/#get-room-member-user-ids
+func (c *Client) GetRoomMemberIDs(ctx context.Context, roomID, start string) (*MemberIDs, error) {
+	if roomID == "" {
+		return nil, errors.New("room ID not found")
+	}
+	return c.getMemberIDs(ctx, fmt.Sprintf(urlRoomMemberIDs, url.PathEscape(roomID)), start)
+}
+
+// GetRoomMemberProfile is a function to call get-room-member-profile API
+// https://developers.line.biz/ja/reference/messaging-api/#get-room-member-profile
+func (c *Client) GetRoomMemberProfile(ctx context.Context, roomID, userID string) (*MemberProfile, error) {
+	if roomID == "" {
+		return nil, errors.New("room ID not found")
+	}
+	if userID == "" {
+		return nil, errors.New("user ID not found")
+	}
+	return c.getMemberProfile(ctx, fmt.Sprintf(urlRoomMemberProfile, url.PathEscape(roomID), url.PathEscape(userID)))
+}
+
+func (c *Client) getMemberCount(ctx context.Context, u string) (int, error) {
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	// Do http request and get response body
+	m := &memberCount{}
+	if err := c.doRequestGetBody(req, m); err != nil {
+		return 0, err
+	}
+	return m.Count, nil
+}
+
+func (c *Client) getMemberIDs(ctx context.Context, u, start string) (*MemberIDs, error) {
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if start != "" {
+		params := req.URL.Query()
+		params.Set("start", start)
+		req.URL.RawQuery = params.Encode()
+	}
+
+	// Do http request and get response body
+	m := &MemberIDs{}
+	if err := c.doRequestGetBody(req, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *Client) getMemberProfile(ctx context.Context, u string) (*MemberProfile, error) {
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	p := &MemberProfile{}
+	if err := c.doRequestGetBody(req, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// IterateGroupMemberIDs returns Iterator of the user IDs of group members
+func (c *Client) IterateGroupMemberIDs(groupID string) *Iterator[string] {
+	return NewIterator(func(ctx context.Context, next string) ([]string, string, error) {
+		m, err := c.GetGroupMemberIDs(ctx, groupID, next)
+		if err != nil {
+			return nil, "", err
+		}
+		return m.MemberIDs, m.Next, nil
+	})
+}
+
+// IterateRoomMemberIDs returns Iterator of the user IDs of room members
+func (c *Client) IterateRoomMemberIDs(roomID string) *Iterator[string] {
+	return NewIterator(func(ctx context.Context, next string) ([]string, string, error) {
+		m, err := c.GetRoomMemberIDs(ctx, roomID, next)
+		if err != nil {
+			return nil, "", err
+		}
+		return m.MemberIDs, m.Next, nil
+	})
+}
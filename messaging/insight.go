@@ -0,0 +1,206 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-number-of-delivery-messages
+	urlInsightMessageDelivery = "https://api.line.me/v2/bot/insight/message/delivery"
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-number-of-followers
+	urlInsightFollowers = "https://api.line.me/v2/bot/insight/followers"
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-demographic
+	urlInsightDemographic = "https://api.line.me/v2/bot/insight/demographic"
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-message-event
+	urlInsightMessageEvent = "https://api.line.me/v2/bot/insight/message/event"
+
+	insightDateFormat = "20060102"
+)
+
+// Insight APIs aggregate statistics by the date in JST (UTC+9)
+var jst = time.FixedZone("JST", 9*60*60)
+
+// InsightStatus is the status of aggregation
+type InsightStatus string
+
+const (
+	InsightStatusReady        InsightStatus = "ready"
+	InsightStatusUnready      InsightStatus = "unready"
+	InsightStatusOutOfService InsightStatus = "out_of_service"
+)
+
+// MessageDeliveryStats is the response json struct of get-number-of-delivery-messages API
+// https://developers.line.biz/ja/reference/messaging-api/#get-number-of-delivery-messages
+type MessageDeliveryStats struct {
+	Status          InsightStatus `json:"status"`
+	Broadcast       int64         `json:"broadcast,omitempty"`
+	Targeting       int64         `json:"targeting,omitempty"`
+	AutoResponse    int64         `json:"autoResponse,omitempty"`
+	WelcomeResponse int64         `json:"welcomeResponse,omitempty"`
+	Chat            int64         `json:"chat,omitempty"`
+	APIBroadcast    int64         `json:"apiBroadcast,omitempty"`
+	APIPush         int64         `json:"apiPush,omitempty"`
+	APIMulticast    int64         `json:"apiMulticast,omitempty"`
+	APINarrowcast   int64         `json:"apiNarrowcast,omitempty"`
+	APIReply        int64         `json:"apiReply,omitempty"`
+}
+
+// GetMessageDeliveryStats is a function to call get-number-of-delivery-messages API.
+// The statistics of the date in JST are returned.
+// https://developers.line.biz/ja/reference/messaging-api/#get-number-of-delivery-messages
+func (c *Client) GetMessageDeliveryStats(ctx context.Context, date time.Time) (*MessageDeliveryStats, error) {
+	// Prepare http request
+	req, err := newInsightRequest(ctx, urlInsightMessageDelivery, date)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	s := &MessageDeliveryStats{}
+	if err := c.doRequestGetBody(req, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// FollowerStats is the response json struct of get-number-of-followers API
+// https://developers.line.biz/ja/reference/messaging-api/#get-number-of-followers
+type FollowerStats struct {
+	Status          InsightStatus `json:"status"`
+	Followers       int64         `json:"followers,omitempty"`
+	TargetedReaches int64         `json:"targetedReaches,omitempty"`
+	Blocks          int64         `json:"blocks,omitempty"`
+}
+
+// GetFollowerStats is a function to call get-number-of-followers API.
+// The statistics of the date in JST are returned.
+// https://developers.line.biz/ja/reference/messaging-api/#get-number-of-followers
+func (c *Client) GetFollowerStats(ctx context.Context, date time.Time) (*FollowerStats, error) {
+	// Prepare http request
+	req, err := newInsightRequest(ctx, urlInsightFollowers, date)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	s := &FollowerStats{}
+	if err := c.doRequestGetBody(req, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Demographic is the response json struct of get-demographic API
+// https://developers.line.biz/ja/reference/messaging-api/#get-demographic
+type Demographic struct {
+	Available           bool                            `json:"available"`
+	Genders             []GenderDemographic             `json:"genders"`
+	Ages                []AgeDemographic                `json:"ages"`
+	Areas               []AreaDemographic               `json:"areas"`
+	AppTypes            []AppTypeDemographic            `json:"appTypes"`
+	SubscriptionPeriods []SubscriptionPeriodDemographic `json:"subscriptionPeriods"`
+}
+
+// GenderDemographic is the percentage per gender
+type GenderDemographic struct {
+	Gender     string  `json:"gender"`
+	Percentage float64 `json:"percentage"`
+}
+
+// AgeDemographic is the percentage per age group
+type AgeDemographic struct {
+	Age        string  `json:"age"`
+	Percentage float64 `json:"percentage"`
+}
+
+// AreaDemographic is the percentage per area
+type AreaDemographic struct {
+	Area       string  `json:"area"`
+	Percentage float64 `json:"percentage"`
+}
+
+// AppTypeDemographic is the percentage per OS
+type AppTypeDemographic struct {
+	AppType    string  `json:"appType"`
+	Percentage float64 `json:"percentage"`
+}
+
+// SubscriptionPeriodDemographic is the percentage per period since friend added
+type SubscriptionPeriodDemographic struct {
+	SubscriptionPeriod string  `json:"subscriptionPeriod"`
+	Percentage         float64 `json:"percentage"`
+}
+
+// GetDemographic is a function to call get-demographic API
+// https://developers.line.biz/ja/reference/messaging-api/#get-demographic
+func (c *Client) GetDemographic(ctx context.Context) (*Demographic, error) {
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlInsightDemographic, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	d := &Demographic{}
+	if err := c.doRequestGetBody(req, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// MessageEventStats is the response json struct of get-message-event API.
+// Only overview is parsed. Use the request ID of narrowcast or broadcast.
+// https://developers.line.biz/ja/reference/messaging-api/#get-message-event
+type MessageEventStats struct {
+	Overview MessageEventOverview `json:"overview"`
+}
+
+// MessageEventOverview is the summary of message event statistics
+type MessageEventOverview struct {
+	RequestID                   string `json:"requestId"`
+	Timestamp                   int64  `json:"timestamp"`
+	Delivered                   int64  `json:"delivered"`
+	UniqueImpression            int64  `json:"uniqueImpression"`
+	UniqueClick                 int64  `json:"uniqueClick"`
+	UniqueMediaPlayed           int64  `json:"uniqueMediaPlayed"`
+	UniqueMediaPlayed100Percent int64  `json:"uniqueMediaPlayed100Percent"`
+}
+
+// GetMessageEventStats is a function to call get-message-event API
+// https://developers.line.biz/ja/reference/messaging-api/#get-message-event
+func (c *Client) GetMessageEventStats(ctx context.Context, requestID string) (*MessageEventStats, error) {
+	// Check paramaters
+	if requestID == "" {
+		return nil, errors.New("request ID not found")
+	}
+
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlInsightMessageEvent, nil)
+	if err != nil {
+		return nil, err
+	}
+	params := req.URL.Query()
+	params.Add("requestId", requestID)
+	req.URL.RawQuery = params.Encode()
+
+	// Do http request and get response body
+	s := &MessageEventStats{}
+	if err := c.doRequestGetBody(req, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func newInsightRequest(ctx context.Context, url string, date time.Time) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	params := req.URL.Query()
+	params.Add("date", date.In(jst).Format(insightDateFormat))
+	req.URL.RawQuery = params.Encode()
+	return req, nil
+}
@@ -0,0 +1,79 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrIteratorDone is returned by Iterator.Next when no more items
+	ErrIteratorDone = errors.New("no more items in iterator")
+)
+
+// PageFunc fetches a page of items. "next" is the continuation token of the page, which is empty for the first page.
+// It returns the continuation token of the next page, or empty at the last page.
+type PageFunc[T any] func(ctx context.Context, next string) (items []T, nextToken string, err error)
+
+// Iterator iterates items of the APIs paginated by "next" continuation tokens
+//
+//	it := c.IterateGroupMemberIDs(groupID)
+//	for {
+//		id, err := it.Next(ctx)
+//		if errors.Is(err, messaging.ErrIteratorDone) {
+//			break
+//		}
+//		if err != nil {
+//			return err
+//		}
+//	}
+type Iterator[T any] struct {
+	fetch PageFunc[T]
+	buf   []T
+	next  string
+	last  bool
+	err   error
+}
+
+// NewIterator returns new Iterator fetching pages by fetch
+func NewIterator[T any](fetch PageFunc[T]) *Iterator[T] {
+	return &Iterator[T]{fetch: fetch}
+}
+
+// Next returns the next item. ErrIteratorDone is returned when no more items.
+// Once an error is returned, the same error is returned on later calls.
+func (it *Iterator[T]) Next(ctx context.Context) (T, error) {
+	var zero T
+	for len(it.buf) == 0 {
+		if it.err != nil {
+			return zero, it.err
+		}
+		if it.last {
+			it.err = ErrIteratorDone
+			return zero, it.err
+		}
+		items, next, err := it.fetch(ctx, it.next)
+		if err != nil {
+			it.err = err
+			return zero, err
+		}
+		it.buf, it.next, it.last = items, next, next == ""
+	}
+	v := it.buf[0]
+	it.buf = it.buf[1:]
+	return v, nil
+}
+
+// All returns all the remaining items
+func (it *Iterator[T]) All(ctx context.Context) ([]T, error) {
+	var all []T
+	for {
+		v, err := it.Next(ctx)
+		if errors.Is(err, ErrIteratorDone) {
+			return all, nil
+		}
+		if err != nil {
+			return all, err
+		}
+		all = append(all, v)
+	}
+}
@@ -0,0 +1,176 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/jlandowner/goline"
+)
+
+const (
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-a-users-membership-subscription-status
+	urlMembershipSubscription = "https://api.line.me/membership/v1/subscription/%s"
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-membership-plans
+	urlMembershipPlans = "https://api.line.me/membership/v1/list"
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-membership-user-ids
+	urlMembershipUserIDs = "https://api.line.me/membership/v1/members/%d/users/ids"
+)
+
+// Membership is the membership plan of LINE Official Account
+type Membership struct {
+	MembershipID    int      `json:"membershipId"`
+	Title           string   `json:"title"`
+	Description     string   `json:"description"`
+	Benefits        []string `json:"benefits"`
+	Price           float64  `json:"price"`
+	Currency        string   `json:"currency"`
+	MemberCount     int      `json:"memberCount,omitempty"`
+	MemberLimit     *int     `json:"memberLimit,omitempty"`
+	IsInAppPurchase bool     `json:"isInAppPurchase,omitempty"`
+	IsPublished     bool     `json:"isPublished,omitempty"`
+}
+
+// MembershipUser is the subscription info of the user
+type MembershipUser struct {
+	MembershipNo            int    `json:"membershipNo"`
+	JoinedTime              int64  `json:"joinedTime"`
+	NextBillingDate         string `json:"nextBillingDate"`
+	TotalSubscriptionMonths int    `json:"totalSubscriptionMonths"`
+}
+
+// Subscription is the membership subscription of the user
+type Subscription struct {
+	Membership Membership     `json:"membership"`
+	User       MembershipUser `json:"user"`
+}
+
+// MembershipUserIDs is the response json struct of get-membership-user-ids API.
+// Next is the continuation token to get the next page. It is empty at the last page.
+type MembershipUserIDs struct {
+	UserIDs []string `json:"userIds"`
+	Next    string   `json:"next,omitempty"`
+}
+
+// GetMembershipSubscription is a function to call get-a-users-membership-subscription-status API.
+// It returns the memberships the user subscribes to, or empty when the user subscribes to none.
+// https://developers.line.biz/ja/reference/messaging-api/#get-a-users-membership-subscription-status
+func (c *Client) GetMembershipSubscription(ctx context.Context, userID string) ([]Subscription, error) {
+	// Check paramaters
+	if userID == "" {
+		return nil, errors.New("user ID not found")
+	}
+
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(urlMembershipSubscription, url.PathEscape(userID)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	res := struct {
+		Subscriptions []Subscription `json:"subscriptions"`
+	}{}
+	if err := c.doRequestGetBody(req, &res); err != nil {
+		// The API responds 404 when the user has no subscription
+		if isNotFound(err) {
+			return []Subscription{}, nil
+		}
+		return nil, err
+	}
+	return res.Subscriptions, nil
+}
+
+// GetMembershipPlans is a function to call get-membership-plans API
+// https://developers.line.biz/ja/reference/messaging-api/#get-membership-plans
+func (c *Client) GetMembershipPlans(ctx context.Context) ([]Membership, error) {
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlMembershipPlans, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	res := struct {
+		Memberships []Membership `json:"memberships"`
+	}{}
+	if err := c.doRequestGetBody(req, &res); err != nil {
+		return nil, err
+	}
+	return res.Memberships, nil
+}
+
+// GetMembershipUserIDs is a function to call get-membership-user-ids API.
+// Pass the continuation token returned as MembershipUserIDs.Next to "start" to get the next page, or empty for the first page.
+// https://developers.line.biz/ja/reference/messaging-api/#get-membership-user-ids
+func (c *Client) GetMembershipUserIDs(ctx context.Context, membershipID int, start string) (*MembershipUserIDs, error) {
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(urlMembershipUserIDs, membershipID), nil)
+	if err != nil {
+		return nil, err
+	}
+	if start != "" {
+		params := req.URL.Query()
+		params.Set("start", start)
+		req.URL.RawQuery = params.Encode()
+	}
+
+	// Do http request and get response body
+	m := &MembershipUserIDs{}
+	if err := c.doRequestGetBody(req, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// IterateMembershipUserIDs returns Iterator of the user IDs of the members of the membership plan
+func (c *Client) IterateMembershipUserIDs(membershipID int) *Iterator[string] {
+	return NewIterator(func(ctx context.Context, next string) ([]string, string, error) {
+		m, err := c.GetMembershipUserIDs(ctx, membershipID, next)
+		if err != nil {
+			return nil, "", err
+		}
+		return m.UserIDs, m.Next, nil
+	})
+}
+
+// RequireMembership returns a middleware allowing the LINE users subscribing to any of the membership plans,
+// or any plan when no membershipIDs are given. Use it after the middlewares of goline.Authorizer.
+// It responds 403 Forbidden to non-members, and 502 Bad Gateway when the subscription cannot be checked.
+func (c *Client) RequireMembership(membershipIDs ...int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			u, ok := goline.UserFromContext(r.Context())
+			if !ok {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			subs, err := c.GetMembershipSubscription(r.Context(), u.ID)
+			if err != nil {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			if !subscribes(subs, membershipIDs) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func subscribes(subs []Subscription, membershipIDs []int) bool {
+	if len(membershipIDs) == 0 {
+		return len(subs) > 0
+	}
+	for _, s := range subs {
+		for _, id := range membershipIDs {
+			if s.Membership.MembershipID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
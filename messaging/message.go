@@ -0,0 +1,91 @@
+package messaging
+
+import "encoding/json"
+
+// MessageType is a type of message object
+type MessageType string
+
+const (
+	MessageTypeText    MessageType = "text"
+	MessageTypeSticker MessageType = "sticker"
+	MessageTypeImage   MessageType = "image"
+	MessageTypeFlex    MessageType = "flex"
+)
+
+// Message is a message object sent by Messaging API.
+// https://developers.line.biz/ja/reference/messaging-api/#message-objects
+type Message interface {
+	Type() MessageType
+}
+
+// TextMessage is a text message object.
+// https://developers.line.biz/ja/reference/messaging-api/#text-message
+type TextMessage struct {
+	Text string
+}
+
+// Type implements Message
+func (m *TextMessage) Type() MessageType {
+	return MessageTypeText
+}
+
+// MarshalJSON implements json.Marshaler
+func (m *TextMessage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type MessageType `json:"type"`
+		Text string      `json:"text"`
+	}{
+		Type: m.Type(),
+		Text: m.Text,
+	})
+}
+
+// StickerMessage is a sticker message object.
+// https://developers.line.biz/ja/reference/messaging-api/#sticker-message
+type StickerMessage struct {
+	PackageID string
+	StickerID string
+}
+
+// Type implements Message
+func (m *StickerMessage) Type() MessageType {
+	return MessageTypeSticker
+}
+
+// MarshalJSON implements json.Marshaler
+func (m *StickerMessage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type      MessageType `json:"type"`
+		PackageID string      `json:"packageId"`
+		StickerID string      `json:"stickerId"`
+	}{
+		Type:      m.Type(),
+		PackageID: m.PackageID,
+		StickerID: m.StickerID,
+	})
+}
+
+// ImageMessage is an image message object.
+// https://developers.line.biz/ja/reference/messaging-api/#image-message
+type ImageMessage struct {
+	OriginalContentURL string
+	PreviewImageURL    string
+}
+
+// Type implements Message
+func (m *ImageMessage) Type() MessageType {
+	return MessageTypeImage
+}
+
+// MarshalJSON implements json.Marshaler
+func (m *ImageMessage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type               MessageType `json:"type"`
+		OriginalContentURL string      `json:"originalContentUrl"`
+		PreviewImageURL    string      `json:"previewImageUrl"`
+	}{
+		Type:               m.Type(),
+		OriginalContentURL: m.OriginalContentURL,
+		PreviewImageURL:    m.PreviewImageURL,
+	})
+}
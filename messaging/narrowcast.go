@@ -0,0 +1,403 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// See https://developers.line.biz/ja/reference/messaging-api/#send-narrowcast-message
+	urlNarrowcast = "https://api.line.me/v2/bot/message/narrowcast"
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-narrowcast-progress-status
+	urlNarrowcastProgress = "https://api.line.me/v2/bot/message/progress/narrowcast"
+
+	headerRequestID = "X-Line-Request-Id"
+
+	defaultPollInterval = 10 * time.Second
+)
+
+// NarrowcastPhase is the phase of narrowcast progress
+type NarrowcastPhase string
+
+const (
+	NarrowcastPhaseWaiting   NarrowcastPhase = "waiting"
+	NarrowcastPhaseSending   NarrowcastPhase = "sending"
+	NarrowcastPhaseSucceeded NarrowcastPhase = "succeeded"
+	NarrowcastPhaseFailed    NarrowcastPhase = "failed"
+)
+
+// NarrowcastRequest is the request json struct of send-narrowcast-message API.
+// Recipient and Filter are optional. When both are empty, messages are sent to all friends.
+// https://developers.line.biz/ja/reference/messaging-api/#send-narrowcast-message
+type NarrowcastRequest struct {
+	Messages             []Message         `json:"messages"`
+	Recipient            Recipient         `json:"recipient,omitempty"`
+	Filter               *NarrowcastFilter `json:"filter,omitempty"`
+	Limit                *NarrowcastLimit  `json:"limit,omitempty"`
+	NotificationDisabled bool              `json:"notificationDisabled,omitempty"`
+}
+
+// NarrowcastFilter is the demographic filter of narrowcast
+type NarrowcastFilter struct {
+	Demographic DemographicFilter `json:"demographic"`
+}
+
+// NarrowcastLimit is the maximum number of narrowcast recipients
+type NarrowcastLimit struct {
+	Max                int  `json:"max,omitempty"`
+	UpToRemainingQuota bool `json:"upToRemainingQuota,omitempty"`
+}
+
+// Narrowcast is a function to call send-narrowcast-message API. It returns the request ID to get the progress.
+// X-Line-Retry-Key header is sent with the key set by WithRetryKey or a new key.
+// When the request with the same key has already been accepted, the request ID accepted before is returned.
+// https://developers.line.biz/ja/reference/messaging-api/#send-narrowcast-message
+func (c *Client) Narrowcast(ctx context.Context, narrowcast *NarrowcastRequest) (string, error) {
+	// Check paramaters
+	if narrowcast == nil {
+		return "", errors.New("narrowcast request is nil")
+	}
+	if len(narrowcast.Messages) == 0 || len(narrowcast.Messages) > maxMessages {
+		return "", fmt.Errorf("number of messages must be 1 to %d: got %d", maxMessages, len(narrowcast.Messages))
+	}
+
+	// Prepare http request
+	req, err := mutating(c.newJSONRequest(ctx, http.MethodPost, urlNarrowcast, narrowcast))
+	if err != nil {
+		return "", err
+	}
+	setRetryKey(req)
+
+	// Do http request and get request ID
+	h, err := c.doRequest(req, nil)
+	if err != nil {
+		if id, ok := AcceptedRequestID(err); ok {
+			return id, nil
+		}
+		return "", err
+	}
+	return h.Get(headerRequestID), nil
+}
+
+// NarrowcastProgress is the response json struct of get-narrowcast-progress-status API
+// https://developers.line.biz/ja/reference/messaging-api/#get-narrowcast-progress-status
+type NarrowcastProgress struct {
+	Phase             NarrowcastPhase `json:"phase"`
+	SuccessCount      int64           `json:"successCount,omitempty"`
+	FailureCount      int64           `json:"failureCount,omitempty"`
+	TargetCount       int64           `json:"targetCount,omitempty"`
+	FailedDescription string          `json:"failedDescription,omitempty"`
+	ErrorCode         int             `json:"errorCode,omitempty"`
+	AcceptedTime      string          `json:"acceptedTime"`
+	CompletedTime     string          `json:"completedTime,omitempty"`
+}
+
+// Done reports whether the narrowcast is completed successfully or not
+func (p *NarrowcastProgress) Done() bool {
+	return p.Phase == NarrowcastPhaseSucceeded || p.Phase == NarrowcastPhaseFailed
+}
+
+// GetNarrowcastProgress is a function to call get-narrowcast-progress-status API
+// https://developers.line.biz/ja/reference/messaging-api/#get-narrowcast-progress-status
+func (c *Client) GetNarrowcastProgress(ctx context.Context, requestID string) (*NarrowcastProgress, error) {
+	// Check paramaters
+	if requestID == "" {
+		return nil, errors.New("request ID not found")
+	}
+
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlNarrowcastProgress, nil)
+	if err != nil {
+		return nil, err
+	}
+	params := req.URL.Query()
+	params.Add("requestId", requestID)
+	req.URL.RawQuery = params.Encode()
+
+	// Do http request and get response body
+	p := &NarrowcastProgress{}
+	if err := c.doRequestGetBody(req, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// WaitNarrowcast polls the narrowcast progress until it is completed and returns the last progress.
+// interval is the polling interval. Default 10 seconds is used when it is zero.
+func (c *Client) WaitNarrowcast(ctx context.Context, requestID string, interval time.Duration) (*NarrowcastProgress, error) {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		p, err := c.GetNarrowcastProgress(ctx, requestID)
+		if err != nil {
+			return nil, err
+		}
+		if p.Done() {
+			return p, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return p, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Recipient is a recipient object of narrowcast.
+// Implemented by *AudienceRecipient, *RedeliveryRecipient and *RecipientOperator.
+// https://developers.line.biz/ja/reference/messaging-api/#narrowcast-recipient
+type Recipient interface {
+	recipient()
+}
+
+// AudienceRecipient specifies the recipients by audience group
+type AudienceRecipient struct {
+	AudienceGroupID int64
+}
+
+func (*AudienceRecipient) recipient() {}
+
+// MarshalJSON implements json.Marshaler
+func (r *AudienceRecipient) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type            string `json:"type"`
+		AudienceGroupID int64  `json:"audienceGroupId"`
+	}{
+		Type:            "audience",
+		AudienceGroupID: r.AudienceGroupID,
+	})
+}
+
+// RedeliveryRecipient specifies the recipients of the past narrowcast request
+type RedeliveryRecipient struct {
+	RequestID string
+}
+
+func (*RedeliveryRecipient) recipient() {}
+
+// MarshalJSON implements json.Marshaler
+func (r *RedeliveryRecipient) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type      string `json:"type"`
+		RequestID string `json:"requestId"`
+	}{
+		Type:      "redelivery",
+		RequestID: r.RequestID,
+	})
+}
+
+// RecipientOperator combines recipients with and, or, not operators.
+// Use RecipientAnd, RecipientOr and RecipientNot to create it.
+type RecipientOperator struct {
+	and []Recipient
+	or  []Recipient
+	not Recipient
+}
+
+// RecipientAnd returns operator matching all of the recipients
+func RecipientAnd(r ...Recipient) *RecipientOperator {
+	return &RecipientOperator{and: r}
+}
+
+// RecipientOr returns operator matching any of the recipients
+func RecipientOr(r ...Recipient) *RecipientOperator {
+	return &RecipientOperator{or: r}
+}
+
+// RecipientNot returns operator matching except the recipient
+func RecipientNot(r Recipient) *RecipientOperator {
+	return &RecipientOperator{not: r}
+}
+
+func (*RecipientOperator) recipient() {}
+
+// MarshalJSON implements json.Marshaler
+func (r *RecipientOperator) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type string      `json:"type"`
+		And  []Recipient `json:"and,omitempty"`
+		Or   []Recipient `json:"or,omitempty"`
+		Not  Recipient   `json:"not,omitempty"`
+	}{
+		Type: "operator",
+		And:  r.and,
+		Or:   r.or,
+		Not:  r.not,
+	})
+}
+
+// DemographicFilter is a demographic filter object of narrowcast.
+// Implemented by *GenderFilter, *AgeFilter, *AreaFilter, *AppTypeFilter, *SubscriptionPeriodFilter and *DemographicOperator.
+// https://developers.line.biz/ja/reference/messaging-api/#narrowcast-demographic-filter
+type DemographicFilter interface {
+	demographicFilter()
+}
+
+// Gender is a gender of GenderFilter
+type Gender string
+
+const (
+	GenderMale   Gender = "male"
+	GenderFemale Gender = "female"
+)
+
+// GenderFilter filters by gender
+type GenderFilter struct {
+	OneOf []Gender
+}
+
+func (*GenderFilter) demographicFilter() {}
+
+// MarshalJSON implements json.Marshaler
+func (f *GenderFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type  string   `json:"type"`
+		OneOf []Gender `json:"oneOf"`
+	}{
+		Type:  "gender",
+		OneOf: f.OneOf,
+	})
+}
+
+// Age is an age boundary of AgeFilter like "age_20"
+type Age string
+
+const (
+	Age15 Age = "age_15"
+	Age20 Age = "age_20"
+	Age25 Age = "age_25"
+	Age30 Age = "age_30"
+	Age35 Age = "age_35"
+	Age40 Age = "age_40"
+	Age45 Age = "age_45"
+	Age50 Age = "age_50"
+)
+
+// AgeFilter filters by age. Gte is inclusive and Lt is exclusive. Either can be empty.
+type AgeFilter struct {
+	Gte Age
+	Lt  Age
+}
+
+func (*AgeFilter) demographicFilter() {}
+
+// MarshalJSON implements json.Marshaler
+func (f *AgeFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		Gte  Age    `json:"gte,omitempty"`
+		Lt   Age    `json:"lt,omitempty"`
+	}{
+		Type: "age",
+		Gte:  f.Gte,
+		Lt:   f.Lt,
+	})
+}
+
+// AreaFilter filters by area code like "jp_13" (Tokyo)
+// https://developers.line.biz/ja/reference/messaging-api/#area-demographic-filter
+type AreaFilter struct {
+	OneOf []string
+}
+
+func (*AreaFilter) demographicFilter() {}
+
+// MarshalJSON implements json.Marshaler
+func (f *AreaFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type  string   `json:"type"`
+		OneOf []string `json:"oneOf"`
+	}{
+		Type:  "area",
+		OneOf: f.OneOf,
+	})
+}
+
+// AppTypeFilter filters by OS type "ios" or "android"
+type AppTypeFilter struct {
+	OneOf []string
+}
+
+func (*AppTypeFilter) demographicFilter() {}
+
+// MarshalJSON implements json.Marshaler
+func (f *AppTypeFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type  string   `json:"type"`
+		OneOf []string `json:"oneOf"`
+	}{
+		Type:  "appType",
+		OneOf: f.OneOf,
+	})
+}
+
+// SubscriptionPeriodFilter filters by the period since friend added like "day_7".
+// Gte is inclusive and Lt is exclusive. Either can be empty.
+type SubscriptionPeriodFilter struct {
+	Gte string
+	Lt  string
+}
+
+func (*SubscriptionPeriodFilter) demographicFilter() {}
+
+// MarshalJSON implements json.Marshaler
+func (f *SubscriptionPeriodFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		Gte  string `json:"gte,omitempty"`
+		Lt   string `json:"lt,omitempty"`
+	}{
+		Type: "subscriptionPeriod",
+		Gte:  f.Gte,
+		Lt:   f.Lt,
+	})
+}
+
+// DemographicOperator combines demographic filters with and, or, not operators.
+// Use DemographicAnd, DemographicOr and DemographicNot to create it.
+type DemographicOperator struct {
+	and []DemographicFilter
+	or  []DemographicFilter
+	not DemographicFilter
+}
+
+// DemographicAnd returns operator matching all of the filters
+func DemographicAnd(f ...DemographicFilter) *DemographicOperator {
+	return &DemographicOperator{and: f}
+}
+
+// DemographicOr returns operator matching any of the filters
+func DemographicOr(f ...DemographicFilter) *DemographicOperator {
+	return &DemographicOperator{or: f}
+}
+
+// DemographicNot returns operator matching except the filter
+func DemographicNot(f DemographicFilter) *DemographicOperator {
+	return &DemographicOperator{not: f}
+}
+
+func (*DemographicOperator) demographicFilter() {}
+
+// MarshalJSON implements json.Marshaler
+func (f *DemographicOperator) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type string              `json:"type"`
+		And  []DemographicFilter `json:"and,omitempty"`
+		Or   []DemographicFilter `json:"or,omitempty"`
+		Not  DemographicFilter   `json:"not,omitempty"`
+	}{
+		Type: "operator",
+		And:  f.and,
+		Or:   f.or,
+		Not:  f.not,
+	})
+}
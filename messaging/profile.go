@@ -0,0 +1,109 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+const (
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-profile
+	urlProfile = "https://api.line.me/v2/bot/profile/%s"
+
+	// Default number of concurrent API calls of GetProfiles
+	defaultProfileConcurrency = 10
+)
+
+// Profile is the response json struct of get-profile API
+// https://developers.line.biz/ja/reference/messaging-api/#get-profile
+type Profile struct {
+	UserID        string `json:"userId"`
+	DisplayName   string `json:"displayName"`
+	PictureURL    string `json:"pictureUrl,omitempty"`
+	StatusMessage string `json:"statusMessage,omitempty"`
+	Language      string `json:"language,omitempty"`
+}
+
+// ProfileResult is the result of GetProfiles for each user ID
+type ProfileResult struct {
+	Profile *Profile
+	Err     error
+}
+
+// GetProfile is a function to call get-profile API with the channel access token.
+// Unlike goline.Client.GetProfile of LINE Login, it needs only the user ID e.g. provided by webhook events
+// and no access token of the user. Only the profiles of the users who added the LINE Official Account
+// as a friend, or the users in the groups and rooms the bot is in, are available.
+// https://developers.line.biz/ja/reference/messaging-api/#get-profile
+func (c *Client) GetProfile(ctx context.Context, userID string) (*Profile, error) {
+	// Check paramaters
+	if userID == "" {
+		return nil, errors.New("user ID not found")
+	}
+
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(urlProfile, url.PathEscape(userID)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	p := &Profile{}
+	if err := c.doRequestGetBody(req, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// GetProfiles calls get-profile API for each user ID with bounded concurrency.
+// The results are in the same order as the user IDs.
+// "concurrency" is the maximum number of concurrent API calls, 10 when 0 or less.
+// Keep it low enough not to exceed the rate limit of the API.
+func (c *Client) GetProfiles(ctx context.Context, userIDs []string, concurrency int) []ProfileResult {
+	if concurrency <= 0 {
+		concurrency = defaultProfileConcurrency
+	}
+	results := make([]ProfileResult, len(userIDs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, id := range userIDs {
+		if err := ctx.Err(); err != nil {
+			results[i] = ProfileResult{Err: err}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			results[i] = ProfileResult{Err: ctx.Err()}
+			continue
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(i int, id string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			p, err := c.GetProfile(ctx, id)
+			results[i] = ProfileResult{Profile: p, Err: err}
+		}(i, id)
+	}
+	wg.Wait()
+	return results
+}
+
+// GetProfileByUserID is the same as GetProfile.
+//
+// Deprecated: Use GetProfile.
+func (c *Client) GetProfileByUserID(ctx context.Context, userID string) (*Profile, error) {
+	return c.GetProfile(ctx, userID)
+}
+
+// GetProfilesByUserID is the same as GetProfiles.
+//
+// Deprecated: Use GetProfiles.
+func (c *Client) GetProfilesByUserID(ctx context.Context, userIDs []string, concurrency int) []ProfileResult {
+	return c.GetProfiles(ctx, userIDs, concurrency)
+}
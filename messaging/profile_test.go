@@ -0,0 +1,51 @@
+package messaging
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGetProfiles(t *testing.T) {
+	hc := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		id := strings.TrimPrefix(r.URL.Path, "/v2/bot/profile/")
+		if id == "unknown" {
+			return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(`{"message":"Not found"}`)), Request: r}, nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"userId":"` + id + `","displayName":"name"}`)),
+			Request:    r,
+		}, nil
+	})}
+	c := NewClient("token", hc)
+	ctx := context.Background()
+
+	for name, get := range map[string]func(context.Context, string) (*Profile, error){
+		"GetProfile":         c.GetProfile,
+		"GetProfileByUserID": c.GetProfileByUserID,
+	} {
+		if p, err := get(ctx, "U1"); err != nil || p.UserID != "U1" {
+			t.Errorf("%s() = %+v, %v", name, p, err)
+		}
+		if _, err := get(ctx, ""); err == nil {
+			t.Errorf("%s() with empty user ID, want error", name)
+		}
+	}
+
+	for name, get := range map[string]func(context.Context, []string, int) []ProfileResult{
+		"GetProfiles":         c.GetProfiles,
+		"GetProfilesByUserID": c.GetProfilesByUserID,
+	} {
+		res := get(ctx, []string{"U1", "unknown", "U2"}, 2)
+		if len(res) != 3 || res[0].Profile.UserID != "U1" || res[1].Err == nil || res[2].Profile.UserID != "U2" {
+			t.Errorf("%s() = %+v", name, res)
+		}
+	}
+}
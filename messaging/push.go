@@ -0,0 +1,82 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const (
+	// See https://developers.line.biz/ja/reference/messaging-api/#send-push-message
+	urlPushMessage = "https://api.line.me/v2/bot/message/push"
+	// See https://developers.line.biz/ja/reference/messaging-api/#send-multicast-message
+	urlMulticast = "https://api.line.me/v2/bot/message/multicast"
+
+	// Maximum number of recipients in one multicast request
+	maxMulticastRecipients = 500
+)
+
+// PushMessage is a function to call send-push-message API.
+// "to" is the ID of the user, group or room.
+// X-Line-Retry-Key header is sent with the key set by WithRetryKey or a new key.
+// https://developers.line.biz/ja/reference/messaging-api/#send-push-message
+func (c *Client) PushMessage(ctx context.Context, to string, messages ...Message) error {
+	// Check paramaters
+	if to == "" {
+		return errors.New("recipient not found")
+	}
+	if len(messages) == 0 || len(messages) > maxMessages {
+		return fmt.Errorf("number of messages must be 1 to %d: got %d", maxMessages, len(messages))
+	}
+
+	body := struct {
+		To       string    `json:"to"`
+		Messages []Message `json:"messages"`
+	}{
+		To:       to,
+		Messages: messages,
+	}
+
+	// Prepare http request
+	req, err := mutating(c.newJSONRequest(ctx, http.MethodPost, urlPushMessage, body))
+	if err != nil {
+		return err
+	}
+	setRetryKey(req)
+
+	// Do http request
+	return c.doRequestGetBody(req, nil)
+}
+
+// Multicast is a function to call send-multicast-message API.
+// "to" is the user IDs up to 500. Use BulkPush to send to more users.
+// X-Line-Retry-Key header is sent with the key set by WithRetryKey or a new key.
+// https://developers.line.biz/ja/reference/messaging-api/#send-multicast-message
+func (c *Client) Multicast(ctx context.Context, to []string, messages ...Message) error {
+	// Check paramaters
+	if len(to) == 0 || len(to) > maxMulticastRecipients {
+		return fmt.Errorf("number of recipients must be 1 to %d: got %d", maxMulticastRecipients, len(to))
+	}
+	if len(messages) == 0 || len(messages) > maxMessages {
+		return fmt.Errorf("number of messages must be 1 to %d: got %d", maxMessages, len(messages))
+	}
+
+	body := struct {
+		To       []string  `json:"to"`
+		Messages []Message `json:"messages"`
+	}{
+		To:       to,
+		Messages: messages,
+	}
+
+	// Prepare http request
+	req, err := mutating(c.newJSONRequest(ctx, http.MethodPost, urlMulticast, body))
+	if err != nil {
+		return err
+	}
+	setRetryKey(req)
+
+	// Do http request
+	return c.doRequestGetBody(req, nil)
+}
@@ -0,0 +1,73 @@
+package messaging
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const (
+	// See https://developers.line.biz/ja/reference/messaging-api/#retry-api-request
+	headerRetryKey          = "X-Line-Retry-Key"
+	headerAcceptedRequestID = "X-Line-Accepted-Request-Id"
+)
+
+var (
+	// ErrAlreadyAccepted is returned when the request with the same retry key has already been accepted.
+	// The messages are not sent twice, so the caller can treat it as success.
+	ErrAlreadyAccepted = errors.New("request with the retry key already accepted")
+)
+
+type retryKeyContextKey struct{}
+
+// WithRetryKey returns the context to send X-Line-Retry-Key header with the key in push, multicast and narrowcast APIs.
+// Use the same key when retrying the same request so that the messages are not sent twice.
+// The key must be UUID. When it is not set, a new UUID is generated for each call.
+func WithRetryKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, retryKeyContextKey{}, key)
+}
+
+// NewRetryKey returns a new random key for WithRetryKey
+func NewRetryKey() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	// UUID version 4
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// setRetryKey sets X-Line-Retry-Key header by the key in ctx or a new key
+func setRetryKey(req *http.Request) {
+	key, _ := req.Context().Value(retryKeyContextKey{}).(string)
+	if key == "" {
+		key = NewRetryKey()
+	}
+	req.Header.Set(headerRetryKey, key)
+}
+
+// alreadyAcceptedError is ErrAlreadyAccepted with the request ID accepted before
+type alreadyAcceptedError struct {
+	requestID string
+}
+
+func (e *alreadyAcceptedError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrAlreadyAccepted.Error(), e.requestID)
+}
+
+func (e *alreadyAcceptedError) Is(target error) bool {
+	return target == ErrAlreadyAccepted
+}
+
+// AcceptedRequestID returns the request ID accepted before when err is ErrAlreadyAccepted
+func AcceptedRequestID(err error) (string, bool) {
+	var e *alreadyAcceptedError
+	if errors.As(err, &e) {
+		return e.requestID, true
+	}
+	return "", false
+}
@@ -0,0 +1,275 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+const (
+	// See https://developers.line.biz/ja/reference/messaging-api/#create-rich-menu
+	urlRichMenu = "https://api.line.me/v2/bot/richmenu"
+	// See https://developers.line.biz/ja/reference/messaging-api/#get-rich-menu-list
+	urlRichMenuList = "https://api.line.me/v2/bot/richmenu/list"
+	// See https://developers.line.biz/ja/reference/messaging-api/#upload-rich-menu-image
+	urlRichMenuContent = "https://api-data.line.me/v2/bot/richmenu/%s/content"
+	// See https://developers.line.biz/ja/reference/messaging-api/#link-rich-menu-to-user
+	urlUserRichMenu = "https://api.line.me/v2/bot/user/%s/richmenu"
+	// See https://developers.line.biz/ja/reference/messaging-api/#set-default-rich-menu
+	urlDefaultRichMenu = "https://api.line.me/v2/bot/user/all/richmenu"
+)
+
+// RichMenu is a rich menu object.
+// https://developers.line.biz/ja/reference/messaging-api/#rich-menu-object
+type RichMenu struct {
+	Size        RichMenuSize   `json:"size"`
+	Selected    bool           `json:"selected"`
+	Name        string         `json:"name"`
+	ChatBarText string         `json:"chatBarText"`
+	Areas       []RichMenuArea `json:"areas"`
+}
+
+// RichMenuSize is the size of rich menu image
+type RichMenuSize struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// RichMenuArea is a tappable area of rich menu
+type RichMenuArea struct {
+	Bounds RichMenuBounds `json:"bounds"`
+	Action Action         `json:"action"`
+}
+
+// RichMenuBounds is the bounds of RichMenuArea
+type RichMenuBounds struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (a *RichMenuArea) UnmarshalJSON(b []byte) error {
+	v := struct {
+		Bounds RichMenuBounds  `json:"bounds"`
+		Action json.RawMessage `json:"action"`
+	}{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	action, err := unmarshalAction(v.Action)
+	if err != nil {
+		return err
+	}
+	a.Bounds = v.Bounds
+	a.Action = action
+	return nil
+}
+
+// RichMenuResponse is the response json struct of get-rich-menu API
+// https://developers.line.biz/ja/reference/messaging-api/#get-rich-menu
+type RichMenuResponse struct {
+	RichMenuID string `json:"richMenuId"`
+	RichMenu
+}
+
+// CreateRichMenu is a function to call create-rich-menu API. It returns the created rich menu ID.
+// https://developers.line.biz/ja/reference/messaging-api/#create-rich-menu
+func (c *Client) CreateRichMenu(ctx context.Context, richMenu *RichMenu) (string, error) {
+	// Check paramaters
+	if richMenu == nil {
+		return "", errors.New("rich menu is nil")
+	}
+
+	// Prepare http request
+	req, err := mutating(c.newJSONRequest(ctx, http.MethodPost, urlRichMenu, richMenu))
+	if err != nil {
+		return "", err
+	}
+
+	// Do http request and get response body
+	res := struct {
+		RichMenuID string `json:"richMenuId"`
+	}{}
+	if err := c.doRequestGetBody(req, &res); err != nil {
+		return "", err
+	}
+	return res.RichMenuID, nil
+}
+
+// GetRichMenu is a function to call get-rich-menu API
+// https://developers.line.biz/ja/reference/messaging-api/#get-rich-menu
+func (c *Client) GetRichMenu(ctx context.Context, richMenuID string) (*RichMenuResponse, error) {
+	// Check paramaters
+	if richMenuID == "" {
+		return nil, errors.New("rich menu ID not found")
+	}
+
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlRichMenu+"/"+url.PathEscape(richMenuID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	res := &RichMenuResponse{}
+	if err := c.doRequestGetBody(req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetRichMenuList is a function to call get-rich-menu-list API
+// https://developers.line.biz/ja/reference/messaging-api/#get-rich-menu-list
+func (c *Client) GetRichMenuList(ctx context.Context) ([]*RichMenuResponse, error) {
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlRichMenuList, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	res := struct {
+		RichMenus []*RichMenuResponse `json:"richmenus"`
+	}{}
+	if err := c.doRequestGetBody(req, &res); err != nil {
+		return nil, err
+	}
+	return res.RichMenus, nil
+}
+
+// DeleteRichMenu is a function to call delete-rich-menu API
+// https://developers.line.biz/ja/reference/messaging-api/#delete-rich-menu
+func (c *Client) DeleteRichMenu(ctx context.Context, richMenuID string) error {
+	// Check paramaters
+	if richMenuID == "" {
+		return errors.New("rich menu ID not found")
+	}
+
+	// Prepare http request
+	req, err := mutating(http.NewRequestWithContext(ctx, http.MethodDelete, urlRichMenu+"/"+url.PathEscape(richMenuID), nil))
+	if err != nil {
+		return err
+	}
+
+	// Do http request
+	return c.doRequestGetBody(req, nil)
+}
+
+// UploadRichMenuImage is a function to call upload-rich-menu-image API.
+// contentType must be "image/jpeg" or "image/png".
+// https://developers.line.biz/ja/reference/messaging-api/#upload-rich-menu-image
+func (c *Client) UploadRichMenuImage(ctx context.Context, richMenuID, contentType string, image io.Reader) error {
+	// Check paramaters
+	if richMenuID == "" {
+		return errors.New("rich menu ID not found")
+	}
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		return fmt.Errorf("content type must be image/jpeg or image/png: got %s", contentType)
+	}
+
+	// Prepare http request
+	req, err := mutating(http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(urlRichMenuContent, url.PathEscape(richMenuID)), image))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	// Do http request
+	return c.doRequestGetBody(req, nil)
+}
+
+// LinkRichMenuToUser is a function to call link-rich-menu-to-user API
+// https://developers.line.biz/ja/reference/messaging-api/#link-rich-menu-to-user
+func (c *Client) LinkRichMenuToUser(ctx context.Context, userID, richMenuID string) error {
+	// Check paramaters
+	if userID == "" {
+		return errors.New("user ID not found")
+	}
+	if richMenuID == "" {
+		return errors.New("rich menu ID not found")
+	}
+
+	// Prepare http request
+	u := fmt.Sprintf(urlUserRichMenu, url.PathEscape(userID)) + "/" + url.PathEscape(richMenuID)
+	req, err := mutating(http.NewRequestWithContext(ctx, http.MethodPost, u, nil))
+	if err != nil {
+		return err
+	}
+
+	// Do http request
+	return c.doRequestGetBody(req, nil)
+}
+
+// UnlinkRichMenuFromUser is a function to call unlink-rich-menu-from-user API
+// https://developers.line.biz/ja/reference/messaging-api/#unlink-rich-menu-from-user
+func (c *Client) UnlinkRichMenuFromUser(ctx context.Context, userID string) error {
+	// Check paramaters
+	if userID == "" {
+		return errors.New("user ID not found")
+	}
+
+	// Prepare http request
+	req, err := mutating(http.NewRequestWithContext(ctx, http.MethodDelete, fmt.Sprintf(urlUserRichMenu, url.PathEscape(userID)), nil))
+	if err != nil {
+		return err
+	}
+
+	// Do http request
+	return c.doRequestGetBody(req, nil)
+}
+
+// SetDefaultRichMenu is a function to call set-default-rich-menu API
+// https://developers.line.biz/ja/reference/messaging-api/#set-default-rich-menu
+func (c *Client) SetDefaultRichMenu(ctx context.Context, richMenuID string) error {
+	// Check paramaters
+	if richMenuID == "" {
+		return errors.New("rich menu ID not found")
+	}
+
+	// Prepare http request
+	req, err := mutating(http.NewRequestWithContext(ctx, http.MethodPost, urlDefaultRichMenu+"/"+url.PathEscape(richMenuID), nil))
+	if err != nil {
+		return err
+	}
+
+	// Do http request
+	return c.doRequestGetBody(req, nil)
+}
+
+// GetDefaultRichMenuID is a function to call get-default-rich-menu-id API
+// https://developers.line.biz/ja/reference/messaging-api/#get-default-rich-menu-id
+func (c *Client) GetDefaultRichMenuID(ctx context.Context) (string, error) {
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlDefaultRichMenu, nil)
+	if err != nil {
+		return "", err
+	}
+
+	// Do http request and get response body
+	res := struct {
+		RichMenuID string `json:"richMenuId"`
+	}{}
+	if err := c.doRequestGetBody(req, &res); err != nil {
+		return "", err
+	}
+	return res.RichMenuID, nil
+}
+
+// CancelDefaultRichMenu is a function to call clear-default-rich-menu API
+// https://developers.line.biz/ja/reference/messaging-api/#clear-default-rich-menu
+func (c *Client) CancelDefaultRichMenu(ctx context.Context) error {
+	// Prepare http request
+	req, err := mutating(http.NewRequestWithContext(ctx, http.MethodDelete, urlDefaultRichMenu, nil))
+	if err != nil {
+		return err
+	}
+
+	// Do http request
+	return c.doRequestGetBody(req, nil)
+}
@@ -0,0 +1,95 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	// See https://developers.line.biz/ja/reference/messaging-api/#set-webhook-endpoint-url
+	urlWebhookEndpoint = "https://api.line.me/v2/bot/channel/webhook/endpoint"
+	// See https://developers.line.biz/ja/reference/messaging-api/#test-webhook-endpoint
+	urlWebhookTest = "https://api.line.me/v2/bot/channel/webhook/test"
+)
+
+// WebhookEndpoint is the response json struct of get-webhook-endpoint-information API
+// https://developers.line.biz/ja/reference/messaging-api/#get-webhook-endpoint-information
+type WebhookEndpoint struct {
+	Endpoint string `json:"endpoint"`
+	// Active is true when the webhook is enabled
+	Active bool `json:"active"`
+}
+
+// WebhookTestResult is the response json struct of test-webhook-endpoint API
+// https://developers.line.biz/ja/reference/messaging-api/#test-webhook-endpoint
+type WebhookTestResult struct {
+	Success    bool      `json:"success"`
+	Timestamp  time.Time `json:"timestamp"`
+	StatusCode int       `json:"statusCode"`
+	Reason     string    `json:"reason"`
+	Detail     string    `json:"detail"`
+}
+
+// SetWebhookEndpoint is a function to call set-webhook-endpoint-url API.
+// The endpoint must be a HTTPS URL.
+// https://developers.line.biz/ja/reference/messaging-api/#set-webhook-endpoint-url
+func (c *Client) SetWebhookEndpoint(ctx context.Context, endpoint string) error {
+	// Check paramaters
+	if endpoint == "" {
+		return errors.New("endpoint not found")
+	}
+
+	// Prepare http request
+	body := struct {
+		Endpoint string `json:"endpoint"`
+	}{Endpoint: endpoint}
+	req, err := mutating(c.newJSONRequest(ctx, http.MethodPut, urlWebhookEndpoint, body))
+	if err != nil {
+		return err
+	}
+
+	// Do http request
+	_, err = c.doRequest(req, nil)
+	return err
+}
+
+// GetWebhookEndpoint is a function to call get-webhook-endpoint-information API
+// https://developers.line.biz/ja/reference/messaging-api/#get-webhook-endpoint-information
+func (c *Client) GetWebhookEndpoint(ctx context.Context) (*WebhookEndpoint, error) {
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlWebhookEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	e := &WebhookEndpoint{}
+	if err := c.doRequestGetBody(req, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// TestWebhookEndpoint is a function to call test-webhook-endpoint API.
+// LINE sends a test webhook event to the endpoint, or to the configured endpoint when it is empty.
+// Check WebhookTestResult.Success, as the API succeeds even when the endpoint fails.
+// https://developers.line.biz/ja/reference/messaging-api/#test-webhook-endpoint
+func (c *Client) TestWebhookEndpoint(ctx context.Context, endpoint string) (*WebhookTestResult, error) {
+	// Prepare http request
+	body := struct {
+		Endpoint string `json:"endpoint,omitempty"`
+	}{Endpoint: endpoint}
+	req, err := c.newJSONRequest(ctx, http.MethodPost, urlWebhookTest, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	r := &WebhookTestResult{}
+	if err := c.doRequestGetBody(req, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,59 @@
+package goline
+
+import (
+	"strconv"
+	"time"
+)
+
+// Names of the metrics emitted to MetricsSink
+const (
+	// MetricAuthDecisions counts the authentication decisions by "decision" and "reason" labels
+	MetricAuthDecisions = "goline_auth_decisions_total"
+	// MetricAuthDuration observes the seconds of the authentication by "decision" label
+	MetricAuthDuration = "goline_auth_duration_seconds"
+	// MetricLINERequests counts the requests to LINE by "path" and "status" labels. The status is empty on network errors.
+	MetricLINERequests = "goline_line_requests_total"
+	// MetricLINERequestDuration observes the seconds of the requests to LINE by "path" label
+	MetricLINERequestDuration = "goline_line_request_duration_seconds"
+)
+
+// MetricsSink receives the metrics of the Client and the Authorizer.
+// Implement it to send the metrics to any backend e.g. StatsD or Datadog.
+// The adapters of Prometheus and OpenTelemetry are in metrics/prometheus and metrics/otelmetrics packages.
+// Implementations must be safe for concurrent use and should not block.
+type MetricsSink interface {
+	// Counter adds the value to the counter
+	Counter(name string, value float64, labels map[string]string)
+	// Observe records the value in the distribution e.g. histogram
+	Observe(name string, value float64, labels map[string]string)
+}
+
+// WithMetrics sets MetricsSink of the Client and the Authorizer using the Client
+func WithMetrics(sink MetricsSink) ClientOption {
+	return func(c *Client) {
+		c.metrics = sink
+	}
+}
+
+// observeDecision emits the metrics of the authentication decision
+func (a *Authorizer) observeDecision(decision AuditDecision, f AuthFailure, d time.Duration) {
+	m := a.lineClient.metrics
+	if m == nil {
+		return
+	}
+	m.Counter(MetricAuthDecisions, 1, map[string]string{"decision": string(decision), "reason": string(f)})
+	m.Observe(MetricAuthDuration, d.Seconds(), map[string]string{"decision": string(decision)})
+}
+
+// observeRequest emits the metrics of the request to LINE
+func (c *Client) observeRequest(path string, status int, d time.Duration) {
+	if c.metrics == nil {
+		return
+	}
+	code := ""
+	if status > 0 {
+		code = strconv.Itoa(status)
+	}
+	c.metrics.Counter(MetricLINERequests, 1, map[string]string{"path": path, "status": code})
+	c.metrics.Observe(MetricLINERequestDuration, d.Seconds(), map[string]string{"path": path})
+}
@@ -0,0 +1,80 @@
+// Package otelmetrics is a goline.MetricsSink recording the metrics by OpenTelemetry metrics API.
+//
+//	sink := otelmetrics.NewSink(otel.Meter("github.com/jlandowner/goline"))
+//	lineClient := goline.NewClient(channelID, http.DefaultClient, goline.WithMetrics(sink))
+package otelmetrics
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Sink is goline.MetricsSink recording the counters by Float64Counter and the distributions by Float64Histogram.
+// The instruments are created on the first record of each metric.
+type Sink struct {
+	meter metric.Meter
+
+	mu         sync.RWMutex
+	counters   map[string]metric.Float64Counter
+	histograms map[string]metric.Float64Histogram
+}
+
+// NewSink returns new Sink recording by the meter
+func NewSink(meter metric.Meter) *Sink {
+	return &Sink{
+		meter:      meter,
+		counters:   make(map[string]metric.Float64Counter),
+		histograms: make(map[string]metric.Float64Histogram),
+	}
+}
+
+// Counter implements goline.MetricsSink
+func (s *Sink) Counter(name string, value float64, labels map[string]string) {
+	s.mu.RLock()
+	c, ok := s.counters[name]
+	s.mu.RUnlock()
+	if !ok {
+		s.mu.Lock()
+		if c, ok = s.counters[name]; !ok {
+			var err error
+			if c, err = s.meter.Float64Counter(name); err != nil {
+				s.mu.Unlock()
+				return
+			}
+			s.counters[name] = c
+		}
+		s.mu.Unlock()
+	}
+	c.Add(context.Background(), value, metric.WithAttributes(attributes(labels)...))
+}
+
+// Observe implements goline.MetricsSink
+func (s *Sink) Observe(name string, value float64, labels map[string]string) {
+	s.mu.RLock()
+	h, ok := s.histograms[name]
+	s.mu.RUnlock()
+	if !ok {
+		s.mu.Lock()
+		if h, ok = s.histograms[name]; !ok {
+			var err error
+			if h, err = s.meter.Float64Histogram(name, metric.WithUnit("s")); err != nil {
+				s.mu.Unlock()
+				return
+			}
+			s.histograms[name] = h
+		}
+		s.mu.Unlock()
+	}
+	h.Record(context.Background(), value, metric.WithAttributes(attributes(labels)...))
+}
+
+func attributes(labels map[string]string) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(labels))
+	for k, v := range labels {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+	return attrs
+}
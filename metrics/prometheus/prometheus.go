@@ -0,0 +1,178 @@
+// Package prometheus is a goline.MetricsSink exposing the metrics in Prometheus text format
+// without depending on the Prometheus client library.
+//
+//	sink := prometheus.NewSink()
+//	lineClient := goline.NewClient(channelID, http.DefaultClient, goline.WithMetrics(sink))
+//	http.Handle("/metrics", sink)
+package prometheus
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets is the default upper bounds of the histogram buckets in seconds
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Sink is goline.MetricsSink aggregating the counters and the histograms in memory.
+// It is http.Handler serving them in Prometheus text exposition format.
+type Sink struct {
+	buckets []float64
+
+	mu         sync.Mutex
+	counters   map[string]map[string]float64
+	histograms map[string]map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// Option configures Sink
+type Option func(*Sink)
+
+// WithBuckets sets the upper bounds of the histogram buckets. Default is DefaultBuckets.
+func WithBuckets(buckets ...float64) Option {
+	return func(s *Sink) {
+		s.buckets = append([]float64(nil), buckets...)
+		sort.Float64s(s.buckets)
+	}
+}
+
+// NewSink returns new Sink
+func NewSink(opts ...Option) *Sink {
+	s := &Sink{
+		buckets:    DefaultBuckets,
+		counters:   make(map[string]map[string]float64),
+		histograms: make(map[string]map[string]*histogram),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Counter implements goline.MetricsSink
+func (s *Sink) Counter(name string, value float64, labels map[string]string) {
+	key := formatLabels(labels)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	series, ok := s.counters[name]
+	if !ok {
+		series = make(map[string]float64)
+		s.counters[name] = series
+	}
+	series[key] += value
+}
+
+// Observe implements goline.MetricsSink
+func (s *Sink) Observe(name string, value float64, labels map[string]string) {
+	key := formatLabels(labels)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	series, ok := s.histograms[name]
+	if !ok {
+		series = make(map[string]*histogram)
+		s.histograms[name] = series
+	}
+	h, ok := series[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(s.buckets))}
+		series[key] = h
+	}
+	for i, b := range s.buckets {
+		if value <= b {
+			h.counts[i]++
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+// ServeHTTP writes the metrics in Prometheus text exposition format
+func (s *Sink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	s.WriteTo(w)
+}
+
+// WriteTo writes the metrics in Prometheus text exposition format
+func (s *Sink) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	s.mu.Lock()
+	for _, name := range sortedKeys(s.counters) {
+		fmt.Fprintf(&b, "# TYPE %s counter\n", name)
+		series := s.counters[name]
+		for _, key := range sortedKeys(series) {
+			fmt.Fprintf(&b, "%s%s %s\n", name, key, formatFloat(series[key]))
+		}
+	}
+	for _, name := range sortedKeys(s.histograms) {
+		fmt.Fprintf(&b, "# TYPE %s histogram\n", name)
+		series := s.histograms[name]
+		for _, key := range sortedKeys(series) {
+			h := series[key]
+			for i, bound := range s.buckets {
+				fmt.Fprintf(&b, "%s_bucket%s %d\n", name, withLabel(key, "le", formatFloat(bound)), h.counts[i])
+			}
+			fmt.Fprintf(&b, "%s_bucket%s %d\n", name, withLabel(key, "le", "+Inf"), h.count)
+			fmt.Fprintf(&b, "%s_sum%s %s\n", name, key, formatFloat(h.sum))
+			fmt.Fprintf(&b, "%s_count%s %d\n", name, key, h.count)
+		}
+	}
+	s.mu.Unlock()
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// formatLabels returns the labels sorted by the names e.g. {decision="allow",reason=""}
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(labels))
+	for _, k := range sortedKeys(labels) {
+		pairs = append(pairs, k+"="+quoteLabel(labels[k]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// withLabel adds the label to the formatted labels
+func withLabel(key, name, value string) string {
+	l := name + "=" + quoteLabel(value)
+	if key == "" {
+		return "{" + l + "}"
+	}
+	return key[:len(key)-1] + "," + l + "}"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// quoteLabel returns the quoted label value escaped by the text exposition format
+func quoteLabel(v string) string {
+	return `"` + labelEscaper.Replace(v) + `"`
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
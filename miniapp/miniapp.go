@@ -0,0 +1,142 @@
+// Package miniapp is a client of LINE MINI App service message API to notify users of the MINI App.
+// https://developers.line.biz/ja/docs/line-mini-app/develop/service-messages/
+package miniapp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/jlandowner/goline"
+)
+
+const (
+	// See https://developers.line.biz/ja/reference/line-mini-app/#issue-notification-token
+	urlNotificationToken = "https://api.line.me/message/v3/notifier/token"
+	// See https://developers.line.biz/ja/reference/line-mini-app/#send-service-message
+	urlSendServiceMessage = "https://api.line.me/message/v3/notifier/send?target=service"
+)
+
+// NotificationToken is the response json struct of issue-notification-token and send-service-message API.
+// The token can be used to send service messages up to RemainingCount times until it expires,
+// and the new token is returned for each sending.
+type NotificationToken struct {
+	NotificationToken string `json:"notificationToken"`
+	// ExpiresIn is the lifetime of the token in seconds
+	ExpiresIn      int    `json:"expiresIn"`
+	RemainingCount int    `json:"remainingCount"`
+	SessionID      string `json:"sessionId"`
+}
+
+// ServiceMessage is the service message sent by the template
+type ServiceMessage struct {
+	// TemplateName is the name of the template with the language e.g. "reservation_confirmation_ja"
+	TemplateName string `json:"templateName"`
+	// Params are the variables of the template
+	Params map[string]string `json:"params"`
+}
+
+// Client is an http client access to LINE MINI App service message API
+type Client struct {
+	channelAccessToken string
+	client             *http.Client
+}
+
+// NewClient returns service message API Client. "channelAccessToken" is the channel access token of the LINE MINI App channel.
+func NewClient(channelAccessToken string, client *http.Client) *Client {
+	return &Client{
+		channelAccessToken: channelAccessToken,
+		client:             client,
+	}
+}
+
+// IssueNotificationToken is a function to call issue-notification-token API.
+// "liffAccessToken" is the access token got by liff.getAccessToken() in the MINI App,
+// which is verified by goline.Authorizer in the backend.
+// https://developers.line.biz/ja/reference/line-mini-app/#issue-notification-token
+func (c *Client) IssueNotificationToken(ctx context.Context, liffAccessToken string) (*NotificationToken, error) {
+	// Check paramaters
+	if liffAccessToken == "" {
+		return nil, errors.New("LIFF access token not found")
+	}
+
+	// Prepare http request
+	req, err := newJSONRequest(ctx, urlNotificationToken, map[string]string{"liffAccessToken": liffAccessToken})
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	t := &NotificationToken{}
+	if err := c.doRequestGetBody(req, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// SendServiceMessage is a function to call send-service-message API.
+// It returns the new notification token to send the next message in the same session.
+// https://developers.line.biz/ja/reference/line-mini-app/#send-service-message
+func (c *Client) SendServiceMessage(ctx context.Context, notificationToken string, m *ServiceMessage) (*NotificationToken, error) {
+	// Check paramaters
+	if notificationToken == "" {
+		return nil, errors.New("notification token not found")
+	}
+	if m == nil || m.TemplateName == "" {
+		return nil, errors.New("template name not found")
+	}
+
+	// Prepare http request
+	params := m.Params
+	if params == nil {
+		params = map[string]string{}
+	}
+	req, err := newJSONRequest(ctx, urlSendServiceMessage, struct {
+		TemplateName      string            `json:"templateName"`
+		Params            map[string]string `json:"params"`
+		NotificationToken string            `json:"notificationToken"`
+	}{m.TemplateName, params, notificationToken})
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	t := &NotificationToken{}
+	if err := c.doRequestGetBody(req, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func newJSONRequest(ctx context.Context, url string, body interface{}) (*http.Request, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+func (c *Client) doRequestGetBody(req *http.Request, resbody interface{}) error {
+	req.Header.Set("Authorization", "Bearer "+c.channelAccessToken)
+
+	// Do http request
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	// Check Status Code
+	// The message of the error response is in *goline.APIError
+	if err := goline.CheckResponse(res); err != nil {
+		return err
+	}
+	return json.NewDecoder(res.Body).Decode(resbody)
+}
@@ -0,0 +1,342 @@
+// Package notify is a client of LINE Notify API including its OAuth flow.
+// https://notify-bot.line.me/doc/ja/
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jlandowner/goline"
+	"github.com/jlandowner/goline/messaging"
+)
+
+const (
+	// See https://notify-bot.line.me/doc/ja/ "GET https://notify-bot.line.me/oauth/authorize"
+	urlAuthorize = "https://notify-bot.line.me/oauth/authorize"
+	// See https://notify-bot.line.me/doc/ja/ "POST https://notify-bot.line.me/oauth/token"
+	urlToken = "https://notify-bot.line.me/oauth/token"
+	// See https://notify-bot.line.me/doc/ja/ "POST https://notify-api.line.me/api/notify"
+	urlNotify = "https://notify-api.line.me/api/notify"
+	// See https://notify-bot.line.me/doc/ja/ "GET https://notify-api.line.me/api/status"
+	urlStatus = "https://notify-api.line.me/api/status"
+	// See https://notify-bot.line.me/doc/ja/ "POST https://notify-api.line.me/api/revoke"
+	urlRevoke = "https://notify-api.line.me/api/revoke"
+
+	// Maximum length of notification message
+	maxMessageLength = 1000
+)
+
+// OAuthClient is an http client of LINE Notify OAuth2 flow to issue access tokens of users
+type OAuthClient struct {
+	clientID     string
+	clientSecret string
+	redirectURI  string
+	client       *http.Client
+}
+
+// NewOAuthClient returns OAuthClient of the LINE Notify service
+func NewOAuthClient(clientID, clientSecret, redirectURI string, client *http.Client) *OAuthClient {
+	return &OAuthClient{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURI:  redirectURI,
+		client:       client,
+	}
+}
+
+// AuthorizeURL returns the URL to redirect users to authorize the notification.
+// "state" must be a random value verified in the callback to prevent CSRF.
+func (c *OAuthClient) AuthorizeURL(state string) string {
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", c.clientID)
+	params.Set("redirect_uri", c.redirectURI)
+	params.Set("scope", "notify")
+	params.Set("state", state)
+	return urlAuthorize + "?" + params.Encode()
+}
+
+// IssueToken is a function to issue the access token by the authorization code received in the callback
+func (c *OAuthClient) IssueToken(ctx context.Context, code string) (string, error) {
+	// Check paramaters
+	if code == "" {
+		return "", errors.New("code not found")
+	}
+
+	// Prepare http request
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.redirectURI)
+	form.Set("client_id", c.clientID)
+	form.Set("client_secret", c.clientSecret)
+	req, err := newFormRequest(ctx, urlToken, form)
+	if err != nil {
+		return "", err
+	}
+
+	// Do http request and get response body
+	res := struct {
+		AccessToken string `json:"access_token"`
+	}{}
+	if _, err := doRequest(c.client, req, &res); err != nil {
+		return "", err
+	}
+	return res.AccessToken, nil
+}
+
+// Client is an http client access to LINE Notify API
+type Client struct {
+	client *http.Client
+	dryRun func(r *messaging.DryRunRequest)
+}
+
+// ClientOption configures Client
+type ClientOption func(*Client)
+
+// WithDryRun short-circuits Notify and Revoke into no-ops same as messaging.WithDryRun.
+// The requests are passed to "record" instead of sending, so that messaging.DryRunQueue can record both clients.
+func WithDryRun(record func(r *messaging.DryRunRequest)) ClientOption {
+	return func(c *Client) {
+		if record == nil {
+			record = func(*messaging.DryRunRequest) {}
+		}
+		c.dryRun = record
+	}
+}
+
+// NewClient returns LINE Notify API Client. Access tokens are passed to each function
+// since they are issued for each user or group.
+func NewClient(client *http.Client, opts ...ClientOption) *Client {
+	c := &Client{client: client}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Notification is a notification sent by Notify.
+// Image and sticker are optional. ImageFile is uploaded when set, prior to ImageThumbnail and ImageFullsize.
+type Notification struct {
+	Message              string
+	ImageThumbnail       string
+	ImageFullsize        string
+	ImageFile            io.Reader
+	ImageFileName        string
+	StickerPackageID     int
+	StickerID            int
+	NotificationDisabled bool
+}
+
+// RateLimit is the rate limit status returned in the response headers of Notify API
+type RateLimit struct {
+	Limit          int
+	Remaining      int
+	ImageLimit     int
+	ImageRemaining int
+	Reset          time.Time
+}
+
+// Notify is a function to send the notification to the user or group of the access token
+func (c *Client) Notify(ctx context.Context, accessToken string, n *Notification) (*RateLimit, error) {
+	// Check paramaters
+	if accessToken == "" {
+		return nil, errors.New("access token not found")
+	}
+	if n == nil || n.Message == "" {
+		return nil, errors.New("message not found")
+	}
+	if len([]rune(n.Message)) > maxMessageLength {
+		return nil, fmt.Errorf("message must be up to %d characters", maxMessageLength)
+	}
+
+	// Prepare http request
+	fields := url.Values{}
+	fields.Set("message", n.Message)
+	if n.ImageThumbnail != "" {
+		fields.Set("imageThumbnail", n.ImageThumbnail)
+	}
+	if n.ImageFullsize != "" {
+		fields.Set("imageFullsize", n.ImageFullsize)
+	}
+	if n.StickerPackageID != 0 && n.StickerID != 0 {
+		fields.Set("stickerPackageId", strconv.Itoa(n.StickerPackageID))
+		fields.Set("stickerId", strconv.Itoa(n.StickerID))
+	}
+	if n.NotificationDisabled {
+		fields.Set("notificationDisabled", "true")
+	}
+
+	var req *http.Request
+	var err error
+	if n.ImageFile != nil {
+		req, err = newMultipartRequest(ctx, urlNotify, fields, n.ImageFileName, n.ImageFile)
+	} else {
+		req, err = newFormRequest(ctx, urlNotify, fields)
+	}
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	// Do http request and get rate limit headers
+	h, err := c.doMutating(req)
+	if err != nil {
+		return nil, err
+	}
+	return parseRateLimit(h), nil
+}
+
+// Status is the response json struct of status API
+type Status struct {
+	Status     int    `json:"status"`
+	Message    string `json:"message"`
+	TargetType string `json:"targetType"`
+	Target     string `json:"target"`
+}
+
+// GetStatus is a function to check the connection status of the access token.
+// goline.ErrUnauthorized is returned when the token is invalid.
+func (c *Client) GetStatus(ctx context.Context, accessToken string) (*Status, error) {
+	// Check paramaters
+	if accessToken == "" {
+		return nil, errors.New("access token not found")
+	}
+
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStatus, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	// Do http request and get response body
+	s := &Status{}
+	if _, err := doRequest(c.client, req, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Revoke is a function to revoke the access token
+func (c *Client) Revoke(ctx context.Context, accessToken string) error {
+	// Check paramaters
+	if accessToken == "" {
+		return errors.New("access token not found")
+	}
+
+	// Prepare http request
+	req, err := newFormRequest(ctx, urlRevoke, url.Values{})
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	// Do http request
+	_, err = c.doMutating(req)
+	return err
+}
+
+// doMutating does the request changing the state of the token, which is recorded instead of sending in dry-run mode
+func (c *Client) doMutating(req *http.Request) (http.Header, error) {
+	if c.dryRun == nil {
+		return doRequest(c.client, req, nil)
+	}
+	r := &messaging.DryRunRequest{Method: req.Method, URL: req.URL.String()}
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = b
+	}
+	c.dryRun(r)
+	return http.Header{}, nil
+}
+
+func newFormRequest(ctx context.Context, url string, form url.Values) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req, nil
+}
+
+func newMultipartRequest(ctx context.Context, url string, fields url.Values, fileName string, file io.Reader) (*http.Request, error) {
+	if fileName == "" {
+		fileName = "image"
+	}
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	for k := range fields {
+		if err := w.WriteField(k, fields.Get(k)); err != nil {
+			return nil, err
+		}
+	}
+	fw, err := w.CreateFormFile("imageFile", fileName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(fw, file); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req, nil
+}
+
+func doRequest(client *http.Client, req *http.Request, resbody interface{}) (http.Header, error) {
+	// Do http request
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	// Check Status Code
+	// The message of the error response is in *goline.APIError
+	if err := goline.CheckResponse(res); err != nil {
+		return nil, err
+	}
+
+	if resbody == nil {
+		return res.Header, nil
+	}
+	return res.Header, json.NewDecoder(res.Body).Decode(resbody)
+}
+
+func parseRateLimit(h http.Header) *RateLimit {
+	atoi := func(k string) int {
+		v, _ := strconv.Atoi(h.Get(k))
+		return v
+	}
+	r := &RateLimit{
+		Limit:          atoi("X-RateLimit-Limit"),
+		Remaining:      atoi("X-RateLimit-Remaining"),
+		ImageLimit:     atoi("X-RateLimit-ImageLimit"),
+		ImageRemaining: atoi("X-RateLimit-ImageRemaining"),
+	}
+	if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		r.Reset = time.Unix(reset, 0)
+	}
+	return r
+}
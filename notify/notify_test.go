@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jlandowner/goline/messaging"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestDryRun(t *testing.T) {
+	ctx := context.Background()
+	hc := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		t.Errorf("request sent in dry-run mode: %s %s", r.Method, r.URL)
+		return nil, errors.New("sent")
+	})}
+	q := &messaging.DryRunQueue{}
+	c := NewClient(hc, WithDryRun(q.Record))
+
+	if _, err := c.Notify(ctx, "token", &Notification{Message: "hi"}); err != nil {
+		t.Errorf("Notify() error = %v", err)
+	}
+	if err := c.Revoke(ctx, "token"); err != nil {
+		t.Errorf("Revoke() error = %v", err)
+	}
+
+	reqs := q.Requests()
+	if len(reqs) != 2 {
+		t.Fatalf("%d requests recorded, want 2", len(reqs))
+	}
+	if reqs[0].URL != urlNotify || string(reqs[0].Body) != "message=hi" {
+		t.Errorf("recorded request = %s %s", reqs[0].URL, reqs[0].Body)
+	}
+	if reqs[1].Method != http.MethodPost || reqs[1].URL != urlRevoke {
+		t.Errorf("recorded request = %s %s", reqs[1].Method, reqs[1].URL)
+	}
+}
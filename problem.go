@@ -0,0 +1,99 @@
+package goline
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// AuthFailure is the reason why the request is not authenticated
+type AuthFailure string
+
+const (
+	// AuthFailureTokenMissing means no bearer token in authorization header
+	AuthFailureTokenMissing AuthFailure = "token-missing"
+	// AuthFailureTokenMalformed means authorization header is not a bearer token
+	AuthFailureTokenMalformed AuthFailure = "token-malformed"
+	// AuthFailureTokenExpired means the token is expired
+	AuthFailureTokenExpired AuthFailure = "token-expired"
+	// AuthFailureTokenRejected means the token is rejected by LINE
+	AuthFailureTokenRejected AuthFailure = "token-rejected"
+	// AuthFailureValidationFailed means the verified token is rejected by Validator
+	AuthFailureValidationFailed AuthFailure = "validation-failed"
+)
+
+var authFailureDetails = map[AuthFailure]string{
+	AuthFailureTokenMissing:     "bearer token not found in authorization header",
+	AuthFailureTokenMalformed:   "authorization header is not a bearer token",
+	AuthFailureTokenExpired:     "token is expired",
+	AuthFailureTokenRejected:    "token is rejected by LINE",
+	AuthFailureValidationFailed: "token is rejected by the application",
+}
+
+// Detail returns the human readable description of the failure
+func (f AuthFailure) Detail() string {
+	return authFailureDetails[f]
+}
+
+// classifyAuthFailure returns the reason of the verification error
+func classifyAuthFailure(err error) AuthFailure {
+	if errors.Is(err, ErrBearerTokenNotFound) {
+		return AuthFailureTokenMissing
+	}
+	if errors.Is(err, ErrBearerTokenMalformed) {
+		return AuthFailureTokenMalformed
+	}
+	if errors.Is(err, ErrTokenExpired) {
+		return AuthFailureTokenExpired
+	}
+	if errors.Is(err, ErrValidationFailed) {
+		return AuthFailureValidationFailed
+	}
+	return AuthFailureTokenRejected
+}
+
+// Problem is the RFC 7807 problem details json written when the request is rejected
+// https://www.rfc-editor.org/rfc/rfc7807
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Errors is the messages of ValidationErrors when the token is rejected by the validators
+	Errors []string `json:"errors,omitempty"`
+}
+
+// WithProblemDetails writes RFC 7807 "application/problem+json" body describing why the request is rejected,
+// instead of an empty 401 Unauthorized. The type is "urn:goline:" + AuthFailure e.g. "urn:goline:token-expired".
+// The messages of all the validators rejecting the token are written in "errors", so write them for the clients.
+func WithProblemDetails() AuthorizerOption {
+	return func(a *Authorizer) {
+		a.problem = true
+	}
+}
+
+// unauthorized writes 401 Unauthorized response with WWW-Authenticate header
+func (a *Authorizer) unauthorized(w http.ResponseWriter, r *http.Request, f AuthFailure, err error) {
+	w.Header().Set("WWW-Authenticate", bearerChallenge(a.realm, f))
+	if !a.problem {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	p := &Problem{
+		Type:     "urn:goline:" + string(f),
+		Title:    http.StatusText(http.StatusUnauthorized),
+		Status:   http.StatusUnauthorized,
+		Detail:   f.Detail(),
+		Instance: r.URL.RequestURI(),
+	}
+	var verrs ValidationErrors
+	if errors.As(err, &verrs) {
+		for _, e := range verrs {
+			p.Errors = append(p.Errors, e.Error())
+		}
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
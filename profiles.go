@@ -0,0 +1,62 @@
+package goline
+
+import (
+	"context"
+	"sync"
+)
+
+const (
+	// Default number of concurrent API calls of the batch functions
+	defaultBatchConcurrency = 10
+)
+
+// ProfileResult is the result of GetProfiles for each access token
+type ProfileResult struct {
+	Profile *LINEProfile
+	Err     error
+}
+
+// GetProfiles calls get-user-profile API for each access token with bounded concurrency,
+// e.g. for back-office tools rendering lists of LINE users. The results are in the same order as the tokens.
+// "concurrency" is the maximum number of concurrent API calls, 10 when 0 or less.
+// The calls not started yet when ctx is done fail with ctx.Err().
+func (c *Client) GetProfiles(ctx context.Context, accessTokens []string, concurrency int) []ProfileResult {
+	results := make([]ProfileResult, len(accessTokens))
+	runBatch(ctx, len(accessTokens), concurrency, func(ctx context.Context, i int) {
+		p, err := c.GetProfile(ctx, accessTokens[i])
+		results[i] = ProfileResult{Profile: p, Err: err}
+	}, func(i int, err error) {
+		results[i] = ProfileResult{Err: err}
+	})
+	return results
+}
+
+// runBatch calls fn for 0 to n-1 with bounded concurrency. cancel is called instead of fn after ctx is done.
+func runBatch(ctx context.Context, n, concurrency int, fn func(ctx context.Context, i int), cancel func(i int, err error)) {
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		if err := ctx.Err(); err != nil {
+			cancel(i, err)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			cancel(i, ctx.Err())
+			continue
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(ctx, i)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,126 @@
+package goline
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter limits requests by key. It returns the duration to wait for the next request when not allowed.
+// Implement it with a shared store such as Redis to limit across multiple instances.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// TokenBucketLimiter is an in-memory token bucket RateLimiter
+type TokenBucketLimiter struct {
+	rate  float64
+	burst float64
+	clock Clock
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// TokenBucketOption configures TokenBucketLimiter
+type TokenBucketOption func(*TokenBucketLimiter)
+
+// WithLimiterClock sets Clock used to refill the buckets. Default is SystemClock.
+func WithLimiterClock(clock Clock) TokenBucketOption {
+	return func(l *TokenBucketLimiter) {
+		l.clock = clock
+	}
+}
+
+// NewTokenBucketLimiter returns new TokenBucketLimiter allowing "rate" requests per second with "burst".
+// "rate" must be positive and "burst" must be 1 or more.
+func NewTokenBucketLimiter(rate float64, burst int, opts ...TokenBucketOption) (*TokenBucketLimiter, error) {
+	if !(rate > 0) || math.IsInf(rate, 1) {
+		return nil, errors.New("rate must be a positive number")
+	}
+	if burst < 1 {
+		return nil, errors.New("burst must be 1 or more")
+	}
+	l := &TokenBucketLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		clock:   SystemClock,
+		buckets: make(map[string]*bucket),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l, nil
+}
+
+// Allow implements RateLimiter
+func (l *TokenBucketLimiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	now := l.clock.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait, nil
+}
+
+// sweep removes buckets which are full again not to grow the map with inactive users
+func (l *TokenBucketLimiter) sweep(now time.Time) {
+	fill := time.Duration(l.burst / l.rate * float64(time.Second))
+	if now.Sub(l.lastSweep) < fill {
+		return
+	}
+	for k, b := range l.buckets {
+		if now.Sub(b.last) >= fill {
+			delete(l.buckets, k)
+		}
+	}
+	l.lastSweep = now
+}
+
+// RateLimitByUser returns a middleware limiting requests by the LINE user ID authenticated by Authorizer.
+// Use it after the middlewares of Authorizer. It responds 429 Too Many Requests with Retry-After header when limited.
+// Requests are allowed when the limiter returns error not to block all users by the failure of the store.
+func RateLimitByUser(l RateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			u, ok := UserFromContext(r.Context())
+			if !ok {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			allowed, retryAfter, err := l.Allow(r.Context(), u.ID)
+			if err == nil && !allowed {
+				if retryAfter > 0 {
+					w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				}
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package goline
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketLimiter(t *testing.T) {
+	type step struct {
+		// advance is the time passed before the request
+		advance        time.Duration
+		key            string
+		wantAllowed    bool
+		wantRetryAfter time.Duration
+	}
+	tests := []struct {
+		name  string
+		rate  float64
+		burst int
+		steps []step
+	}{
+		{
+			name: "burst", rate: 1, burst: 3,
+			steps: []step{
+				{wantAllowed: true},
+				{wantAllowed: true},
+				{wantAllowed: true},
+				{wantAllowed: false, wantRetryAfter: time.Second},
+			},
+		},
+		{
+			name: "refill", rate: 2, burst: 1,
+			steps: []step{
+				{wantAllowed: true},
+				{wantAllowed: false, wantRetryAfter: 500 * time.Millisecond},
+				{advance: 250 * time.Millisecond, wantAllowed: false, wantRetryAfter: 250 * time.Millisecond},
+				{advance: 250 * time.Millisecond, wantAllowed: true},
+			},
+		},
+		{
+			name: "refill up to burst", rate: 1, burst: 2,
+			steps: []step{
+				{wantAllowed: true},
+				{wantAllowed: true},
+				{advance: time.Hour, wantAllowed: true},
+				{wantAllowed: true},
+				{wantAllowed: false, wantRetryAfter: time.Second},
+			},
+		},
+		{
+			name: "by key", rate: 1, burst: 1,
+			steps: []step{
+				{key: "U1", wantAllowed: true},
+				{key: "U1", wantAllowed: false, wantRetryAfter: time.Second},
+				{key: "U2", wantAllowed: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			l, err := NewTokenBucketLimiter(tt.rate, tt.burst, WithLimiterClock(ClockFunc(func() time.Time { return now })))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, s := range tt.steps {
+				now = now.Add(s.advance)
+				key := s.key
+				if key == "" {
+					key = "U1"
+				}
+				allowed, retryAfter, err := l.Allow(context.Background(), key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if allowed != s.wantAllowed || retryAfter != s.wantRetryAfter {
+					t.Errorf("step %d: Allow() = %v, %s, want %v, %s", i, allowed, retryAfter, s.wantAllowed, s.wantRetryAfter)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenBucketLimiterSweep(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l, err := NewTokenBucketLimiter(1, 2, WithLimiterClock(ClockFunc(func() time.Time { return now })))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Allow(context.Background(), "U1")
+	now = now.Add(2 * time.Second)
+	l.Allow(context.Background(), "U2")
+	if _, ok := l.buckets["U1"]; ok || len(l.buckets) != 1 {
+		t.Errorf("buckets = %v, want the full bucket of U1 removed", l.buckets)
+	}
+}
+
+func TestNewTokenBucketLimiterValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		rate  float64
+		burst int
+	}{
+		{name: "zero rate", rate: 0, burst: 1},
+		{name: "negative rate", rate: -1, burst: 1},
+		{name: "NaN rate", rate: math.NaN(), burst: 1},
+		{name: "infinite rate", rate: math.Inf(1), burst: 1},
+		{name: "zero burst", rate: 1, burst: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTokenBucketLimiter(tt.rate, tt.burst); err == nil {
+				t.Error("NewTokenBucketLimiter() succeeded")
+			}
+		})
+	}
+}
+
+func TestRateLimitByUserRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// 0.4 requests per second waits 2.5s, rounded up to 3s
+	l, err := NewTokenBucketLimiter(0.4, 1, WithLimiterClock(ClockFunc(func() time.Time { return now })))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := RateLimitByUser(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	do := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r = r.WithContext(SetUser(r.Context(), &User{ID: "U1"}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	if w := do(); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	w := do()
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "3" {
+		t.Errorf("status = %d, Retry-After = %q, want 429 and 3", w.Code, w.Header().Get("Retry-After"))
+	}
+}
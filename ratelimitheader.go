@@ -0,0 +1,70 @@
+package goline
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimit is the rate limit status in the response headers of LINE API.
+// LINE does not always send them, so it is available only when the headers are present.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	// Reset is the time when the quota is reset. It is zero when not sent.
+	Reset time.Time
+	// ObservedAt is the time when the response was received
+	ObservedAt time.Time
+}
+
+// ParseRateLimit parses X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers.
+// ok is false when the headers are not present.
+func ParseRateLimit(h http.Header) (rl *RateLimit, ok bool) {
+	limit, err := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return nil, false
+	}
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return nil, false
+	}
+	rl = &RateLimit{Limit: limit, Remaining: remaining}
+	if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rl.Reset = time.Unix(reset, 0)
+	}
+	return rl, true
+}
+
+// WithRateLimitHook calls fn with the rate limit status of every response having the rate limit headers,
+// e.g. to export the remaining quota as metrics and alert before getting throttled.
+func WithRateLimitHook(fn func(rl *RateLimit)) ClientOption {
+	return func(c *Client) {
+		c.rateLimitHook = fn
+	}
+}
+
+// RateLimit returns the latest rate limit status observed by the Client, or nil when LINE has not sent it
+func (c *Client) RateLimit() *RateLimit {
+	return c.rateLimit.Load()
+}
+
+// TooManyRequests returns the number of 429 Too Many Requests responses received by the Client
+func (c *Client) TooManyRequests() int64 {
+	return c.throttled.Load()
+}
+
+// observeRateLimit records the rate limit status and 429 responses
+func (c *Client) observeRateLimit(res *http.Response) {
+	if res.StatusCode == http.StatusTooManyRequests {
+		c.throttled.Add(1)
+	}
+	rl, ok := ParseRateLimit(res.Header)
+	if !ok {
+		return
+	}
+	rl.ObservedAt = c.clock.Now()
+	c.rateLimit.Store(rl)
+	if c.rateLimitHook != nil {
+		c.rateLimitHook(rl)
+	}
+}
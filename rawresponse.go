@@ -0,0 +1,119 @@
+package goline
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// RawResponse keeps the raw json of the response body. It is embedded in the response structs of the Client
+// so that the fields newly added by LINE are not lost. See ExtraFields to get them.
+type RawResponse struct {
+	Raw json.RawMessage `json:"-"`
+}
+
+func (r *RawResponse) setRaw(b json.RawMessage) {
+	r.Raw = b
+}
+
+// rawReceiver is implemented by the response structs embedding RawResponse
+type rawReceiver interface {
+	setRaw(b json.RawMessage)
+}
+
+// WithStrictDecoding makes the Client fail to decode the responses including fields unknown to the response structs.
+// It is intended for tests to detect API changes. The claims of ID tokens are not checked as they are open-ended.
+func WithStrictDecoding() ClientOption {
+	return func(c *Client) {
+		c.strict = true
+	}
+}
+
+// decodeResponse decodes the json body into v keeping the raw json when v embeds RawResponse
+func decodeResponse(r io.Reader, v interface{}, strict bool) error {
+	rr, keepRaw := v.(rawReceiver)
+	if !keepRaw && !strict {
+		// Decode directly from the stream not to buffer the whole body
+		return json.NewDecoder(r).Decode(v)
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if keepRaw {
+		rr.setRaw(raw)
+	}
+	return nil
+}
+
+// ExtraFields returns the fields in the raw json of the response unknown to the response struct,
+// e.g. fields newly added by LINE. v must be a pointer to the response struct embedding RawResponse.
+//
+//	p, err := lineClient.GetProfile(ctx, accessToken)
+//	extra, err := goline.ExtraFields(p)
+func ExtraFields(v interface{}) (map[string]json.RawMessage, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("response must be a pointer to struct")
+	}
+	f := rv.Elem().FieldByName("RawResponse")
+	if !f.IsValid() || f.Type() != reflect.TypeOf(RawResponse{}) {
+		return nil, errors.New("response does not embed RawResponse")
+	}
+	raw := f.Interface().(RawResponse).Raw
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	known := jsonFieldNames(rv.Elem().Type())
+	for k := range fields {
+		for _, name := range known {
+			// encoding/json matches the names case-insensitively
+			if strings.EqualFold(k, name) {
+				delete(fields, k)
+				break
+			}
+		}
+	}
+	return fields, nil
+}
+
+// jsonFieldNames returns the json names of the exported fields including the fields of embedded structs
+func jsonFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && tag == "" {
+			names = append(names, jsonFieldNames(f.Type)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = f.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
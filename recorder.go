@@ -0,0 +1,228 @@
+package goline
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	redacted = "REDACTED"
+
+	// Maximum size of body recorded in Exchange
+	maxRecordBodySize = 64 * 1024
+)
+
+// Parameters and json fields which values are redacted in Exchange
+var sensitiveKeys = map[string]bool{
+	"access_token":     true,
+	"refresh_token":    true,
+	"id_token":         true,
+	"client_secret":    true,
+	"client_assertion": true,
+	"code":             true,
+	"code_verifier":    true,
+}
+
+// Exchange is a sanitized copy of the request to LINE and the response.
+// Tokens, secrets, emails and display names in headers, query, form and json bodies are redacted by RedactionPolicy.
+type Exchange struct {
+	Time           time.Time
+	Duration       time.Duration
+	Method         string
+	URL            string
+	RequestHeader  http.Header
+	RequestBody    string
+	StatusCode     int
+	ResponseHeader http.Header
+	ResponseBody   string
+	Err            string
+	// CorrelationID is the correlation ID in the context of the request, see SetCorrelationID
+	CorrelationID string
+}
+
+// Recorder records exchanges with LINE for debugging
+type Recorder interface {
+	Record(e *Exchange)
+}
+
+// RecorderFunc is an adapter to use a function as Recorder
+type RecorderFunc func(e *Exchange)
+
+// Record implements Recorder
+func (f RecorderFunc) Record(e *Exchange) {
+	f(e)
+}
+
+// WithRecorder records sanitized copies of requests and responses of the Client.
+// It is useful to diagnose verification failures in production without enabling full wire logging.
+func WithRecorder(r Recorder) ClientOption {
+	return func(c *Client) {
+		c.recorder = r
+	}
+}
+
+// RingRecorder is a Recorder keeping the latest exchanges in a ring buffer
+type RingRecorder struct {
+	mu   sync.Mutex
+	buf  []*Exchange
+	next int
+	full bool
+}
+
+// NewRingRecorder returns new RingRecorder keeping the latest "size" exchanges
+func NewRingRecorder(size int) *RingRecorder {
+	if size <= 0 {
+		size = 1
+	}
+	return &RingRecorder{buf: make([]*Exchange, size)}
+}
+
+// Record implements Recorder
+func (r *RingRecorder) Record(e *Exchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buf[r.next] = e
+	r.next = (r.next + 1) % len(r.buf)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Exchanges returns the recorded exchanges from the oldest
+func (r *RingRecorder) Exchanges() []*Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]*Exchange(nil), r.buf[:r.next]...)
+	}
+	return append(append([]*Exchange(nil), r.buf[r.next:]...), r.buf[:r.next]...)
+}
+
+// recordingTransport is a http.RoundTripper recording exchanges
+type recordingTransport struct {
+	base      http.RoundTripper
+	recorder  Recorder
+	clock     Clock
+	redaction RedactionPolicy
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	e := &Exchange{
+		Time:          t.clock.Now(),
+		Method:        req.Method,
+		URL:           t.redaction.sanitizeURL(req.URL),
+		RequestHeader: t.redaction.sanitizeHeader(req.Header),
+		CorrelationID: CorrelationIDFromContext(req.Context()),
+	}
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			b, _ := io.ReadAll(io.LimitReader(body, maxRecordBodySize))
+			body.Close()
+			e.RequestBody = t.redaction.sanitizeBody(req.Header.Get("Content-Type"), b)
+		}
+	}
+
+	res, err := t.base.RoundTrip(req)
+	e.Duration = t.clock.Now().Sub(e.Time)
+	if err != nil {
+		e.Err = err.Error()
+		t.recorder.Record(e)
+		return nil, err
+	}
+
+	e.StatusCode = res.StatusCode
+	e.ResponseHeader = t.redaction.sanitizeHeader(res.Header)
+	if isJSON(res.Header.Get("Content-Type")) {
+		// Read only the recorded part and splice the rest back not to buffer large responses
+		b, err := io.ReadAll(io.LimitReader(res.Body, maxRecordBodySize+1))
+		res.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(b), res.Body), res.Body}
+		if err != nil {
+			e.Err = err.Error()
+		}
+		if len(b) > maxRecordBodySize {
+			b = b[:maxRecordBodySize]
+		}
+		e.ResponseBody = t.redaction.sanitizeBody(res.Header.Get("Content-Type"), b)
+	}
+	t.recorder.Record(e)
+	return res, nil
+}
+
+func (p RedactionPolicy) sanitizeURL(u *url.URL) string {
+	c := *u
+	q := c.Query()
+	for k := range q {
+		if p.redactKey(k) {
+			q.Set(k, redacted)
+		}
+	}
+	c.RawQuery = q.Encode()
+	return c.String()
+}
+
+func (p RedactionPolicy) sanitizeHeader(h http.Header) http.Header {
+	c := h.Clone()
+	if !p.AllowTokens && c.Get("Authorization") != "" {
+		c.Set("Authorization", redacted)
+	}
+	return c
+}
+
+func (p RedactionPolicy) sanitizeBody(contentType string, b []byte) string {
+	switch {
+	case strings.HasPrefix(contentType, contentTypeForm):
+		v, err := url.ParseQuery(string(b))
+		if err != nil {
+			return redacted
+		}
+		for k := range v {
+			if p.redactKey(k) {
+				v.Set(k, redacted)
+			}
+		}
+		return v.Encode()
+	case isJSON(contentType):
+		var v interface{}
+		if err := json.Unmarshal(b, &v); err != nil {
+			// Do not record body in unknown format not to leak secrets
+			return redacted
+		}
+		s, _ := json.Marshal(p.redactJSON(v))
+		return string(s)
+	default:
+		return ""
+	}
+}
+
+// redactJSON redacts the values of the sensitive fields in the decoded json at any depth,
+// e.g. the tokens nested in the objects or the arrays of the response.
+func (p RedactionPolicy) redactJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if p.redactKey(k) {
+				v[k] = redacted
+				continue
+			}
+			v[k] = p.redactJSON(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = p.redactJSON(e)
+		}
+	}
+	return v
+}
+
+func isJSON(contentType string) bool {
+	return strings.HasPrefix(contentType, "application/json")
+}
@@ -0,0 +1,70 @@
+package goline
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// countingBody counts the bytes read from the body and whether it is closed
+type countingBody struct {
+	r      io.Reader
+	read   int
+	closed bool
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	b.read += n
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestRecordingTransportLargeResponse(t *testing.T) {
+	full := `{"data":"` + strings.Repeat("a", 4*maxRecordBodySize) + `"}`
+	body := &countingBody{r: strings.NewReader(full)}
+	rec := NewRingRecorder(1)
+	rt := &recordingTransport{
+		base: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       body,
+				Request:    r,
+			}, nil
+		}),
+		recorder: rec,
+		clock:    SystemClock,
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.line.me/v2/profile", nil)
+	res, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body.read > maxRecordBodySize+1 {
+		t.Errorf("%d bytes buffered, want up to %d", body.read, maxRecordBodySize+1)
+	}
+	if e := rec.Exchanges()[0]; len(e.ResponseBody) > maxRecordBodySize {
+		t.Errorf("recorded %d bytes, want up to %d", len(e.ResponseBody), maxRecordBodySize)
+	}
+
+	// The caller reads the whole body
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, []byte(full)) {
+		t.Errorf("read %d bytes, want the whole body of %d bytes", len(b), len(full))
+	}
+	res.Body.Close()
+	if !body.closed {
+		t.Error("the original body is not closed")
+	}
+}
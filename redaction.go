@@ -0,0 +1,58 @@
+package goline
+
+import (
+	"errors"
+	"net/url"
+)
+
+// Json fields of the responses which values are redacted in Exchange by RedactionPolicy
+var (
+	emailKeys       = map[string]bool{"email": true}
+	displayNameKeys = map[string]bool{"name": true, "displayName": true}
+)
+
+// RedactionPolicy selects the fields allowed to appear in logs, error strings and the exchanges recorded by WithRecorder.
+// The zero value redacts tokens, secrets, emails and display names. Allow them only in debug environments.
+type RedactionPolicy struct {
+	// AllowTokens allows tokens and secrets in queries, forms, json bodies and authorization header
+	AllowTokens bool
+	// AllowEmails allows emails in json bodies and the authentication logs
+	AllowEmails bool
+	// AllowDisplayNames allows display names in json bodies and the authentication logs
+	AllowDisplayNames bool
+}
+
+// WithRedactionPolicy sets RedactionPolicy of the Client and the Authorizer using the Client. Default redacts all.
+func WithRedactionPolicy(p RedactionPolicy) ClientOption {
+	return func(c *Client) {
+		c.redaction = p
+	}
+}
+
+// redactKey returns true when the value of the parameter or the json field must be redacted
+func (p RedactionPolicy) redactKey(k string) bool {
+	switch {
+	case sensitiveKeys[k]:
+		return !p.AllowTokens
+	case emailKeys[k]:
+		return !p.AllowEmails
+	case displayNameKeys[k]:
+		return !p.AllowDisplayNames
+	default:
+		return false
+	}
+}
+
+// redactError redacts the tokens in the URL of *url.Error returned by http.Client,
+// e.g. the access token in the query of verify-access-token API.
+func (p RedactionPolicy) redactError(err error) error {
+	var ue *url.Error
+	if p.AllowTokens || !errors.As(err, &ue) {
+		return err
+	}
+	u, perr := url.Parse(ue.URL)
+	if perr != nil {
+		return &url.Error{Op: ue.Op, URL: redacted, Err: ue.Err}
+	}
+	return &url.Error{Op: ue.Op, URL: p.sanitizeURL(u), Err: ue.Err}
+}
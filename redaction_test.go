@@ -0,0 +1,168 @@
+package goline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// Tokens which must never appear in the logs and the recorded exchanges
+var secretValues = []string{"secret-id-token", "secret-access-token", "secret-refresh-token", "secret-code"}
+
+func assertNoSecrets(t *testing.T, where, s string) {
+	t.Helper()
+	for _, v := range secretValues {
+		if strings.Contains(s, v) {
+			t.Errorf("%s contains %q: %s", where, v, s)
+		}
+	}
+}
+
+func TestRedactionPolicySanitizeBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      RedactionPolicy
+		contentType string
+		body        string
+		want        string
+	}{
+		{
+			name:        "form",
+			contentType: contentTypeForm,
+			body:        "id_token=secret-id-token&client_id=123",
+			want:        "client_id=123&id_token=REDACTED",
+		},
+		{
+			name:        "json top level",
+			contentType: "application/json",
+			body:        `{"access_token":"secret-access-token","expires_in":3600}`,
+			want:        `{"access_token":"REDACTED","expires_in":3600}`,
+		},
+		{
+			name:        "json nested object",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"result":{"token":{"access_token":"secret-access-token","id_token":"secret-id-token"}}}`,
+			want:        `{"result":{"token":{"access_token":"REDACTED","id_token":"REDACTED"}}}`,
+		},
+		{
+			name:        "json nested array",
+			contentType: "application/json",
+			body:        `{"tokens":[{"refresh_token":"secret-refresh-token"},{"code":"secret-code"}]}`,
+			want:        `{"tokens":[{"refresh_token":"REDACTED"},{"code":"REDACTED"}]}`,
+		},
+		{
+			name:        "json top level array",
+			contentType: "application/json",
+			body:        `[{"access_token":"secret-access-token"}]`,
+			want:        `[{"access_token":"REDACTED"}]`,
+		},
+		{
+			name:        "json emails and display names",
+			contentType: "application/json",
+			body:        `{"sub":"U1","email":"a@example.com","name":"Taro","friends":[{"displayName":"Hanako"}]}`,
+			want:        `{"email":"REDACTED","friends":[{"displayName":"REDACTED"}],"name":"REDACTED","sub":"U1"}`,
+		},
+		{
+			name:        "json allowed",
+			policy:      RedactionPolicy{AllowTokens: true, AllowEmails: true, AllowDisplayNames: true},
+			contentType: "application/json",
+			body:        `{"data":{"access_token":"at","email":"a@example.com","name":"Taro"}}`,
+			want:        `{"data":{"access_token":"at","email":"a@example.com","name":"Taro"}}`,
+		},
+		{
+			name:        "invalid json",
+			contentType: "application/json",
+			body:        `{"access_token":"secret-access-token"`,
+			want:        redacted,
+		},
+		{
+			name:        "unknown content type",
+			contentType: "text/plain",
+			body:        "secret-access-token",
+			want:        "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.sanitizeBody(tt.contentType, []byte(tt.body)); got != tt.want {
+				t.Errorf("sanitizeBody() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactionPolicySanitizeURLAndHeader(t *testing.T) {
+	u, _ := url.Parse("https://api.line.me/oauth2/v2.1/verify?access_token=secret-access-token&foo=bar")
+	got := RedactionPolicy{}.sanitizeURL(u)
+	assertNoSecrets(t, "url", got)
+	if !strings.Contains(got, "foo=bar") {
+		t.Errorf("sanitizeURL() = %s, want other parameters kept", got)
+	}
+
+	h := http.Header{"Authorization": {"Bearer secret-access-token"}}
+	if got := (RedactionPolicy{}).sanitizeHeader(h).Get("Authorization"); got != redacted {
+		t.Errorf("sanitizeHeader() Authorization = %s, want %s", got, redacted)
+	}
+	if h.Get("Authorization") != "Bearer secret-access-token" {
+		t.Error("sanitizeHeader() modified the original header")
+	}
+}
+
+func TestRecorderRedactsTokens(t *testing.T) {
+	_, hc := newTestLINE(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"iss":"https://access.line.me","sub":"U1","aud":"123","exp":1,"iat":1,` +
+			`"linked":{"access_token":"secret-access-token"},"history":[{"refresh_token":"secret-refresh-token"}]}`))
+	}))
+	rec := NewRingRecorder(10)
+	c := NewClient("123", hc, WithRecorder(rec))
+
+	if _, err := c.VerifyIDToken(context.Background(), "secret-id-token", nil); err != nil {
+		t.Fatal(err)
+	}
+	exchanges := rec.Exchanges()
+	if len(exchanges) != 1 {
+		t.Fatalf("recorded %d exchanges, want 1", len(exchanges))
+	}
+	b, _ := json.Marshal(exchanges[0])
+	assertNoSecrets(t, "exchange", string(b))
+	if !strings.Contains(exchanges[0].ResponseBody, `"sub":"U1"`) {
+		t.Errorf("response body %s, want other fields kept", exchanges[0].ResponseBody)
+	}
+}
+
+func TestLogsRedactTokens(t *testing.T) {
+	s, hc := newTestLINE(t, http.NotFoundHandler())
+	s.Close() // fail by network error which has the URL with the token
+
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c := NewClient("123", hc, WithLogger(log))
+	a := NewAuthorizer(c, log)
+
+	_, err := c.VerifyAccessToken(context.Background(), "secret-access-token")
+	if err == nil {
+		t.Fatal("want error")
+	}
+	assertNoSecrets(t, "error", err.Error())
+
+	r, _ := http.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer secret-access-token")
+	a.VerifyAccessTokenMiddleware(http.NotFoundHandler()).ServeHTTP(discardResponseWriter{}, r)
+
+	if buf.Len() == 0 {
+		t.Fatal("nothing logged")
+	}
+	assertNoSecrets(t, "log", buf.String())
+}
+
+type discardResponseWriter struct{}
+
+func (discardResponseWriter) Header() http.Header         { return http.Header{} }
+func (discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (discardResponseWriter) WriteHeader(int)             {}
@@ -0,0 +1,193 @@
+package goline
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRefreshInterval  = time.Minute
+	defaultRefreshThreshold = time.Hour
+)
+
+// RefreshManager watches tokens in TokenStore and refreshes access tokens nearing expiry in background.
+// The store must implement TokenLister. The expiry is checked by the Clock of the Client.
+type RefreshManager struct {
+	client    *Client
+	store     TokenStore
+	lister    TokenLister
+	interval  time.Duration
+	threshold time.Duration
+	onRefresh func(userID string, t *TokenSet)
+	onFailure func(userID string, err error)
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// RefreshManagerOption configures RefreshManager
+type RefreshManagerOption func(*RefreshManager)
+
+// WithRefreshInterval sets the interval to check tokens. Default is 1 minute.
+// Each interval is jittered up to 10% not to refresh at the same time among multiple instances.
+func WithRefreshInterval(d time.Duration) RefreshManagerOption {
+	return func(m *RefreshManager) {
+		m.interval = d
+	}
+}
+
+// WithRefreshThreshold sets the remaining lifetime of access tokens to be refreshed. Default is 1 hour.
+func WithRefreshThreshold(d time.Duration) RefreshManagerOption {
+	return func(m *RefreshManager) {
+		m.threshold = d
+	}
+}
+
+// WithRefreshHook sets a function called when the token is refreshed and saved
+func WithRefreshHook(fn func(userID string, t *TokenSet)) RefreshManagerOption {
+	return func(m *RefreshManager) {
+		m.onRefresh = fn
+	}
+}
+
+// WithRefreshFailureHook sets a function called when the token refresh fails.
+// ErrTokenExpired or ErrTokenRevoked is passed when the refresh token is expired or revoked by the user,
+// which means the user needs to log in again.
+func WithRefreshFailureHook(fn func(userID string, err error)) RefreshManagerOption {
+	return func(m *RefreshManager) {
+		m.onFailure = fn
+	}
+}
+
+// NewRefreshManager returns new RefreshManager.
+// The client must be created with WithChannelSecret and the store must implement TokenLister.
+func NewRefreshManager(client *Client, store TokenStore, opts ...RefreshManagerOption) (*RefreshManager, error) {
+	if client == nil {
+		return nil, errors.New("client is nil")
+	}
+	lister, ok := store.(TokenLister)
+	if !ok {
+		return nil, errors.New("token store does not implement TokenLister")
+	}
+	m := &RefreshManager{
+		client:    client,
+		store:     store,
+		lister:    lister,
+		interval:  defaultRefreshInterval,
+		threshold: defaultRefreshThreshold,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m, nil
+}
+
+// Start starts watching tokens in background. It returns error when already started.
+func (m *RefreshManager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cancel != nil {
+		return errors.New("refresh manager already started")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	go m.run(ctx, m.done)
+	return nil
+}
+
+// Stop stops watching tokens and waits for the running refresh to finish
+func (m *RefreshManager) Stop() {
+	m.mu.Lock()
+	cancel, done := m.cancel, m.done
+	m.cancel, m.done = nil, nil
+	m.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+func (m *RefreshManager) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	for {
+		m.RefreshAll(ctx)
+
+		timer := time.NewTimer(m.jitteredInterval())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (m *RefreshManager) jitteredInterval() time.Duration {
+	jitter := int64(m.interval) / 10
+	if jitter <= 0 {
+		return m.interval
+	}
+	return m.interval + time.Duration(rand.Int63n(jitter))
+}
+
+// RefreshAll refreshes all tokens nearing expiry once.
+// It is called periodically after Start, and can be called directly.
+func (m *RefreshManager) RefreshAll(ctx context.Context) {
+	ids, err := m.lister.ListUserIDs(ctx)
+	if err != nil {
+		m.fail("", err)
+		return
+	}
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := m.refresh(ctx, id); err != nil {
+			m.fail(id, err)
+		}
+	}
+}
+
+func (m *RefreshManager) refresh(ctx context.Context, userID string) error {
+	t, err := m.store.Load(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrTokenNotFound) {
+			return nil
+		}
+		return err
+	}
+	now := m.client.clock.Now()
+	if !t.ExpiresWithin(now, m.threshold) {
+		return nil
+	}
+
+	res, err := m.client.RefreshAccessToken(ctx, t.RefreshToken)
+	if err != nil {
+		return err
+	}
+	newToken := res.TokenSet(now)
+	if newToken.IDToken == "" {
+		newToken.IDToken = t.IDToken
+	}
+	if err := m.store.Save(ctx, userID, newToken); err != nil {
+		return err
+	}
+	if m.onRefresh != nil {
+		m.onRefresh(userID, newToken)
+	}
+	return nil
+}
+
+func (m *RefreshManager) fail(userID string, err error) {
+	if m.onFailure != nil {
+		m.onFailure(userID, err)
+	}
+}
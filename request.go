@@ -0,0 +1,202 @@
+package goline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const contentTypeJSON = "application/json"
+
+// jsonContentType is the precomputed header value of the json requests. It must not be modified.
+var jsonContentType = []string{contentTypeJSON}
+
+// requestBuilder builds the requests to LINE with query parameters, a form-encoded body or a json body uniformly.
+// The parameters are given as key value pairs, and the pairs with empty value are omitted.
+//
+//	req, err := newRequest(http.MethodPost, urlVerifyIDToken).formBody("id_token", idToken, "client_id", c.clientid).build(ctx)
+type requestBuilder struct {
+	method     string
+	url        string
+	tmpl       *requestTemplate
+	query      []string
+	form       []string
+	json       interface{}
+	bearer     string
+	idempotent bool
+}
+
+func newRequest(method, url string) *requestBuilder {
+	return &requestBuilder{method: method, url: url}
+}
+
+// requestTemplate is a request to a constant endpoint built once. It is cloned per call,
+// not to parse the URL on every verification.
+type requestTemplate struct {
+	req *http.Request
+}
+
+// newRequestTemplate returns requestTemplate. It panics when the URL is invalid, as it is a constant.
+func newRequestTemplate(method, url string) *requestTemplate {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		panic(err)
+	}
+	return &requestTemplate{req: req}
+}
+
+// newRequest returns requestBuilder building the request cloned from the template
+func (t *requestTemplate) newRequest() *requestBuilder {
+	return &requestBuilder{tmpl: t}
+}
+
+// clone returns the copy of the template request with the query parameters and the body
+func (t *requestTemplate) clone(ctx context.Context, query string, body io.Reader) *http.Request {
+	req := t.req.Clone(ctx)
+	if query != "" {
+		if req.URL.RawQuery != "" {
+			query = req.URL.RawQuery + "&" + query
+		}
+		req.URL.RawQuery = query
+	}
+	if body != nil {
+		setBody(req, body)
+	}
+	return req
+}
+
+// setBody sets the body and its length as http.NewRequest does for the readers the builder creates
+func setBody(req *http.Request, body io.Reader) {
+	switch v := body.(type) {
+	case *strings.Reader:
+		req.ContentLength = int64(v.Len())
+		snapshot := *v
+		req.GetBody = func() (io.ReadCloser, error) {
+			r := snapshot
+			return io.NopCloser(&r), nil
+		}
+	case *bytes.Reader:
+		req.ContentLength = int64(v.Len())
+		snapshot := *v
+		req.GetBody = func() (io.ReadCloser, error) {
+			r := snapshot
+			return io.NopCloser(&r), nil
+		}
+	}
+	if req.ContentLength == 0 && req.GetBody != nil {
+		req.Body = http.NoBody
+		req.GetBody = func() (io.ReadCloser, error) { return http.NoBody, nil }
+		return
+	}
+	req.Body = io.NopCloser(body)
+}
+
+// queryParams adds the query parameters
+func (b *requestBuilder) queryParams(kv ...string) *requestBuilder {
+	b.query = append(b.query, kv...)
+	return b
+}
+
+// formBody sets the "application/x-www-form-urlencoded" body
+func (b *requestBuilder) formBody(kv ...string) *requestBuilder {
+	b.form = append(b.form, kv...)
+	return b
+}
+
+// jsonBody sets the json body encoded from v
+func (b *requestBuilder) jsonBody(v interface{}) *requestBuilder {
+	b.json = v
+	return b
+}
+
+// bearerAuth sets the authorization header with the bearer token
+func (b *requestBuilder) bearerAuth(token string) *requestBuilder {
+	b.bearer = token
+	return b
+}
+
+// idempotentRequest marks the POST request as idempotent to be retried by RetryPolicy.
+// The Idempotency-Key header without value is not sent, as net/http treats it.
+func (b *requestBuilder) idempotentRequest() *requestBuilder {
+	b.idempotent = true
+	return b
+}
+
+// build returns the http request. Either form or json body can be set.
+func (b *requestBuilder) build(ctx context.Context) (*http.Request, error) {
+	q := encodeForm(b.query...)
+
+	var body io.Reader
+	var contentType []string
+	switch {
+	case b.form != nil && b.json != nil:
+		return nil, errors.New("both form and json body are set")
+	case b.form != nil:
+		body = strings.NewReader(encodeForm(b.form...))
+		contentType = formContentType
+	case b.json != nil:
+		buf, err := json.Marshal(b.json)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(buf)
+		contentType = jsonContentType
+	}
+
+	var req *http.Request
+	if b.tmpl != nil {
+		req = b.tmpl.clone(ctx, q, body)
+	} else {
+		u := b.url
+		if q != "" {
+			if strings.Contains(u, "?") {
+				u += "&" + q
+			} else {
+				u += "?" + q
+			}
+		}
+		var err error
+		req, err = http.NewRequestWithContext(ctx, b.method, u, body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if contentType != nil {
+		req.Header["Content-Type"] = contentType
+	}
+	if b.bearer != "" {
+		req.Header.Set(authHeader, bearerToken(b.bearer))
+	}
+	if b.idempotent {
+		req.Header["Idempotency-Key"] = nil
+	}
+	return req, nil
+}
+
+// encodeForm encodes key value pairs in "application/x-www-form-urlencoded" format.
+// Pairs with empty value are omitted. It avoids allocating url.Values on the verification path.
+func encodeForm(kv ...string) string {
+	var b strings.Builder
+	n := 0
+	for i := 0; i+1 < len(kv); i += 2 {
+		n += len(kv[i]) + len(kv[i+1]) + 2
+	}
+	b.Grow(n)
+	for i := 0; i+1 < len(kv); i += 2 {
+		if kv[i+1] == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('&')
+		}
+		b.WriteString(kv[i])
+		b.WriteByte('=')
+		b.WriteString(url.QueryEscape(kv[i+1]))
+	}
+	return b.String()
+}
@@ -0,0 +1,247 @@
+package goline
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func readBody(t *testing.T, req *http.Request) string {
+	t.Helper()
+	if req.Body == nil {
+		return ""
+	}
+	b, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestEncodeForm(t *testing.T) {
+	tests := []struct {
+		name string
+		kv   []string
+		want string
+	}{
+		{name: "empty", want: ""},
+		{name: "pairs", kv: []string{"a", "1", "b", "2"}, want: "a=1&b=2"},
+		{name: "empty values omitted", kv: []string{"a", "", "b", "2", "c", ""}, want: "b=2"},
+		{name: "escaped", kv: []string{"redirect_uri", "https://example.com/cb?x=1&y=2", "scope", "profile openid"},
+			want: "redirect_uri=https%3A%2F%2Fexample.com%2Fcb%3Fx%3D1%26y%3D2&scope=profile+openid"},
+		{name: "token characters", kv: []string{"id_token", "a.b-c_d~e+f/g=="}, want: "id_token=a.b-c_d~e%2Bf%2Fg%3D%3D"},
+		{name: "odd pair ignored", kv: []string{"a", "1", "b"}, want: "a=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := encodeForm(tt.kv...)
+			if got != tt.want {
+				t.Errorf("encodeForm() = %s, want %s", got, tt.want)
+			}
+			// Same as url.Values except the omitted pairs
+			v, err := url.ParseQuery(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i+1 < len(tt.kv); i += 2 {
+				if tt.kv[i+1] != "" && v.Get(tt.kv[i]) != tt.kv[i+1] {
+					t.Errorf("decoded %s = %s, want %s", tt.kv[i], v.Get(tt.kv[i]), tt.kv[i+1])
+				}
+			}
+		})
+	}
+}
+
+func TestRequestBuilder(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("query", func(t *testing.T) {
+		req, err := newRequest(http.MethodGet, "https://api.line.me/v2/x?fixed=1").queryParams("access_token", "a+b", "empty", "").build(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := req.URL.String(); got != "https://api.line.me/v2/x?fixed=1&access_token=a%2Bb" {
+			t.Errorf("URL = %s", got)
+		}
+		if req.Body != nil || req.Header.Get("Content-Type") != "" {
+			t.Errorf("GET request has body or content type")
+		}
+	})
+
+	t.Run("form", func(t *testing.T) {
+		req, err := newRequest(http.MethodPost, urlVerifyIDToken).formBody("id_token", "t", "client_id", "123", "nonce", "").build(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := req.Header.Get("Content-Type"); got != contentTypeForm {
+			t.Errorf("Content-Type = %s", got)
+		}
+		if req.ContentLength != int64(len("id_token=t&client_id=123")) {
+			t.Errorf("ContentLength = %d", req.ContentLength)
+		}
+		if got := readBody(t, req); got != "id_token=t&client_id=123" {
+			t.Errorf("body = %s", got)
+		}
+		// GetBody replays the body for retries
+		body, err := req.GetBody()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b, _ := io.ReadAll(body); string(b) != "id_token=t&client_id=123" {
+			t.Errorf("replayed body = %s", b)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		req, err := newRequest(http.MethodPost, "https://api.line.me/v2/x").jsonBody(map[string]string{"a": "b"}).bearerAuth("tok").build(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := req.Header.Get("Content-Type"); got != contentTypeJSON {
+			t.Errorf("Content-Type = %s", got)
+		}
+		if got := req.Header.Get(authHeader); got != "Bearer tok" {
+			t.Errorf("Authorization = %s", got)
+		}
+		if got := readBody(t, req); got != `{"a":"b"}` {
+			t.Errorf("body = %s", got)
+		}
+	})
+
+	t.Run("form and json", func(t *testing.T) {
+		if _, err := newRequest(http.MethodPost, "https://api.line.me/v2/x").formBody("a", "1").jsonBody(1).build(ctx); err == nil {
+			t.Error("want error")
+		}
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		if _, err := newRequest(http.MethodPost, "https://api.line.me/v2/x").jsonBody(make(chan int)).build(ctx); err == nil {
+			t.Error("want error")
+		}
+	})
+
+	t.Run("empty form", func(t *testing.T) {
+		req, err := newRequest(http.MethodPost, "https://api.line.me/v2/x").formBody("a", "").build(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if req.ContentLength != 0 || readBody(t, req) != "" {
+			t.Errorf("ContentLength = %d, want empty body", req.ContentLength)
+		}
+	})
+}
+
+func TestRequestTemplate(t *testing.T) {
+	tmpl := newRequestTemplate(http.MethodPost, urlVerifyIDToken)
+	ctx := context.WithValue(context.Background(), correlationIDContextKey{}, "cid")
+
+	req, err := tmpl.newRequest().formBody("id_token", "t1").bearerAuth("tok").build(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.URL.String() != urlVerifyIDToken || req.Method != http.MethodPost {
+		t.Errorf("got %s %s", req.Method, req.URL)
+	}
+	if req.Context() != ctx {
+		t.Error("context is not set")
+	}
+	if got := readBody(t, req); got != "id_token=t1" {
+		t.Errorf("body = %s", got)
+	}
+
+	// The headers, the URL and the body are not shared with the template or other requests
+	req.Header.Set("X-Modified", "1")
+	req.URL.RawQuery = "modified=1"
+	if len(tmpl.req.Header) != 0 || tmpl.req.URL.RawQuery != "" {
+		t.Errorf("template is modified: %v %s", tmpl.req.Header, tmpl.req.URL)
+	}
+	req2, err := tmpl.newRequest().formBody("id_token", "t2").build(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req2.Header.Get("X-Modified") != "" || req2.Header.Get(authHeader) != "" || req2.URL.RawQuery != "" {
+		t.Errorf("request shares the headers or the URL with the previous one: %v %s", req2.Header, req2.URL)
+	}
+	if got := readBody(t, req2); got != "id_token=t2" {
+		t.Errorf("body = %s", got)
+	}
+
+	q, err := newRequestTemplate(http.MethodGet, urlVerifyAccessToken).newRequest().queryParams("access_token", "a b").build(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := q.URL.String(); got != urlVerifyAccessToken+"?access_token=a+b" {
+		t.Errorf("URL = %s", got)
+	}
+}
+
+func TestRequestTemplateInvalidURL(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("want panic")
+		}
+	}()
+	newRequestTemplate(http.MethodGet, "://invalid")
+}
+
+func TestIdempotentRequest(t *testing.T) {
+	got := make(chan http.Header, 1)
+	_, hc := newTestLINE(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got <- r.Header
+	}))
+
+	req, err := newRequest(http.MethodPost, urlVerifyIDToken).formBody("id_token", "t").idempotentRequest().build(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isIdempotent(req) {
+		t.Error("request is not idempotent")
+	}
+	res, err := hc.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if h := <-got; len(h.Values("Idempotency-Key")) != 0 {
+		t.Errorf("Idempotency-Key is sent: %v", h.Values("Idempotency-Key"))
+	}
+
+	plain, err := newRequest(http.MethodPost, urlVerifyIDToken).formBody("id_token", "t").build(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isIdempotent(plain) {
+		t.Error("POST request without idempotentRequest is idempotent")
+	}
+}
+
+// BenchmarkBuildVerifyRequests builds the requests of verify-id-token and verify-access-token APIs from the templates,
+// as the Client does per verification
+func BenchmarkBuildVerifyRequests(b *testing.B) {
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := verifyIDTokenRequest.newRequest().formBody("id_token", "token", "client_id", "123").idempotentRequest().build(ctx); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := verifyAccessTokenRequest.newRequest().queryParams("access_token", "token").build(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkBuildVerifyRequestsWithoutTemplate builds the same requests parsing the URLs per call, to compare with the templates
+func BenchmarkBuildVerifyRequestsWithoutTemplate(b *testing.B) {
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := newRequest(http.MethodPost, urlVerifyIDToken).formBody("id_token", "token", "client_id", "123").idempotentRequest().build(ctx); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := newRequest(http.MethodGet, urlVerifyAccessToken).queryParams("access_token", "token").build(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
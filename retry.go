@@ -0,0 +1,161 @@
+package goline
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryBackoff    = 100 * time.Millisecond
+	defaultRetryMaxBackoff = 2 * time.Second
+)
+
+// RetryPolicy is the policy to retry the requests to LINE on network errors, 429 Too Many Requests and 5xx.
+//
+// Only idempotent requests are retried: GET requests and the verification requests which have no side effects.
+// The requests issuing tokens such as refreshing access tokens and issuing channel access tokens are never retried,
+// not to issue tokens twice and invalidate the refresh token accidentally.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one. 1 or less disables retries.
+	MaxAttempts int
+	// Backoff is the wait before the first retry, doubled on each retry. Default is 100ms.
+	// Retry-After of the response is used instead when it is longer.
+	Backoff time.Duration
+	// MaxBackoff is the maximum wait between retries. Default is 2s.
+	// When Retry-After of the response is longer, the error is returned without retrying.
+	MaxBackoff time.Duration
+}
+
+// WithRetryPolicy enables retries of the idempotent requests by the policy
+func WithRetryPolicy(p RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+// isIdempotent returns true when the request can be retried safely.
+// POST requests are idempotent when marked by Idempotency-Key header as net/http does.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	_, ok := req.Header["Idempotency-Key"]
+	return ok
+}
+
+// do does the request and checks the response status, retrying by the RetryPolicy.
+// The caller must close the response body when the error is nil.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	backoff := c.retry.Backoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+	maxBackoff := c.retry.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultRetryMaxBackoff
+	}
+	retryable := c.retry.MaxAttempts > 1 && isIdempotent(req) && (req.Body == nil || req.GetBody != nil)
+
+	for attempt := 1; ; attempt++ {
+		start := c.clock.Now()
+		res, err := c.client.Do(req)
+		err = c.redaction.redactError(err)
+		c.recordRequest(req, res, err, attempt, start)
+		if err == nil {
+			c.observeRateLimit(res)
+			if err = CheckResponse(res); err == nil {
+				return res, nil
+			}
+			// Drain the body to reuse the connection
+			io.CopyN(io.Discard, res.Body, maxDrainSize)
+			res.Body.Close()
+		}
+		// The timeouts of each attempt e.g. http.Client.Timeout are retried, but not the ones of the caller
+		if !retryable || attempt >= c.retry.MaxAttempts || req.Context().Err() != nil || !IsRetryable(err) {
+			return nil, err
+		}
+
+		wait := backoff
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > maxBackoff {
+			// Retrying before Retry-After would be rejected again
+			return nil, err
+		}
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > wait {
+			wait = apiErr.RetryAfter
+		}
+		if wait > maxBackoff {
+			wait = maxBackoff
+		}
+		if err := sleepContext(req.Context(), wait); err != nil {
+			return nil, err
+		}
+		backoff *= 2
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// recordRequest logs the request to LINE at debug level and emits the metrics.
+// The query is not logged as it may contain the token e.g. verify-access-token API.
+func (c *Client) recordRequest(req *http.Request, res *http.Response, err error, attempt int, start time.Time) {
+	latency := c.clock.Now().Sub(start)
+	status := 0
+	if res != nil {
+		status = res.StatusCode
+	}
+	c.observeRequest(req.URL.Path, status, latency)
+
+	ctx := req.Context()
+	if !c.log.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	h := []any{
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+		slog.Int("attempt", attempt),
+		slog.Int64("latency_ms", latency.Milliseconds()),
+	}
+	if res != nil {
+		h = append(h, slog.Int("status", status))
+	}
+	attrs := []slog.Attr{lineGroup(c.clientid, ""), slog.Group("http", h...)}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	c.log.LogAttrs(ctx, slog.LevelDebug, "LINE API request", attrs...)
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// ParseRetryAfter parses Retry-After header in seconds. It returns 0 when the value is not a positive number.
+func ParseRetryAfter(v string) time.Duration {
+	sec, err := strconv.Atoi(v)
+	if err != nil || sec <= 0 {
+		return 0
+	}
+	return time.Duration(sec) * time.Second
+}
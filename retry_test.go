@@ -0,0 +1,119 @@
+package goline
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientRetry(t *testing.T) {
+	tests := []struct {
+		name string
+		// first responds the first attempt, and the later attempts get the profile
+		first        http.HandlerFunc
+		timeout      time.Duration
+		ctxTimeout   time.Duration
+		wantAttempts int32
+		wantErr      func(error) bool
+	}{
+		{
+			name:         "5xx",
+			first:        func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusServiceUnavailable) },
+			wantAttempts: 2,
+		},
+		{
+			name: "timeout of the attempt",
+			first: func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			},
+			timeout:      50 * time.Millisecond,
+			wantAttempts: 2,
+		},
+		{
+			name: "deadline of the caller",
+			first: func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			},
+			ctxTimeout:   50 * time.Millisecond,
+			wantAttempts: 1,
+			wantErr:      func(err error) bool { return errors.Is(err, context.DeadlineExceeded) },
+		},
+		{
+			name: "retry after within max backoff",
+			first: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			wantAttempts: 2,
+		},
+		{
+			name: "retry after longer than max backoff",
+			first: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "10")
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			wantAttempts: 1,
+			wantErr: func(err error) bool {
+				var apiErr *APIError
+				return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests && apiErr.RetryAfter == 10*time.Second
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			_, hc := newTestLINE(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if attempts.Add(1) == 1 {
+					tt.first(w, r)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write(profileResponse)
+			}))
+			hc.Timeout = tt.timeout
+			c := NewClient("123", hc, WithRetryPolicy(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, MaxBackoff: time.Second}))
+
+			ctx := context.Background()
+			if tt.ctxTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.ctxTimeout)
+				defer cancel()
+			}
+			_, err := c.GetProfile(ctx, "token")
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("GetProfile() error = %v", err)
+			}
+			if tt.wantErr != nil && !tt.wantErr(err) {
+				t.Errorf("GetProfile() error = %v", err)
+			}
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("%d attempts, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := map[string]time.Duration{
+		"3":                             3 * time.Second,
+		"0":                             0,
+		"-1":                            0,
+		"":                              0,
+		"1.5":                           0,
+		"Wed, 21 Oct 2015 07:28:00 GMT": 0,
+	}
+	for v, want := range tests {
+		if got := ParseRetryAfter(v); got != want {
+			t.Errorf("ParseRetryAfter(%q) = %s, want %s", v, got, want)
+		}
+	}
+}
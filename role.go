@@ -0,0 +1,95 @@
+package goline
+
+import (
+	"context"
+	"net/http"
+)
+
+// RoleResolver maps the LINE user to application roles
+type RoleResolver interface {
+	ResolveRoles(ctx context.Context, u *User) ([]string, error)
+}
+
+// RoleResolverFunc is an adapter to use a function as RoleResolver
+type RoleResolverFunc func(ctx context.Context, u *User) ([]string, error)
+
+// ResolveRoles implements RoleResolver
+func (f RoleResolverFunc) ResolveRoles(ctx context.Context, u *User) ([]string, error) {
+	return f(ctx, u)
+}
+
+// StaticRoles is a RoleResolver by the static map of LINE user ID to roles
+type StaticRoles map[string][]string
+
+// ResolveRoles implements RoleResolver
+func (s StaticRoles) ResolveRoles(ctx context.Context, u *User) ([]string, error) {
+	return s[u.ID], nil
+}
+
+// UserAuthorizer authorizes the LINE user authenticated by Authorizer with application roles.
+// Use its middlewares after the middlewares of Authorizer.
+//
+//	ua := goline.NewUserAuthorizer(goline.StaticRoles{"Uxxx": {"admin"}})
+//	router.Use(lineAuth.VerifyIDTokenMiddleware, ua.RequireRole("admin"))
+type UserAuthorizer struct {
+	resolver RoleResolver
+}
+
+// NewUserAuthorizer returns new UserAuthorizer
+func NewUserAuthorizer(resolver RoleResolver) *UserAuthorizer {
+	return &UserAuthorizer{resolver: resolver}
+}
+
+// RequireRole returns a middleware allowing users having any of the roles.
+// It responds 401 Unauthorized when the user is not authenticated, and 403 Forbidden when the user has no role.
+// The resolved roles are available by RolesFromContext.
+func (ua *UserAuthorizer) RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			u, ok := UserFromContext(r.Context())
+			if !ok {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			userRoles, err := ua.resolver.ResolveRoles(r.Context(), u)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			if !containsAny(userRoles, roles) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), rolesContextKey{}, userRoles)))
+		})
+	}
+}
+
+// AllowUsers returns a middleware allowing only the LINE user IDs
+func AllowUsers(userIDs ...string) func(http.Handler) http.Handler {
+	roles := make(StaticRoles, len(userIDs))
+	for _, id := range userIDs {
+		roles[id] = []string{"allowed"}
+	}
+	return NewUserAuthorizer(roles).RequireRole("allowed")
+}
+
+type rolesContextKey struct{}
+
+// RolesFromContext returns the roles of the user resolved by UserAuthorizer
+func RolesFromContext(ctx context.Context) []string {
+	roles, _ := ctx.Value(rolesContextKey{}).([]string)
+	return roles
+}
+
+// containsAny reports whether "values" contains any of "targets"
+func containsAny(values, targets []string) bool {
+	for _, v := range values {
+		for _, t := range targets {
+			if v == t {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package goline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Component is a component running goroutines in background like RefreshManager.
+// Start must not block, and Stop must wait for the goroutines to exit.
+type Component interface {
+	Start(ctx context.Context) error
+	Stop()
+}
+
+// Runtime manages the lifecycle of background components.
+// Components are started in the order added and stopped in the reverse order,
+// so that servers can shut them down cleanly and tests do not leak goroutines.
+//
+//	rt := goline.NewRuntime(refreshManager)
+//	if err := rt.Start(ctx); err != nil { ... }
+//	defer rt.Shutdown(context.Background())
+type Runtime struct {
+	mu         sync.Mutex
+	components []Component
+	started    []Component
+}
+
+// NewRuntime returns Runtime of the components
+func NewRuntime(components ...Component) *Runtime {
+	return &Runtime{components: components}
+}
+
+// Add adds the component. It is started immediately with ctx when Runtime is already started.
+func (r *Runtime) Add(ctx context.Context, c Component) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.components = append(r.components, c)
+	if r.started == nil {
+		return nil
+	}
+	if err := c.Start(ctx); err != nil {
+		return err
+	}
+	r.started = append(r.started, c)
+	return nil
+}
+
+// Start starts all components. When one of them fails to start,
+// the components already started are stopped and the error is returned.
+func (r *Runtime) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started != nil {
+		return errors.New("runtime already started")
+	}
+
+	started := make([]Component, 0, len(r.components))
+	for i, c := range r.components {
+		if err := c.Start(ctx); err != nil {
+			stopAll(context.Background(), started)
+			return fmt.Errorf("failed to start component %d: %w", i, err)
+		}
+		started = append(started, c)
+	}
+	r.started = started
+	return nil
+}
+
+// Shutdown stops all started components in the reverse order.
+// It returns ctx.Err() when ctx is done before all components stop.
+func (r *Runtime) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	started := r.started
+	r.started = nil
+	r.mu.Unlock()
+
+	return stopAll(ctx, started)
+}
+
+// Run starts all components and blocks until ctx is done, then shuts them down
+func (r *Runtime) Run(ctx context.Context) error {
+	if err := r.Start(ctx); err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return r.Shutdown(context.Background())
+}
+
+func stopAll(ctx context.Context, components []Component) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := len(components) - 1; i >= 0; i-- {
+			components[i].Stop()
+		}
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
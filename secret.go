@@ -0,0 +1,67 @@
+package goline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrSecretNotFound is returned by SecretProvider when the secret does not exist
+	ErrSecretNotFound = errors.New("secret not found")
+)
+
+// SecretProvider provides secrets like the channel secret and the assertion signing keys
+// so that they are not hard-coded nor passed as plain strings in code.
+// Implementations for environment variables, files, AWS Secrets Manager and GCP Secret Manager are in secrets package.
+type SecretProvider interface {
+	GetSecret(ctx context.Context, name string) ([]byte, error)
+}
+
+// SecretProviderFunc is an adapter to use an ordinary function as SecretProvider
+type SecretProviderFunc func(ctx context.Context, name string) ([]byte, error)
+
+// GetSecret calls f(ctx, name)
+func (f SecretProviderFunc) GetSecret(ctx context.Context, name string) ([]byte, error) {
+	return f(ctx, name)
+}
+
+// loadSecretString gets the secret as string trimming the trailing new lines of secret files
+func loadSecretString(ctx context.Context, p SecretProvider, name string) (string, error) {
+	if p == nil {
+		return "", errors.New("secret provider not found")
+	}
+	b, err := p.GetSecret(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret %s: %w", name, err)
+	}
+	s := strings.TrimSpace(string(b))
+	if s == "" {
+		return "", fmt.Errorf("secret %s is empty", name)
+	}
+	return s, nil
+}
+
+// ChannelSecretFrom gets the channel secret by the name from SecretProvider and returns the option to set it.
+//
+//	opt, err := goline.ChannelSecretFrom(ctx, provider, "line-channel-secret")
+//	if err != nil { ... }
+//	client := goline.NewClient(channelID, http.DefaultClient, opt)
+func ChannelSecretFrom(ctx context.Context, p SecretProvider, name string) (ClientOption, error) {
+	secret, err := loadSecretString(ctx, p, name)
+	if err != nil {
+		return nil, err
+	}
+	return WithChannelSecret(secret), nil
+}
+
+// LoadChannelSecret sets ChannelSecret of the config by the secret of the name got from SecretProvider
+func (c *Config) LoadChannelSecret(ctx context.Context, p SecretProvider, name string) error {
+	secret, err := loadSecretString(ctx, p, name)
+	if err != nil {
+		return err
+	}
+	c.ChannelSecret = secret
+	return nil
+}
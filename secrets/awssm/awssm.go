@@ -0,0 +1,50 @@
+// Package awssm is a goline.SecretProvider of AWS Secrets Manager.
+//
+//	cfg, err := config.LoadDefaultConfig(ctx)
+//	provider := awssm.NewProvider(secretsmanager.NewFromConfig(cfg))
+//	opt, err := goline.ChannelSecretFrom(ctx, provider, "line/channel-secret")
+package awssm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+
+	"github.com/jlandowner/goline"
+)
+
+// API is the subset of secretsmanager.Client used by Provider
+type API interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// Provider provides secrets from AWS Secrets Manager
+type Provider struct {
+	client API
+}
+
+// NewProvider returns Provider. The name passed to GetSecret is the secret ID, which is the name or ARN of the secret.
+func NewProvider(client API) *Provider {
+	return &Provider{client: client}
+}
+
+// GetSecret returns the binary or string value of the current version of the secret.
+// goline.ErrSecretNotFound is returned when the secret does not exist.
+func (p *Provider) GetSecret(ctx context.Context, name string) ([]byte, error) {
+	out, err := p.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(name)})
+	if err != nil {
+		var nf *types.ResourceNotFoundException
+		if errors.As(err, &nf) {
+			return nil, fmt.Errorf("%w: %s", goline.ErrSecretNotFound, err)
+		}
+		return nil, err
+	}
+	if out.SecretBinary != nil {
+		return out.SecretBinary, nil
+	}
+	return []byte(aws.ToString(out.SecretString)), nil
+}
@@ -0,0 +1,100 @@
+// Package gcpsm is a goline.SecretProvider of GCP Secret Manager.
+// It calls the REST API with the http client authorized by the caller e.g. golang.org/x/oauth2/google.
+//
+//	hc, err := google.DefaultClient(ctx, "https://www.googleapis.com/auth/cloud-platform")
+//	provider := gcpsm.NewProvider("my-project", hc)
+//	opt, err := goline.ChannelSecretFrom(ctx, provider, "line-channel-secret")
+package gcpsm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jlandowner/goline"
+)
+
+const (
+	// See https://cloud.google.com/secret-manager/docs/reference/rest/v1/projects.secrets.versions/access
+	urlAccessSecretVersion = "https://secretmanager.googleapis.com/v1/%s:access"
+)
+
+// Provider provides secrets from GCP Secret Manager
+type Provider struct {
+	project string
+	client  *http.Client
+}
+
+// NewProvider returns Provider of the project. The client must be authorized to access the secrets.
+func NewProvider(project string, client *http.Client) *Provider {
+	return &Provider{project: project, client: client}
+}
+
+// accessSecretVersionResponse is the response json struct of access API
+type accessSecretVersionResponse struct {
+	Name    string `json:"name"`
+	Payload struct {
+		Data       string `json:"data"`
+		DataCrc32c string `json:"dataCrc32c"`
+	} `json:"payload"`
+}
+
+// GetSecret returns the payload of the secret version.
+// The name is the secret ID in the project to get the latest version, or the full resource name
+// like "projects/*/secrets/*/versions/*". goline.ErrSecretNotFound is returned when it does not exist.
+func (p *Provider) GetSecret(ctx context.Context, name string) ([]byte, error) {
+	// Check paramaters
+	if name == "" {
+		return nil, errors.New("secret name not found")
+	}
+	resource := name
+	if !strings.HasPrefix(name, "projects/") {
+		if p.project == "" {
+			return nil, errors.New("project not found")
+		}
+		resource = fmt.Sprintf("projects/%s/secrets/%s/versions/latest", url.PathEscape(p.project), url.PathEscape(name))
+	}
+
+	// Prepare http request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(urlAccessSecretVersion, resource), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", goline.ErrSecretNotFound, resource)
+	}
+	if err := goline.CheckResponse(res); err != nil {
+		return nil, fmt.Errorf("failed to access secret %s: %w", resource, err)
+	}
+	v := &accessSecretVersionResponse{}
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return nil, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(v.Payload.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode secret payload: %w", err)
+	}
+	if v.Payload.DataCrc32c != "" {
+		sum, err := strconv.ParseUint(v.Payload.DataCrc32c, 10, 32)
+		if err != nil || uint32(sum) != crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli)) {
+			return nil, errors.New("secret payload checksum mismatch")
+		}
+	}
+	return data, nil
+}
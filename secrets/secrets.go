@@ -0,0 +1,60 @@
+// Package secrets provides goline.SecretProvider implementations of environment variables and files.
+// See awssm and gcpsm packages for AWS Secrets Manager and GCP Secret Manager.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jlandowner/goline"
+)
+
+// EnvProvider provides secrets from environment variables
+type EnvProvider struct {
+	prefix string
+}
+
+// NewEnvProvider returns EnvProvider getting the secret of the name from the environment variable "prefix + name"
+func NewEnvProvider(prefix string) *EnvProvider {
+	return &EnvProvider{prefix: prefix}
+}
+
+// GetSecret returns the value of the environment variable. goline.ErrSecretNotFound is returned when it is not set.
+func (p *EnvProvider) GetSecret(ctx context.Context, name string) ([]byte, error) {
+	v, ok := os.LookupEnv(p.prefix + name)
+	if !ok {
+		return nil, fmt.Errorf("%w: environment variable %s", goline.ErrSecretNotFound, p.prefix+name)
+	}
+	return []byte(v), nil
+}
+
+// FileProvider provides secrets from the files in a directory e.g. Kubernetes Secret volume or Docker secrets in /run/secrets
+type FileProvider struct {
+	dir string
+}
+
+// NewFileProvider returns FileProvider getting the secret of the name from the file "dir/name"
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{dir: dir}
+}
+
+// GetSecret returns the content of the file. goline.ErrSecretNotFound is returned when the file does not exist.
+func (p *FileProvider) GetSecret(ctx context.Context, name string) ([]byte, error) {
+	// Do not read files outside of the directory
+	if name == "" || !filepath.IsLocal(name) || strings.ContainsRune(name, filepath.Separator) {
+		return nil, fmt.Errorf("invalid secret name: %s", name)
+	}
+	b, err := os.ReadFile(filepath.Join(p.dir, name))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", goline.ErrSecretNotFound, err)
+		}
+		return nil, err
+	}
+	return b, nil
+}
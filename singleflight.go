@@ -0,0 +1,60 @@
+package goline
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// flightGroup deduplicates concurrent calls with the same key so that
+// only one upstream call to LINE happens per token at a time.
+// The zero value is ready to use.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+}
+
+// do calls fn once for concurrent callers with the same key and returns the shared result.
+// "shared" is true when the result of the call by another caller is returned.
+// When the shared call is canceled by the context of another caller, fn is called again with ctx.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (v interface{}, shared bool, err error) {
+	v, shared, err = g.share(ctx, key, fn)
+	if err != nil && ctx.Err() == nil &&
+		(errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		v, err = fn(ctx)
+		return v, false, err
+	}
+	return v, shared, err
+}
+
+func (g *flightGroup) share(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, bool, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, true, c.err
+	}
+	// err is kept for waiters when fn panics
+	c := &flightCall{err: errors.New("shared call did not return")}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		c.wg.Done()
+	}()
+	c.val, c.err = fn(ctx)
+	return c.val, false, c.err
+}
@@ -0,0 +1,248 @@
+package goline
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	defaultStateTTL = 10 * time.Minute
+	minStateKeySize = 32
+)
+
+var (
+	// ErrInvalidState is returned when the state parameter is malformed, the signature or the CSRF token does not match
+	ErrInvalidState = errors.New("invalid state")
+	// ErrStateExpired is returned when the state parameter is expired
+	ErrStateExpired = errors.New("state expired")
+	// ErrInvalidRedirectPath is returned when the redirect path is not a relative path on the same host
+	ErrInvalidRedirectPath = errors.New("invalid redirect path")
+)
+
+// State is the login state embedded in the state parameter of the authorization request
+type State struct {
+	// RedirectPath is the relative path, or the URL allowed by WithRedirectAllowlist, to redirect after login. Empty when not set.
+	RedirectPath string
+	// CSRFToken is bound to the browser e.g. by a cookie and checked in the callback
+	CSRFToken string
+	// Nonce is the nonce of the authorization request to verify in the ID token
+	Nonce string
+	// IssuedAt is the time when the state is signed
+	IssuedAt time.Time
+}
+
+// statePayload is the json payload of the state parameter
+type statePayload struct {
+	RedirectPath string `json:"r,omitempty"`
+	CSRFToken    string `json:"c"`
+	Nonce        string `json:"n"`
+	IssuedAt     int64  `json:"iat"`
+}
+
+// StateSigner creates and validates HMAC-signed, time-limited state parameters of LINE Login,
+// preventing CSRF and open redirects in the callback. It is safe for concurrent use.
+//
+//	state, st, err := signer.Sign(r.URL.Query().Get("redirect"))
+//	// Set st.CSRFToken in a cookie and redirect to LINE Login with state and st.Nonce
+//
+//	// In the callback
+//	st, err := signer.Verify(r.URL.Query().Get("state"), csrfCookie.Value)
+//	// Verify the ID token with st.Nonce and redirect to st.RedirectPath
+type StateSigner struct {
+	key   []byte
+	ttl   time.Duration
+	clock Clock
+
+	redirectPaths []string
+	redirectHosts map[string]bool
+}
+
+// StateSignerOption configures StateSigner
+type StateSignerOption func(*StateSigner)
+
+// WithStateTTL sets the lifetime of the state parameters. Default is 10 minutes.
+func WithStateTTL(d time.Duration) StateSignerOption {
+	return func(s *StateSigner) {
+		s.ttl = d
+	}
+}
+
+// WithStateClock sets Clock used to check the expiry of the state parameters. Default is SystemClock.
+func WithStateClock(clock Clock) StateSignerOption {
+	return func(s *StateSigner) {
+		s.clock = clock
+	}
+}
+
+// WithRedirectAllowlist restricts the redirect paths embedded in the state parameters.
+// The entries starting with "/" are the allowed path prefixes e.g. "/app/", and the others are the hosts
+// allowed in absolute https URLs e.g. "admin.example.com". Only relative paths on the same host are allowed by default.
+func WithRedirectAllowlist(entries ...string) StateSignerOption {
+	return func(s *StateSigner) {
+		for _, e := range entries {
+			if strings.HasPrefix(e, "/") {
+				s.redirectPaths = append(s.redirectPaths, e)
+				continue
+			}
+			if s.redirectHosts == nil {
+				s.redirectHosts = make(map[string]bool)
+			}
+			s.redirectHosts[strings.ToLower(e)] = true
+		}
+	}
+}
+
+// NewStateSigner returns StateSigner signing by the key. The key must be at least 32 bytes of random value.
+func NewStateSigner(key []byte, opts ...StateSignerOption) (*StateSigner, error) {
+	if len(key) < minStateKeySize {
+		return nil, fmt.Errorf("state key must be at least %d bytes", minStateKeySize)
+	}
+	s := &StateSigner{
+		key:   append([]byte(nil), key...),
+		ttl:   defaultStateTTL,
+		clock: SystemClock,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.ttl <= 0 {
+		return nil, errors.New("state TTL must be positive")
+	}
+	return s, nil
+}
+
+// Sign returns new state parameter embedding the redirect path, a random CSRF token and a random nonce.
+// The redirect path must be empty or a relative path on the same host e.g. "/orders?id=1", or allowed by WithRedirectAllowlist.
+func (s *StateSigner) Sign(redirectPath string) (string, *State, error) {
+	if err := s.validateRedirect(redirectPath); err != nil {
+		return "", nil, err
+	}
+	csrf, err := NewNonce()
+	if err != nil {
+		return "", nil, err
+	}
+	nonce, err := NewNonce()
+	if err != nil {
+		return "", nil, err
+	}
+	st := &State{
+		RedirectPath: redirectPath,
+		CSRFToken:    csrf,
+		Nonce:        nonce,
+		IssuedAt:     s.clock.Now().Truncate(time.Second),
+	}
+
+	b, err := json.Marshal(&statePayload{
+		RedirectPath: st.RedirectPath,
+		CSRFToken:    st.CSRFToken,
+		Nonce:        st.Nonce,
+		IssuedAt:     st.IssuedAt.Unix(),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(b)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.mac(payload)), st, nil
+}
+
+// Verify verifies the signature and the expiry of the state parameter and the CSRF token bound to the browser,
+// and returns the embedded State. ErrInvalidState or ErrStateExpired is returned when failed.
+func (s *StateSigner) Verify(state, csrfToken string) (*State, error) {
+	payload, sig, ok := strings.Cut(state, ".")
+	if !ok {
+		return nil, ErrInvalidState
+	}
+	b, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(b, s.mac(payload)) {
+		return nil, ErrInvalidState
+	}
+
+	p := &statePayload{}
+	if err := decodeJWTSegment(payload, p); err != nil {
+		return nil, ErrInvalidState
+	}
+	issuedAt := time.Unix(p.IssuedAt, 0)
+	if !s.clock.Now().Before(issuedAt.Add(s.ttl)) {
+		return nil, ErrStateExpired
+	}
+	if p.CSRFToken == "" || !equalSecret(p.CSRFToken, csrfToken) {
+		return nil, fmt.Errorf("%w: CSRF token does not match", ErrInvalidState)
+	}
+	if err := s.validateRedirect(p.RedirectPath); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidState, err)
+	}
+	return &State{RedirectPath: p.RedirectPath, CSRFToken: p.CSRFToken, Nonce: p.Nonce, IssuedAt: issuedAt}, nil
+}
+
+// validateRedirect validates the redirect path by ValidateRedirectPath and the allowlist
+func (s *StateSigner) validateRedirect(p string) error {
+	if p == "" {
+		return nil
+	}
+	if ValidateRedirectPath(p) != nil {
+		u, err := url.Parse(p)
+		if err != nil || u.Scheme != "https" || u.User != nil || !s.redirectHosts[strings.ToLower(u.Host)] {
+			return ErrInvalidRedirectPath
+		}
+		return nil
+	}
+	if len(s.redirectPaths) == 0 {
+		return nil
+	}
+	u, err := url.Parse(p)
+	if err != nil {
+		return ErrInvalidRedirectPath
+	}
+	clean := path.Clean(u.Path)
+	for _, prefix := range s.redirectPaths {
+		if clean == strings.TrimSuffix(prefix, "/") || strings.HasPrefix(clean, strings.TrimSuffix(prefix, "/")+"/") {
+			return nil
+		}
+	}
+	return ErrInvalidRedirectPath
+}
+
+func (s *StateSigner) mac(payload string) []byte {
+	m := hmac.New(sha256.New, s.key)
+	m.Write([]byte(payload))
+	return m.Sum(nil)
+}
+
+// NewNonce returns a random value of 256 bits encoded in base64url for nonce and CSRF token
+func NewNonce() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// ValidateRedirectPath returns ErrInvalidRedirectPath unless the path is empty or a relative path on the same host,
+// rejecting absolute URLs, scheme-relative URLs like "//evil.example" and backslashes to prevent open redirects.
+func ValidateRedirectPath(p string) error {
+	if p == "" {
+		return nil
+	}
+	if !strings.HasPrefix(p, "/") || strings.HasPrefix(p, "//") || strings.ContainsAny(p, "\\") {
+		return ErrInvalidRedirectPath
+	}
+	for _, c := range p {
+		if c < 0x20 || c == 0x7f {
+			return ErrInvalidRedirectPath
+		}
+	}
+	u, err := url.Parse(p)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.User != nil {
+		return ErrInvalidRedirectPath
+	}
+	return nil
+}
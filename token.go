@@ -0,0 +1,101 @@
+package goline
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	// See https://developers.line.biz/ja/reference/line-login/#issue-access-token
+	// and https://developers.line.biz/ja/reference/line-login/#refresh-access-token
+	urlToken = "https://api.line.me/oauth2/v2.1/token"
+)
+
+// TokenSet converts the response into TokenSet. ExpiresAt is calculated from "now".
+func (r *TokenResponse) TokenSet(now time.Time) *TokenSet {
+	return &TokenSet{
+		AccessToken:  r.AccessToken,
+		RefreshToken: r.RefreshToken,
+		IDToken:      r.IDToken,
+		ExpiresAt:    now.Add(time.Duration(r.ExpiresIn) * time.Second),
+	}
+}
+
+// IssueAccessTokenOptions is the parameters of IssueAccessToken
+type IssueAccessTokenOptions struct {
+	// Code is the authorization code of the callback. Required.
+	Code string
+	// RedirectURI is the callback URL of the authorization request. Required.
+	RedirectURI string
+	// CodeVerifier is required only when the authorization request has code_challenge of PKCE
+	CodeVerifier string
+}
+
+// IssueAccessToken is a function to call issue-access-token API by the authorization code of the callback.
+// The Client must be created with WithChannelSecret.
+// https://developers.line.biz/ja/reference/line-login/#issue-access-token
+func (c *Client) IssueAccessToken(ctx context.Context, opts IssueAccessTokenOptions) (*TokenResponse, error) {
+	// Check paramaters
+	if opts.Code == "" {
+		return nil, errors.New("authorization code not found")
+	}
+	if opts.RedirectURI == "" {
+		return nil, errors.New("redirect URI not found")
+	}
+	if c.clientSecret == "" {
+		return nil, errors.New("channel secret is not set")
+	}
+
+	// Prepare http request
+	req, err := newRequest(http.MethodPost, urlToken).formBody(
+		"grant_type", "authorization_code",
+		"code", opts.Code,
+		"redirect_uri", opts.RedirectURI,
+		"client_id", c.clientid,
+		"client_secret", c.clientSecret,
+		"code_verifier", opts.CodeVerifier,
+	).build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	res := &TokenResponse{}
+	if err := c.doRequestGetBody(req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// RefreshAccessToken is a function to call refresh-access-token API.
+// The Client must be created with WithChannelSecret.
+// https://developers.line.biz/ja/reference/line-login/#refresh-access-token
+func (c *Client) RefreshAccessToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
+	// Check token paramater
+	if refreshToken == "" {
+		return nil, errors.New("refresh token not found")
+	}
+	if c.clientSecret == "" {
+		return nil, errors.New("channel secret is not set")
+	}
+
+	// Prepare http request
+	req, err := newRequest(http.MethodPost, urlToken).formBody(
+		"grant_type", "refresh_token",
+		"refresh_token", refreshToken,
+		"client_id", c.clientid,
+		"client_secret", c.clientSecret,
+	).build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do http request and get response body
+	res := &TokenResponse{}
+	if err := c.doRequestGetBody(req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
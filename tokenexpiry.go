@@ -0,0 +1,51 @@
+package goline
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HeaderTokenExpiresIn is the response header of the seconds until the token expires, set by WithTokenExpiresInHeader
+const HeaderTokenExpiresIn = "X-LINE-Token-Expires-In"
+
+// WithTokenExpiresInHeader sets X-LINE-Token-Expires-In response header of the seconds until the verified token expires,
+// so that the clients can refresh the token proactively instead of discovering the expiry by 401 Unauthorized.
+func WithTokenExpiresInHeader() AuthorizerOption {
+	return func(a *Authorizer) {
+		a.expiresInHeader = true
+	}
+}
+
+// setExpiresInHeader sets X-LINE-Token-Expires-In header when enabled
+func (a *Authorizer) setExpiresInHeader(h http.Header, u *User) {
+	if !a.expiresInHeader || u.ExpiresAt.IsZero() {
+		return
+	}
+	sec := int64(u.ExpiresAt.Sub(a.lineClient.clock.Now()).Seconds())
+	if sec < 0 {
+		sec = 0
+	}
+	h.Set(HeaderTokenExpiresIn, strconv.FormatInt(sec, 10))
+}
+
+// WithSoftExpiryHook calls fn when the verified token expires within threshold,
+// e.g. to trigger the refresh flow or to warn the client before the token expires.
+// fn is called synchronously in the authentication with the remaining lifetime of the token.
+func WithSoftExpiryHook(threshold time.Duration, fn func(ctx context.Context, u *User, remaining time.Duration)) AuthorizerOption {
+	return func(a *Authorizer) {
+		a.softExpiry = threshold
+		a.onSoftExpiry = fn
+	}
+}
+
+// checkSoftExpiry calls the soft expiry hook when the token expires soon
+func (a *Authorizer) checkSoftExpiry(ctx context.Context, u *User) {
+	if a.onSoftExpiry == nil || u.ExpiresAt.IsZero() {
+		return
+	}
+	if remaining := u.ExpiresAt.Sub(a.lineClient.clock.Now()); remaining <= a.softExpiry {
+		a.onSoftExpiry(ctx, u, remaining)
+	}
+}
@@ -0,0 +1,118 @@
+package goline
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+const (
+	tokenReviewAPIVersion = "authentication.k8s.io/v1"
+	tokenReviewKind       = "TokenReview"
+
+	// Maximum size of the TokenReview request body, which only has a token
+	maxTokenReviewBodySize = 16 << 10
+)
+
+// TokenReview is the request and response json struct of the token review server.
+// It is compatible with Kubernetes TokenReview so that it can be used by the webhook token authenticator
+// and API gateway custom authorizers as a generic JSON contract.
+// https://kubernetes.io/docs/reference/access-authn-authz/authentication/#webhook-token-authentication
+type TokenReview struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Spec       TokenReviewSpec   `json:"spec"`
+	Status     TokenReviewStatus `json:"status"`
+}
+
+// TokenReviewSpec is the spec of TokenReview
+type TokenReviewSpec struct {
+	Token string `json:"token"`
+}
+
+// TokenReviewStatus is the result of TokenReview
+type TokenReviewStatus struct {
+	Authenticated bool            `json:"authenticated"`
+	User          TokenReviewUser `json:"user,omitempty"`
+	Error         string          `json:"error,omitempty"`
+}
+
+// TokenReviewUser is the authenticated user of TokenReview.
+// Username and UID are LINE user ID, and the other user info is set in Extra.
+type TokenReviewUser struct {
+	Username string              `json:"username,omitempty"`
+	UID      string              `json:"uid,omitempty"`
+	Extra    map[string][]string `json:"extra,omitempty"`
+}
+
+// IDTokenReviewHandler returns a handler of TokenReview verifying the ID token in spec.token.
+// It responds 200 OK with status.authenticated false when the token is not verified,
+// and 413 Request Entity Too Large when the request body is larger than 16KiB.
+func (a *Authorizer) IDTokenReviewHandler() http.Handler {
+	return a.tokenReviewHandler("IDTokenReviewHandler", a.AuthenticateIDToken)
+}
+
+// AccessTokenReviewHandler returns a handler of TokenReview same as IDTokenReviewHandler verifying the access token
+func (a *Authorizer) AccessTokenReviewHandler() http.Handler {
+	return a.tokenReviewHandler("AccessTokenReviewHandler", a.AuthenticateAccessToken)
+}
+
+func (a *Authorizer) tokenReviewHandler(name string, fn authenticateFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, log := a.withCorrelationID(r, a.log.With("handler", name))
+
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		review := &TokenReview{}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTokenReviewBodySize)).Decode(review); err != nil {
+			log.Error("failed to decode request body", "error", err)
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				return
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if review.APIVersion == "" {
+			review.APIVersion = tokenReviewAPIVersion
+		}
+		review.Kind = tokenReviewKind
+		review.Status = TokenReviewStatus{}
+
+		start := a.lineClient.clock.Now()
+		if review.Spec.Token == "" {
+			review.Status.Error = "token not found"
+		} else if u, err := fn(r.Context(), review.Spec.Token); err != nil {
+			a.recordDecision(r.Context(), log, nil, classifyAuthFailure(err), err, start)
+			review.Status.Error = "failed to verify token"
+		} else {
+			a.recordDecision(r.Context(), log, u, "", nil, start)
+			review.Status.Authenticated = true
+			review.Status.User = tokenReviewUser(u)
+		}
+		// Do not echo back the token
+		review.Spec.Token = ""
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(review); err != nil {
+			log.Error("failed to write response body", "error", err)
+		}
+	})
+}
+
+func tokenReviewUser(u *User) TokenReviewUser {
+	extra := make(map[string][]string)
+	set := func(k, v string) {
+		if v != "" {
+			extra[k] = []string{v}
+		}
+	}
+	set("displayName", u.DisplayName)
+	set("pictureUrl", u.PictureURL)
+	set("email", u.Email)
+	set("statusMessage", u.StatusMessage)
+	return TokenReviewUser{Username: u.ID, UID: u.ID, Extra: extra}
+}
@@ -0,0 +1,46 @@
+package goline
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTokenReviewHandler(t *testing.T) {
+	tests := []struct {
+		name              string
+		body              string
+		wantStatus        int
+		wantAuthenticated bool
+	}{
+		{name: "authenticated", body: `{"spec":{"token":"token"}}`, wantStatus: http.StatusOK, wantAuthenticated: true},
+		{name: "no token", body: `{"spec":{}}`, wantStatus: http.StatusOK},
+		{name: "invalid json", body: `{"spec":`, wantStatus: http.StatusBadRequest},
+		{name: "too large", body: `{"spec":{"token":"` + strings.Repeat("a", maxTokenReviewBodySize) + `"}}`, wantStatus: http.StatusRequestEntityTooLarge},
+	}
+	a := NewAuthorizer(NewClient("123", inMemoryLINE()), nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			a.AccessTokenReviewHandler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			review := &TokenReview{}
+			if err := json.NewDecoder(w.Body).Decode(review); err != nil {
+				t.Fatal(err)
+			}
+			if review.Status.Authenticated != tt.wantAuthenticated {
+				t.Errorf("authenticated = %v, want %v", review.Status.Authenticated, tt.wantAuthenticated)
+			}
+			if review.Spec.Token != "" {
+				t.Error("token is echoed back")
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package goline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrTokenNotFound is returned by TokenStore when no token set is saved for the user
+	ErrTokenNotFound = errors.New("token not found")
+)
+
+// TokenSet is a set of tokens issued to the LINE user by LINE Login
+type TokenSet struct {
+	AccessToken  string
+	RefreshToken string
+	IDToken      string
+	ExpiresAt    time.Time
+}
+
+// Expired reports whether the access token is expired at "now"
+func (t *TokenSet) Expired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// ExpiresWithin reports whether the access token expires within d from "now"
+func (t *TokenSet) ExpiresWithin(now time.Time, d time.Duration) bool {
+	return t.ExpiresAt.Sub(now) <= d
+}
+
+// TokenStore is a persistent store of token sets keyed by LINE user ID.
+// Implementations must be safe for concurrent use.
+type TokenStore interface {
+	// Save saves the token set of the user. The existing token set is overwritten.
+	Save(ctx context.Context, userID string, t *TokenSet) error
+	// Load returns the token set of the user. ErrTokenNotFound is returned when not found.
+	Load(ctx context.Context, userID string) (*TokenSet, error)
+	// Delete deletes the token set of the user. It returns nil when not found.
+	Delete(ctx context.Context, userID string) error
+}
+
+// TokenLister is implemented by TokenStore which can list the saved user IDs.
+// It is required by RefreshManager to watch all tokens in the store.
+type TokenLister interface {
+	ListUserIDs(ctx context.Context) ([]string, error)
+}
+
+// MemoryTokenStore is an in-memory TokenStore
+type MemoryTokenStore struct {
+	mu     sync.RWMutex
+	tokens map[string]TokenSet
+}
+
+// NewMemoryTokenStore returns new MemoryTokenStore
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: make(map[string]TokenSet)}
+}
+
+// Save implements TokenStore
+func (s *MemoryTokenStore) Save(ctx context.Context, userID string, t *TokenSet) error {
+	if userID == "" {
+		return errors.New("user ID not found")
+	}
+	if t == nil {
+		return errors.New("token set is nil")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[userID] = *t
+	return nil
+}
+
+// Load implements TokenStore
+func (s *MemoryTokenStore) Load(ctx context.Context, userID string) (*TokenSet, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.tokens[userID]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	return &t, nil
+}
+
+// Delete implements TokenStore
+func (s *MemoryTokenStore) Delete(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, userID)
+	return nil
+}
+
+// ListUserIDs implements TokenLister
+func (s *MemoryTokenStore) ListUserIDs(ctx context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.tokens))
+	for id := range s.tokens {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,248 @@
+package goline
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var validTableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// SQLTokenStore is a TokenStore backed by database/sql.
+// The table must be created in advance with the following columns.
+//
+//	CREATE TABLE line_tokens (
+//		user_id       VARCHAR(64) PRIMARY KEY,
+//		access_token  TEXT NOT NULL,
+//		refresh_token TEXT NOT NULL,
+//		id_token      TEXT NOT NULL,
+//		expires_at    BIGINT NOT NULL
+//	);
+//
+// expires_at is stored as unix time in seconds.
+type SQLTokenStore struct {
+	db          *sql.DB
+	table       string
+	placeholder func(n int) string
+	cipher      TokenCipher
+}
+
+// SQLTokenStoreOption configures SQLTokenStore
+type SQLTokenStoreOption func(*SQLTokenStore)
+
+// WithSQLPlaceholder sets the placeholder format of the driver. Default is "?".
+// Use DollarPlaceholder for PostgreSQL.
+func WithSQLPlaceholder(fn func(n int) string) SQLTokenStoreOption {
+	return func(s *SQLTokenStore) {
+		s.placeholder = fn
+	}
+}
+
+// WithTokenCipher encrypts access tokens and refresh tokens before storing them
+func WithTokenCipher(c TokenCipher) SQLTokenStoreOption {
+	return func(s *SQLTokenStore) {
+		s.cipher = c
+	}
+}
+
+// DollarPlaceholder returns "$n" placeholder used by PostgreSQL drivers
+func DollarPlaceholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func questionPlaceholder(n int) string {
+	return "?"
+}
+
+// NewSQLTokenStore returns new SQLTokenStore using the table
+func NewSQLTokenStore(db *sql.DB, table string, opts ...SQLTokenStoreOption) (*SQLTokenStore, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+	if !validTableName.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name: %s", table)
+	}
+	s := &SQLTokenStore{
+		db:          db,
+		table:       table,
+		placeholder: questionPlaceholder,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Save implements TokenStore
+func (s *SQLTokenStore) Save(ctx context.Context, userID string, t *TokenSet) error {
+	if userID == "" {
+		return errors.New("user ID not found")
+	}
+	if t == nil {
+		return errors.New("token set is nil")
+	}
+	accessToken, err := s.encrypt(userID, t.AccessToken)
+	if err != nil {
+		return err
+	}
+	refreshToken, err := s.encrypt(userID, t.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	// Delete and insert in a transaction as upsert syntax differs by database
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		fmt.Sprintf("DELETE FROM %s WHERE user_id = %s", s.table, s.placeholder(1)),
+		userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		fmt.Sprintf("INSERT INTO %s (user_id, access_token, refresh_token, id_token, expires_at) VALUES (%s, %s, %s, %s, %s)",
+			s.table, s.placeholder(1), s.placeholder(2), s.placeholder(3), s.placeholder(4), s.placeholder(5)),
+		userID, accessToken, refreshToken, t.IDToken, t.ExpiresAt.Unix()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Load implements TokenStore
+func (s *SQLTokenStore) Load(ctx context.Context, userID string) (*TokenSet, error) {
+	row := s.db.QueryRowContext(ctx,
+		fmt.Sprintf("SELECT access_token, refresh_token, id_token, expires_at FROM %s WHERE user_id = %s", s.table, s.placeholder(1)),
+		userID)
+
+	var (
+		accessToken, refreshToken, idToken string
+		expiresAt                          int64
+	)
+	if err := row.Scan(&accessToken, &refreshToken, &idToken, &expiresAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, err
+	}
+
+	t := &TokenSet{IDToken: idToken, ExpiresAt: time.Unix(expiresAt, 0)}
+	var err error
+	if t.AccessToken, err = s.decrypt(userID, accessToken); err != nil {
+		return nil, err
+	}
+	if t.RefreshToken, err = s.decrypt(userID, refreshToken); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Delete implements TokenStore
+func (s *SQLTokenStore) Delete(ctx context.Context, userID string) error {
+	_, err := s.db.ExecContext(ctx,
+		fmt.Sprintf("DELETE FROM %s WHERE user_id = %s", s.table, s.placeholder(1)),
+		userID)
+	return err
+}
+
+// ListUserIDs implements TokenLister
+func (s *SQLTokenStore) ListUserIDs(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf("SELECT user_id FROM %s", s.table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// encrypt encrypts the token bound to the user ID, so that the ciphertext copied to the row of another user cannot be decrypted
+func (s *SQLTokenStore) encrypt(userID, v string) (string, error) {
+	if s.cipher == nil || v == "" {
+		return v, nil
+	}
+	b, err := s.cipher.Encrypt([]byte(v), []byte(userID))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt token: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+func (s *SQLTokenStore) decrypt(userID, v string) (string, error) {
+	if s.cipher == nil || v == "" {
+		return v, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode token: %w", err)
+	}
+	p, err := s.cipher.Decrypt(b, []byte(userID))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt token: %w", err)
+	}
+	return string(p), nil
+}
+
+// TokenCipher encrypts and decrypts tokens stored in TokenStore.
+// additionalData is authenticated but not encrypted, SQLTokenStore passes the user ID of the row.
+// Decrypt must fail when additionalData differs from the one given to Encrypt.
+type TokenCipher interface {
+	Encrypt(plaintext, additionalData []byte) ([]byte, error)
+	Decrypt(ciphertext, additionalData []byte) ([]byte, error)
+}
+
+// AESGCMCipher is a TokenCipher using AES-GCM.
+// The random nonce is prepended to the ciphertext, and additionalData is used as the additional authenticated data.
+type AESGCMCipher struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCMCipher returns new AESGCMCipher. The key must be 16, 24 or 32 bytes to select AES-128, AES-192 or AES-256.
+func NewAESGCMCipher(key []byte) (*AESGCMCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AESGCMCipher{aead: aead}, nil
+}
+
+// Encrypt implements TokenCipher
+func (c *AESGCMCipher) Encrypt(plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Decrypt implements TokenCipher
+func (c *AESGCMCipher) Decrypt(ciphertext, additionalData []byte) ([]byte, error) {
+	n := c.aead.NonceSize()
+	if len(ciphertext) < n {
+		return nil, errors.New("ciphertext too short")
+	}
+	return c.aead.Open(nil, ciphertext[:n], ciphertext[n:], additionalData)
+}
@@ -0,0 +1,55 @@
+package goline
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAESGCMCipher(t *testing.T) {
+	c, err := NewAESGCMCipher(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, err := c.Encrypt([]byte("token"), []byte("U1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(ct, []byte("token")) {
+		t.Error("ciphertext contains the plaintext")
+	}
+	if p, err := c.Decrypt(ct, []byte("U1")); err != nil || string(p) != "token" {
+		t.Errorf("Decrypt() = %s, %v", p, err)
+	}
+	for _, aad := range [][]byte{[]byte("U2"), nil} {
+		if _, err := c.Decrypt(ct, aad); err == nil {
+			t.Errorf("Decrypt() with additional data %q, want error", aad)
+		}
+	}
+	if _, err := c.Decrypt(ct[:4], []byte("U1")); err == nil {
+		t.Error("Decrypt() of short ciphertext, want error")
+	}
+	if _, err := NewAESGCMCipher([]byte("short")); err == nil {
+		t.Error("NewAESGCMCipher() with invalid key size, want error")
+	}
+}
+
+func TestSQLTokenStoreCipherBindsUserID(t *testing.T) {
+	c, _ := NewAESGCMCipher(bytes.Repeat([]byte{1}, 32))
+	s := &SQLTokenStore{cipher: c}
+
+	v, err := s.encrypt("U1", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.decrypt("U1", v); err != nil || got != "token" {
+		t.Errorf("decrypt() = %s, %v", got, err)
+	}
+	// The ciphertext copied to the row of another user
+	if _, err := s.decrypt("U2", v); err == nil {
+		t.Error("decrypt() for another user, want error")
+	}
+	// Empty tokens are stored as is
+	if v, _ := s.encrypt("U1", ""); v != "" {
+		t.Errorf("encrypt() of empty token = %s", v)
+	}
+}
@@ -0,0 +1,93 @@
+package goline
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ErrTransportNotConfigurable is returned by the requests of the Client created with WithProxyURL or WithTLSConfig
+// when the transport of the http client is not *http.Transport, as the options cannot be applied to it.
+// The requests fail rather than being sent without the proxy or the TLS configuration.
+var ErrTransportNotConfigurable = errors.New("WithProxyURL and WithTLSConfig require *http.Transport")
+
+// WithProxyURL sends the requests to LINE via the proxy e.g. a dedicated NAT or allowlist proxy.
+// Without it, the proxy is taken from HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+// when the http client uses the default transport.
+// It is applied to a copy of the transport of the http client, which must be *http.Transport or nil,
+// otherwise the requests fail with ErrTransportNotConfigurable.
+func WithProxyURL(u *url.URL) ClientOption {
+	return func(c *Client) {
+		c.proxyURL = u
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the requests to LINE, e.g. custom CA bundles, pinned certificates
+// or client certificates of mTLS proxies. The config is cloned, so changing it after NewClient has no effect.
+// It is applied to a copy of the transport of the http client, which must be *http.Transport or nil,
+// otherwise the requests fail with ErrTransportNotConfigurable.
+func WithTLSConfig(cfg *tls.Config) ClientOption {
+	return func(c *Client) {
+		c.tlsConfig = cfg.Clone()
+	}
+}
+
+type transportContextKey struct{}
+
+// SetTransport returns the context to send the requests of the Client by rt instead of the transport of the Client,
+// e.g. to route the calls of specific tenants via another proxy.
+func SetTransport(ctx context.Context, rt http.RoundTripper) context.Context {
+	return context.WithValue(ctx, transportContextKey{}, rt)
+}
+
+// contextTransport is a http.RoundTripper using the transport in the request context if any
+type contextTransport struct {
+	base http.RoundTripper
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt, ok := req.Context().Value(transportContextKey{}).(http.RoundTripper); ok && rt != nil {
+		return rt.RoundTrip(req)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// errorTransport is a http.RoundTripper failing all requests
+type errorTransport struct {
+	err error
+}
+
+func (t errorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, t.err
+}
+
+// configureTransport sets up the transport of the copied http client by the options
+func (c *Client) configureTransport() {
+	base := c.client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if c.proxyURL != nil || c.tlsConfig != nil {
+		if t, ok := base.(*http.Transport); ok {
+			t = t.Clone()
+			if c.proxyURL != nil {
+				t.Proxy = http.ProxyURL(c.proxyURL)
+			}
+			if c.tlsConfig != nil {
+				t.TLSClientConfig = c.tlsConfig
+			}
+			base = t
+		} else {
+			err := fmt.Errorf("%w: the transport is %T", ErrTransportNotConfigurable, base)
+			c.log.Error("proxy and TLS options are not applied, all requests fail", "error", err)
+			base = errorTransport{err: err}
+		}
+	}
+	c.client.Transport = &contextTransport{base: base}
+}
@@ -0,0 +1,63 @@
+package goline
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestConfigureTransport(t *testing.T) {
+	proxy, _ := url.Parse("http://proxy.example.com:3128")
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS13}
+
+	t.Run("http.Transport", func(t *testing.T) {
+		base := &http.Transport{}
+		c := NewClient("123", &http.Client{Transport: base}, WithProxyURL(proxy), WithTLSConfig(tlsConfig))
+		tr, ok := c.client.Transport.(*contextTransport).base.(*http.Transport)
+		if !ok {
+			t.Fatalf("transport = %T, want *http.Transport", c.client.Transport.(*contextTransport).base)
+		}
+		if tr == base {
+			t.Error("the transport of the http client is modified")
+		}
+		req, _ := http.NewRequest(http.MethodGet, urlGetUserProfile, nil)
+		if u, _ := tr.Proxy(req); u.String() != proxy.String() {
+			t.Errorf("proxy = %v", u)
+		}
+		if tr.TLSClientConfig.MinVersion != tls.VersionTLS13 {
+			t.Errorf("TLSClientConfig = %+v", tr.TLSClientConfig)
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		c := NewClient("123", nil, WithTLSConfig(tlsConfig))
+		if _, ok := c.client.Transport.(*contextTransport).base.(*http.Transport); !ok {
+			t.Errorf("transport = %T, want *http.Transport", c.client.Transport.(*contextTransport).base)
+		}
+	})
+
+	t.Run("other RoundTripper", func(t *testing.T) {
+		called := false
+		hc := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			called = true
+			return nil, errors.New("unexpected")
+		})}
+		for _, opt := range []ClientOption{WithProxyURL(proxy), WithTLSConfig(tlsConfig)} {
+			_, err := NewClient("123", hc, opt).GetProfile(context.Background(), "token")
+			if !errors.Is(err, ErrTransportNotConfigurable) {
+				t.Errorf("GetProfile() error = %v, want ErrTransportNotConfigurable", err)
+			}
+		}
+		if called {
+			t.Error("the request is sent without the options")
+		}
+
+		// Without the options the transport is used as is
+		if _, err := NewClient("123", hc).GetProfile(context.Background(), "token"); errors.Is(err, ErrTransportNotConfigurable) || !called {
+			t.Errorf("GetProfile() error = %v, want sent by the transport", err)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package goline
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+)
+
+var (
+	// ErrUserNotFound is returned when no authenticated user is in the context
+	ErrUserNotFound = errors.New("authenticated user not found in context")
+)
+
+// User is the LINE user authorized by Authorizer.
+// Fields not provided by the verified token are empty,
+// e.g. StatusMessage for ID token and Email for access token.
+type User struct {
+	ID            string `json:"userId"`
+	DisplayName   string `json:"displayName,omitempty"`
+	PictureURL    string `json:"pictureUrl,omitempty"`
+	Email         string `json:"email,omitempty"`
+	StatusMessage string `json:"statusMessage,omitempty"`
+	// AMR is the authentication methods of the ID token
+	AMR []string `json:"amr,omitempty"`
+	// AuthTime is auth_time or iat of the ID token. It is zero for the access token.
+	AuthTime time.Time `json:"-"`
+	// ExpiresAt is the expiry of the token, "exp" of the ID token or calculated from expires_in of the access token
+	ExpiresAt time.Time `json:"-"`
+
+	// Claims is all claims of the ID token, or the fields of the profile for the access token
+	Claims map[string]interface{} `json:"-"`
+	// Enrichment is the application-specific data added by Enricher
+	Enrichment *Enrichment `json:"-"`
+	// Degraded is true when the user is authorized by DegradationPolicy without LINE
+	Degraded bool `json:"-"`
+}
+
+// clone returns a copy of u not sharing the slices and the maps, as the kept users are returned to many requests
+func (u *User) clone() *User {
+	c := *u
+	c.AMR = slices.Clone(u.AMR)
+	c.Claims = maps.Clone(u.Claims)
+	return &c
+}
+
+type userContextKey struct{}
+
+// SetUser returns the context with the LINE user.
+// The middlewares of Authorizer set the authenticated user by it. It is also useful to test handlers.
+func SetUser(ctx context.Context, u *User) context.Context {
+	return context.WithValue(ctx, userContextKey{}, u)
+}
+
+// UserFromContext returns the LINE user set by the middlewares of Authorizer
+func UserFromContext(ctx context.Context) (*User, bool) {
+	u, ok := ctx.Value(userContextKey{}).(*User)
+	return u, ok && u != nil
+}
+
+// MustUserFromContext returns the LINE user set by the middlewares of Authorizer.
+// It panics when no user is in the context, which means the handler is not protected by the middlewares.
+func MustUserFromContext(ctx context.Context) *User {
+	u, ok := UserFromContext(ctx)
+	if !ok {
+		panic(ErrUserNotFound)
+	}
+	return u
+}
+
+// UserFromRequest returns the LINE user in the request context.
+// ErrUserNotFound is returned when no user is in the context.
+func UserFromRequest(r *http.Request) (*User, error) {
+	u, ok := UserFromContext(r.Context())
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return u, nil
+}
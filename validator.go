@@ -0,0 +1,74 @@
+package goline
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrValidationFailed is wrapped in the error returned when Validator rejects the verified token
+	ErrValidationFailed = errors.New("validation failed")
+)
+
+// Validator validates the token and the user after the verification by LINE,
+// e.g. to check the user against an internal ban list or to enforce tenant rules.
+// The user has the claims of the token in Claims. Return an error to reject the token.
+type Validator interface {
+	Validate(ctx context.Context, token string, user *User) error
+}
+
+// ValidatorFunc is an adapter to use an ordinary function as Validator
+type ValidatorFunc func(ctx context.Context, token string, user *User) error
+
+// Validate calls f(ctx, token, user)
+func (f ValidatorFunc) Validate(ctx context.Context, token string, user *User) error {
+	return f(ctx, token, user)
+}
+
+// WithValidators adds Validators run in order after the verification by LINE.
+// They are run by AuthenticateIDToken and AuthenticateAccessToken, so by all the middlewares and handlers of Authorizer.
+// All of them are run, and the request is rejected with AuthFailureValidationFailed and ValidationErrors
+// when any of them returns an error.
+func WithValidators(v ...Validator) AuthorizerOption {
+	return func(a *Authorizer) {
+		a.validators = append(a.validators, v...)
+	}
+}
+
+// ValidationErrors is the errors of all the validators rejecting the token.
+// errors.Is(err, ErrValidationFailed) is true, and errors.Is and errors.As also match each error like errors.Join.
+type ValidationErrors []error
+
+// Error returns the messages of all the errors
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return ErrValidationFailed.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors of the validators
+func (e ValidationErrors) Unwrap() []error {
+	return e
+}
+
+// Is reports whether the target is ErrValidationFailed
+func (e ValidationErrors) Is(target error) bool {
+	return target == ErrValidationFailed
+}
+
+// validate runs all the validators and returns ValidationErrors when any of them fails
+func (a *Authorizer) validate(ctx context.Context, token string, u *User) error {
+	var errs ValidationErrors
+	for _, v := range a.validators {
+		if err := v.Validate(ctx, token, u); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package goline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// Issuer of ID tokens
+	idTokenIssuer = "https://access.line.me"
+)
+
+// Names of the checks in VerificationResult
+const (
+	CheckFormat   = "format"
+	CheckAudience = "aud"
+	CheckIssuer   = "iss"
+	CheckExpiry   = "exp"
+	CheckNonce    = "nonce"
+	CheckClientID = "client_id"
+	// CheckRemote is the verification by LINE API including the signature
+	CheckRemote = "remote"
+)
+
+// VerificationCheck is the result of a check in the verification
+type VerificationCheck struct {
+	Name     string        `json:"name"`
+	Passed   bool          `json:"passed"`
+	Detail   string        `json:"detail,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// VerificationResult is the verbose result of the token verification for debugging why the token is rejected.
+// The decision is made by LINE API. The local checks of the claims are diagnostics
+// e.g. to tell the token is expired or issued for another channel before asking LINE.
+type VerificationResult struct {
+	Verified bool                `json:"verified"`
+	Failure  AuthFailure         `json:"failure,omitempty"`
+	Error    string              `json:"error,omitempty"`
+	Checks   []VerificationCheck `json:"checks"`
+	// Shared is true when the result of the API call by a concurrent caller is used
+	Shared bool `json:"shared"`
+	// Cached is true when the cached result is used
+	Cached   bool          `json:"cached"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Failed returns the names of the failed checks
+func (r *VerificationResult) Failed() []string {
+	var names []string
+	for _, c := range r.Checks {
+		if !c.Passed {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+func (r *VerificationResult) check(name string, start time.Time, passed bool, detail string) {
+	r.Checks = append(r.Checks, VerificationCheck{Name: name, Passed: passed, Detail: detail, Duration: time.Since(start)})
+}
+
+func (r *VerificationResult) fail(err error, failure AuthFailure) {
+	r.Verified = false
+	r.Failure = failure
+	r.Error = err.Error()
+}
+
+// VerifyIDTokenWithResult verifies the ID token same as VerifyIDToken and returns VerificationResult as well.
+// It is an opt-in API for debugging as it decodes the token locally in addition to VerifyIDToken.
+func (c *Client) VerifyIDTokenWithResult(ctx context.Context, idToken string, opts *VerifyIDTokenOptions) (*IDTokenData, *VerificationResult, error) {
+	o := c.idTokenOptions(opts)
+	start := time.Now()
+	r := &VerificationResult{}
+	defer func() { r.Duration = time.Since(start) }()
+
+	// Local checks
+	t := time.Now()
+	claims, err := DecodeIDToken(idToken)
+	if err != nil {
+		r.check(CheckFormat, t, false, err.Error())
+		r.fail(err, AuthFailureTokenMalformed)
+		return nil, r, err
+	}
+	r.check(CheckFormat, t, true, "")
+
+	t = time.Now()
+	r.check(CheckAudience, t, claims.Aud == o.ChannelID, fmt.Sprintf("got %s want %s", claims.Aud, o.ChannelID))
+
+	t = time.Now()
+	r.check(CheckIssuer, t, claims.Iss == idTokenIssuer, fmt.Sprintf("got %s want %s", claims.Iss, idTokenIssuer))
+
+	t = time.Now()
+	exp := time.Unix(claims.Exp, 0)
+	now := c.clock.Now()
+	if now.Before(exp) {
+		r.check(CheckExpiry, t, true, fmt.Sprintf("expires in %s", exp.Sub(now).Round(time.Second)))
+	} else {
+		r.check(CheckExpiry, t, false, fmt.Sprintf("expired %s ago", now.Sub(exp).Round(time.Second)))
+	}
+
+	if o.Nonce != "" {
+		t = time.Now()
+		r.check(CheckNonce, t, equalSecret(claims.Nonce, o.Nonce), "")
+	}
+
+	// Verification by LINE
+	t = time.Now()
+	d, source, err := c.sharedVerifyIDToken(ctx, idToken, o)
+	r.Shared, r.Cached = source == sourceShared, source == sourceCache
+	if err != nil {
+		r.check(CheckRemote, t, false, err.Error())
+		r.fail(err, classifyAuthFailure(err))
+		return nil, r, err
+	}
+	r.check(CheckRemote, t, true, "")
+	r.Verified = true
+	return d, r, nil
+}
+
+// VerifyAccessTokenWithResult verifies the access token same as VerifyAccessToken and returns VerificationResult as well
+func (c *Client) VerifyAccessTokenWithResult(ctx context.Context, accessToken string) (*VerifyAccessTokenResponse, *VerificationResult, error) {
+	start := time.Now()
+	r := &VerificationResult{}
+	defer func() { r.Duration = time.Since(start) }()
+
+	// Verification by LINE. Access tokens are opaque so that no local checks can be done.
+	t := time.Now()
+	res, source, err := c.sharedVerifyAccessToken(ctx, accessToken)
+	r.Shared, r.Cached = source == sourceShared, source == sourceCache
+	if err != nil {
+		if errors.Is(err, ErrClientIDMismatch) {
+			r.check(CheckRemote, t, true, "")
+			r.check(CheckClientID, t, false, err.Error())
+		} else {
+			r.check(CheckRemote, t, false, err.Error())
+		}
+		r.fail(err, classifyAuthFailure(err))
+		return nil, r, err
+	}
+	r.check(CheckRemote, t, true, "")
+	if c.checksClientID() {
+		r.check(CheckClientID, t, true, "")
+	} else {
+		r.check(CheckClientID, t, true, "skipped")
+	}
+	r.check(CheckExpiry, t, true, fmt.Sprintf("expires in %s", time.Duration(res.ExpiresIn)*time.Second))
+	r.Verified = true
+	return res, r, nil
+}
+
+// DecodeIDToken decodes the claims of the ID token WITHOUT verifying it.
+// Use it only for logging, debugging or choosing the channel by "aud" before verification.
+// The claims must not be trusted until the token is verified by VerifyIDToken.
+func DecodeIDToken(idToken string) (*IDTokenData, error) {
+	d := &IDTokenData{}
+	if err := decodeJWTPayload(idToken, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// decodeJWTPayload decodes the payload of JWT into v without verifying the signature
+func decodeJWTPayload(token string, v interface{}) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return errors.New("token is not JWT")
+	}
+	return decodeJWTSegment(parts[1], v)
+}
@@ -0,0 +1,85 @@
+package goline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// warmupOptions is the options of Authorizer.Warmup
+type warmupOptions struct {
+	verifier     *LocalVerifier
+	idTokens     []string
+	accessTokens []string
+}
+
+// WarmupOption configures Authorizer.Warmup
+type WarmupOption func(o *warmupOptions)
+
+// WithWarmupLocalVerifier fills the JWKS cache of the LocalVerifier in Warmup
+func WithWarmupLocalVerifier(v *LocalVerifier) WarmupOption {
+	return func(o *warmupOptions) {
+		o.verifier = v
+	}
+}
+
+// WithWarmupIDToken pre-verifies the canary ID token in Warmup. The validators and the enricher are not run.
+func WithWarmupIDToken(idToken string) WarmupOption {
+	return func(o *warmupOptions) {
+		o.idTokens = append(o.idTokens, idToken)
+	}
+}
+
+// WithWarmupAccessToken pre-verifies the canary access token in Warmup. The validators and the enricher are not run.
+func WithWarmupAccessToken(accessToken string) WarmupOption {
+	return func(o *warmupOptions) {
+		o.accessTokens = append(o.accessTokens, accessToken)
+	}
+}
+
+// Warmup fetches the OpenID Connect discovery document and JWKS of LINE, and pre-verifies the canary tokens if given,
+// so that the first authenticated request after deploy does not pay the cold-start latency
+// of DNS, TLS handshakes and the caches. Call it before marking the server ready.
+// It tries all steps and returns the errors joined. It does nothing for NewDevAuthorizer.
+//
+//	if err := lineAuth.Warmup(ctx, goline.WithWarmupLocalVerifier(verifier)); err != nil {
+//		log.Warn("warmup failed", "error", err)
+//	}
+func (a *Authorizer) Warmup(ctx context.Context, opts ...WarmupOption) error {
+	if a.dev != nil {
+		return nil
+	}
+	o := &warmupOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	start := a.lineClient.clock.Now()
+
+	var errs []error
+	if err := a.lineClient.HealthCheck(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to fetch OpenID Connect discovery: %w", err))
+	}
+
+	jwks := newJWKSCache(a.lineClient)
+	if o.verifier != nil {
+		jwks = o.verifier.jwks
+	}
+	if err := jwks.refresh(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, t := range o.idTokens {
+		if _, err := a.verifyIDToken(ctx, t); err != nil {
+			errs = append(errs, fmt.Errorf("failed to verify canary ID token: %w", err))
+		}
+	}
+	for _, t := range o.accessTokens {
+		if _, err := a.verifyAccessToken(ctx, t); err != nil {
+			errs = append(errs, fmt.Errorf("failed to verify canary access token: %w", err))
+		}
+	}
+
+	err := errors.Join(errs...)
+	a.log.DebugContext(ctx, "warmup finished", "latency_ms", a.lineClient.clock.Now().Sub(start).Milliseconds(), "error", err)
+	return err
+}
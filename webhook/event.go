@@ -0,0 +1,82 @@
+package webhook
+
+// EventType is a type of webhook event
+// https://developers.line.biz/ja/reference/messaging-api/#webhook-event-objects
+type EventType string
+
+const (
+	EventTypeMessage  EventType = "message"
+	EventTypeFollow   EventType = "follow"
+	EventTypeUnfollow EventType = "unfollow"
+	EventTypeJoin     EventType = "join"
+	EventTypeLeave    EventType = "leave"
+	EventTypePostback EventType = "postback"
+)
+
+// Request is the request body json struct of webhook.
+// https://developers.line.biz/ja/reference/messaging-api/#request-body
+type Request struct {
+	Destination string   `json:"destination"`
+	Events      []*Event `json:"events"`
+}
+
+// Event is a webhook event object.
+// Message, Postback and Follow are set only for the corresponding event type.
+// https://developers.line.biz/ja/reference/messaging-api/#common-properties
+type Event struct {
+	Type            EventType       `json:"type"`
+	Mode            string          `json:"mode"`
+	Timestamp       int64           `json:"timestamp"`
+	Source          *Source         `json:"source,omitempty"`
+	WebhookEventID  string          `json:"webhookEventId"`
+	DeliveryContext DeliveryContext `json:"deliveryContext"`
+	ReplyToken      string          `json:"replyToken,omitempty"`
+	Message         *Message        `json:"message,omitempty"`
+	Postback        *Postback       `json:"postback,omitempty"`
+	Follow          *Follow         `json:"follow,omitempty"`
+}
+
+// Source is the source of the event.
+// https://developers.line.biz/ja/reference/messaging-api/#source-user
+type Source struct {
+	Type    string `json:"type"`
+	UserID  string `json:"userId,omitempty"`
+	GroupID string `json:"groupId,omitempty"`
+	RoomID  string `json:"roomId,omitempty"`
+}
+
+// DeliveryContext is the delivery context of the event.
+type DeliveryContext struct {
+	IsRedelivery bool `json:"isRedelivery"`
+}
+
+// Message is the message object of message event.
+// https://developers.line.biz/ja/reference/messaging-api/#message-event
+type Message struct {
+	ID         string  `json:"id"`
+	Type       string  `json:"type"`
+	QuoteToken string  `json:"quoteToken,omitempty"`
+	Text       string  `json:"text,omitempty"`
+	Duration   int     `json:"duration,omitempty"`
+	FileName   string  `json:"fileName,omitempty"`
+	FileSize   int     `json:"fileSize,omitempty"`
+	Title      string  `json:"title,omitempty"`
+	Address    string  `json:"address,omitempty"`
+	Latitude   float64 `json:"latitude,omitempty"`
+	Longitude  float64 `json:"longitude,omitempty"`
+	PackageID  string  `json:"packageId,omitempty"`
+	StickerID  string  `json:"stickerId,omitempty"`
+}
+
+// Postback is the postback object of postback event.
+// https://developers.line.biz/ja/reference/messaging-api/#postback-event
+type Postback struct {
+	Data   string            `json:"data"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// Follow is the follow object of follow event.
+// https://developers.line.biz/ja/reference/messaging-api/#follow-event
+type Follow struct {
+	IsUnblocked bool `json:"isUnblocked"`
+}
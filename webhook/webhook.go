@@ -0,0 +1,392 @@
+// Package webhook provides an http.Handler receiving LINE Messaging API webhook events.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/jlandowner/goline/messaging"
+)
+
+const (
+	// See https://developers.line.biz/ja/reference/messaging-api/#signature-validation
+	signatureHeader = "X-Line-Signature"
+
+	defaultMaxConcurrency = 10
+	defaultQueueSize      = 1000
+	// LINE platform sends at most 100 events in a request, which fits in 1MiB
+	defaultMaxBodySize = 1 << 20
+)
+
+var (
+	// ErrInvalidSignature is returned when X-Line-Signature does not match the request body
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrNoReplyToken is returned when ReplyFunc returns messages for the event without reply token
+	ErrNoReplyToken = errors.New("event has no reply token")
+	// ErrCallbackPanic is passed to the error handler when an event callback panics
+	ErrCallbackPanic = errors.New("event callback panicked")
+)
+
+// EventHandlerFunc is a callback function for webhook events
+type EventHandlerFunc func(ctx context.Context, e *Event) error
+
+// ReplyFunc is a callback function for webhook events returning messages to reply.
+// The returned messages are sent with the reply token of the event. Return no messages not to reply.
+type ReplyFunc func(ctx context.Context, e *Event) ([]messaging.Message, error)
+
+// Option configures Handler
+type Option func(*Handler)
+
+// WithMaxConcurrency sets the maximum number of event callbacks running at the same time.
+// Default is 10.
+func WithMaxConcurrency(n int) Option {
+	return func(h *Handler) {
+		if n > 0 {
+			h.maxConcurrency = n
+		}
+	}
+}
+
+// WithQueueSize sets the maximum number of event callbacks waiting for a free slot.
+// Requests whose events do not fit in the queue are rejected with 503 and redelivered by LINE platform
+// if webhook redelivery is enabled. Default is 1000.
+func WithQueueSize(n int) Option {
+	return func(h *Handler) {
+		if n > 0 {
+			h.queueSize = n
+		}
+	}
+}
+
+// WithErrorHandler sets a function called when an event callback returns an error.
+func WithErrorHandler(fn func(e *Event, err error)) Option {
+	return func(h *Handler) {
+		h.onError = fn
+	}
+}
+
+// WithMaxBodySize sets the maximum size of the request body in bytes.
+// Larger requests are rejected with 413 Request Entity Too Large. Default is 1MiB.
+func WithMaxBodySize(n int64) Option {
+	return func(h *Handler) {
+		if n > 0 {
+			h.maxBodySize = n
+		}
+	}
+}
+
+// WithBaseContext sets the parent context of the context passed to the event callbacks.
+// Default is context.Background().
+func WithBaseContext(ctx context.Context) Option {
+	return func(h *Handler) {
+		h.baseCtx = ctx
+	}
+}
+
+// WithReplyClient sets Messaging API client used to send messages returned by ReplyFunc.
+func WithReplyClient(c *messaging.Client) Option {
+	return func(h *Handler) {
+		h.replyClient = c
+	}
+}
+
+// Handler is an http.Handler of LINE webhook.
+// It verifies X-Line-Signature, responds 200 immediately and calls the registered callbacks asynchronously
+// on a fixed number of goroutines started by NewHandler and stopped by Shutdown.
+type Handler struct {
+	secret         string
+	maxConcurrency int
+	queueSize      int
+	maxBodySize    int64
+	baseCtx        context.Context
+	onError        func(e *Event, err error)
+	replyClient    *messaging.Client
+
+	mu       sync.RWMutex
+	handlers map[EventType][]EventHandlerFunc
+
+	// ctx is passed to the event callbacks and canceled by Shutdown
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// queueMu guards closed, pending and the sends to queue against Shutdown closing it
+	queueMu sync.Mutex
+	idle    *sync.Cond
+	closed  bool
+	pending int
+	queue   chan job
+	workers sync.WaitGroup
+}
+
+type job struct {
+	fn EventHandlerFunc
+	e  *Event
+}
+
+// NewHandler returns new Handler. "secret" is the channel secret of the Messaging API channel.
+func NewHandler(secret string, opts ...Option) *Handler {
+	h := &Handler{
+		secret:         secret,
+		maxConcurrency: defaultMaxConcurrency,
+		queueSize:      defaultQueueSize,
+		maxBodySize:    defaultMaxBodySize,
+		baseCtx:        context.Background(),
+		handlers:       make(map[EventType][]EventHandlerFunc),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.ctx, h.cancel = context.WithCancel(h.baseCtx)
+	h.idle = sync.NewCond(&h.queueMu)
+	h.queue = make(chan job, h.queueSize)
+	h.workers.Add(h.maxConcurrency)
+	for i := 0; i < h.maxConcurrency; i++ {
+		go h.work()
+	}
+	return h
+}
+
+// Handle registers a callback for the given event type
+func (h *Handler) Handle(t EventType, fn EventHandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handlers[t] = append(h.handlers[t], fn)
+}
+
+// OnMessage registers a callback for message events
+func (h *Handler) OnMessage(fn EventHandlerFunc) {
+	h.Handle(EventTypeMessage, fn)
+}
+
+// OnFollow registers a callback for follow events
+func (h *Handler) OnFollow(fn EventHandlerFunc) {
+	h.Handle(EventTypeFollow, fn)
+}
+
+// OnPostback registers a callback for postback events
+func (h *Handler) OnPostback(fn EventHandlerFunc) {
+	h.Handle(EventTypePostback, fn)
+}
+
+// HandleReply registers a callback for the given event type which replies the returned messages.
+// The Handler must be created with WithReplyClient.
+// When the reply token is expired or already used, messaging.ErrInvalidReplyToken is passed to the error handler.
+func (h *Handler) HandleReply(t EventType, fn ReplyFunc) {
+	h.Handle(t, func(ctx context.Context, e *Event) error {
+		msgs, err := fn(ctx, e)
+		if err != nil {
+			return err
+		}
+		if len(msgs) == 0 {
+			return nil
+		}
+		return h.reply(ctx, e, msgs)
+	})
+}
+
+// OnMessageReply registers a callback for message events which replies the returned messages
+func (h *Handler) OnMessageReply(fn ReplyFunc) {
+	h.HandleReply(EventTypeMessage, fn)
+}
+
+// OnFollowReply registers a callback for follow events which replies the returned messages
+func (h *Handler) OnFollowReply(fn ReplyFunc) {
+	h.HandleReply(EventTypeFollow, fn)
+}
+
+// OnPostbackReply registers a callback for postback events which replies the returned messages
+func (h *Handler) OnPostbackReply(fn ReplyFunc) {
+	h.HandleReply(EventTypePostback, fn)
+}
+
+func (h *Handler) reply(ctx context.Context, e *Event, msgs []messaging.Message) error {
+	if h.replyClient == nil {
+		return errors.New("reply client is not set")
+	}
+	if e.ReplyToken == "" {
+		return ErrNoReplyToken
+	}
+	if err := h.replyClient.ReplyMessage(ctx, e.ReplyToken, msgs...); err != nil {
+		return fmt.Errorf("failed to reply: %w", err)
+	}
+	return nil
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	// LINE platform redelivers the events after shutdown if webhook redelivery is enabled
+	if h.isClosed() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBodySize)
+	req, err := ParseRequest(h.secret, r)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.Is(err, ErrInvalidSignature):
+			w.WriteHeader(http.StatusUnauthorized)
+		case errors.As(err, &maxBytesErr):
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		return
+	}
+
+	// Enqueueing never blocks, so the response is sent before processing events as LINE platform recommends
+	if !h.enqueue(h.jobs(req.Events)) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Wait blocks until all queued and running event callbacks are finished
+func (h *Handler) Wait() {
+	h.queueMu.Lock()
+	defer h.queueMu.Unlock()
+	for h.pending > 0 {
+		h.idle.Wait()
+	}
+}
+
+// Shutdown stops accepting webhook requests with 503 and waits for the queued and running event callbacks.
+// When ctx is done before they finish, the context of the callbacks is canceled and ctx.Err() is returned
+// without waiting for them to return. Call it after http.Server.Shutdown.
+func (h *Handler) Shutdown(ctx context.Context) error {
+	h.queueMu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.queue)
+	}
+	h.queueMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		h.cancel()
+		return nil
+	case <-ctx.Done():
+		// The callbacks still waiting in the queue are skipped with the error of the canceled context
+		h.cancel()
+		return ctx.Err()
+	}
+}
+
+func (h *Handler) isClosed() bool {
+	h.queueMu.Lock()
+	defer h.queueMu.Unlock()
+	return h.closed
+}
+
+func (h *Handler) jobs(events []*Event) []job {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var jobs []job
+	for _, e := range events {
+		for _, fn := range h.handlers[e.Type] {
+			jobs = append(jobs, job{fn: fn, e: e})
+		}
+	}
+	return jobs
+}
+
+// enqueue queues all the jobs, or none of them when the handler is closed or the queue has no room for them.
+// The events of a request are not partially processed, as LINE platform redelivers all of them.
+func (h *Handler) enqueue(jobs []job) bool {
+	h.queueMu.Lock()
+	defer h.queueMu.Unlock()
+	if h.closed || len(jobs) > cap(h.queue)-len(h.queue) {
+		return false
+	}
+	h.pending += len(jobs)
+	for _, j := range jobs {
+		h.queue <- j
+	}
+	return true
+}
+
+func (h *Handler) work() {
+	defer h.workers.Done()
+	for j := range h.queue {
+		h.run(j)
+
+		h.queueMu.Lock()
+		h.pending--
+		if h.pending == 0 {
+			h.idle.Broadcast()
+		}
+		h.queueMu.Unlock()
+	}
+}
+
+func (h *Handler) run(j job) {
+	if err := h.ctx.Err(); err != nil {
+		h.handleError(j.e, err)
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			h.handleError(j.e, fmt.Errorf("%w: %v", ErrCallbackPanic, r))
+		}
+	}()
+	if err := j.fn(h.ctx, j.e); err != nil {
+		h.handleError(j.e, err)
+	}
+}
+
+func (h *Handler) handleError(e *Event, err error) {
+	if h.onError != nil {
+		h.onError(e, err)
+	}
+}
+
+// ParseRequest verifies the signature of webhook request and returns the parsed request body.
+// The body is read without limit, wrap it by http.MaxBytesReader for untrusted requests as Handler does.
+func ParseRequest(secret string, r *http.Request) (*Request, error) {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	if !ValidateSignature(secret, r.Header.Get(signatureHeader), b) {
+		return nil, ErrInvalidSignature
+	}
+
+	req := &Request{}
+	if err := json.Unmarshal(b, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// ValidateSignature reports whether the signature matches the HMAC-SHA256 digest of body with the channel secret
+// https://developers.line.biz/ja/reference/messaging-api/#signature-validation
+func ValidateSignature(secret, signature string, body []byte) bool {
+	decoded, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(decoded, mac.Sum(nil))
+}
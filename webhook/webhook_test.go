@@ -0,0 +1,301 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testSecret = "secret"
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func messageEvents(n int) string {
+	events := make([]string, n)
+	for i := range events {
+		events[i] = fmt.Sprintf(`{"type":"message","webhookEventId":"e%d","message":{"id":"%d","type":"text","text":"hi"}}`, i, i)
+	}
+	return `{"destination":"U1","events":[` + strings.Join(events, ",") + `]}`
+}
+
+func post(h http.Handler, body, signature string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+	r.Header.Set(signatureHeader, signature)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestHandlerServeHTTP(t *testing.T) {
+	body := messageEvents(1)
+	tests := []struct {
+		name      string
+		method    string
+		body      string
+		signature string
+		want      int
+	}{
+		{name: "ok", body: body, signature: sign(testSecret, body), want: http.StatusOK},
+		{name: "invalid signature", body: body, signature: sign("other", body), want: http.StatusUnauthorized},
+		{name: "no signature", body: body, want: http.StatusUnauthorized},
+		{name: "invalid json", body: `{"events":`, signature: sign(testSecret, `{"events":`), want: http.StatusBadRequest},
+		{name: "too large", body: messageEvents(2000), signature: sign(testSecret, messageEvents(2000)), want: http.StatusRequestEntityTooLarge},
+		{name: "method", method: http.MethodGet, want: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(testSecret, WithMaxBodySize(64<<10))
+			var got int
+			var mu sync.Mutex
+			h.OnMessage(func(ctx context.Context, e *Event) error {
+				mu.Lock()
+				got++
+				mu.Unlock()
+				return nil
+			})
+
+			var w *httptest.ResponseRecorder
+			if tt.method != "" {
+				w = httptest.NewRecorder()
+				h.ServeHTTP(w, httptest.NewRequest(tt.method, "/webhook", nil))
+			} else {
+				w = post(h, tt.body, tt.signature)
+			}
+			h.Wait()
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if want := map[bool]int{true: 1, false: 0}[tt.want == http.StatusOK]; got != want {
+				t.Errorf("callback called %d times, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestHandlerRespondsWhileCallbacksAreBusy(t *testing.T) {
+	h := NewHandler(testSecret, WithMaxConcurrency(1))
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var handled []string
+	h.OnMessage(func(ctx context.Context, e *Event) error {
+		<-release
+		mu.Lock()
+		handled = append(handled, e.WebhookEventID)
+		mu.Unlock()
+		return nil
+	})
+
+	// All the events wait for the single slot, but the response must not wait for them
+	body := messageEvents(5)
+	done := make(chan int)
+	go func() { done <- post(h, body, sign(testSecret, body)).Code }()
+	select {
+	case code := <-done:
+		if code != http.StatusOK {
+			t.Errorf("status = %d, want 200", code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ServeHTTP blocked by the running callbacks")
+	}
+
+	close(release)
+	h.Wait()
+	if len(handled) != 5 {
+		t.Errorf("%d events handled, want 5", len(handled))
+	}
+}
+
+func TestHandlerShutdown(t *testing.T) {
+	t.Run("wait", func(t *testing.T) {
+		h := NewHandler(testSecret)
+		finished := make(chan struct{})
+		h.OnMessage(func(ctx context.Context, e *Event) error {
+			time.Sleep(10 * time.Millisecond)
+			close(finished)
+			return ctx.Err()
+		})
+		body := messageEvents(1)
+		post(h, body, sign(testSecret, body))
+
+		if err := h.Shutdown(context.Background()); err != nil {
+			t.Errorf("Shutdown() error = %v", err)
+		}
+		select {
+		case <-finished:
+		default:
+			t.Error("Shutdown() returned before the callback finished")
+		}
+		if w := post(h, body, sign(testSecret, body)); w.Code != http.StatusServiceUnavailable {
+			t.Errorf("status after shutdown = %d, want 503", w.Code)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		var mu sync.Mutex
+		var errs []error
+		h := NewHandler(testSecret, WithMaxConcurrency(1), WithErrorHandler(func(e *Event, err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		}))
+		h.OnMessage(func(ctx context.Context, e *Event) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		// One callback runs and one waits for the slot
+		body := messageEvents(2)
+		post(h, body, sign(testSecret, body))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := h.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Shutdown() error = %v, want context.DeadlineExceeded", err)
+		}
+		h.Wait()
+		if len(errs) != 2 {
+			t.Fatalf("%d errors, want 2", len(errs))
+		}
+		for _, err := range errs {
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("error = %v, want context.Canceled", err)
+			}
+		}
+	})
+
+	t.Run("callback ignoring cancel", func(t *testing.T) {
+		h := NewHandler(testSecret)
+		release := make(chan struct{})
+		defer close(release)
+		h.OnMessage(func(ctx context.Context, e *Event) error {
+			<-release
+			return nil
+		})
+		body := messageEvents(1)
+		post(h, body, sign(testSecret, body))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		done := make(chan error)
+		go func() { done <- h.Shutdown(ctx) }()
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Shutdown() error = %v, want context.DeadlineExceeded", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Shutdown() waited for the callback after the deadline")
+		}
+	})
+
+	t.Run("twice", func(t *testing.T) {
+		h := NewHandler(testSecret)
+		for i := 0; i < 2; i++ {
+			if err := h.Shutdown(context.Background()); err != nil {
+				t.Errorf("Shutdown() error = %v", err)
+			}
+		}
+	})
+
+	t.Run("base context", func(t *testing.T) {
+		type key struct{}
+		base := context.WithValue(context.Background(), key{}, "v")
+		h := NewHandler(testSecret, WithBaseContext(base))
+		var got interface{}
+		h.OnMessage(func(ctx context.Context, e *Event) error {
+			got = ctx.Value(key{})
+			return nil
+		})
+		body := messageEvents(1)
+		post(h, body, sign(testSecret, body))
+		h.Wait()
+		if got != "v" {
+			t.Errorf("ctx.Value() = %v, want the value of the base context", got)
+		}
+	})
+}
+
+func TestHandlerQueueFull(t *testing.T) {
+	h := NewHandler(testSecret, WithMaxConcurrency(1), WithQueueSize(2))
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var handled int
+	h.OnMessage(func(ctx context.Context, e *Event) error {
+		<-release
+		mu.Lock()
+		handled++
+		mu.Unlock()
+		return nil
+	})
+
+	// The events of a request are queued all together or rejected
+	body := messageEvents(3)
+	if w := post(h, body, sign(testSecret, body)); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", w.Code)
+	}
+	body = messageEvents(2)
+	if w := post(h, body, sign(testSecret, body)); w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", w.Code)
+	}
+
+	close(release)
+	h.Wait()
+	if handled != 2 {
+		t.Errorf("%d events handled, want 2", handled)
+	}
+}
+
+func TestHandlerRecoversPanic(t *testing.T) {
+	var mu sync.Mutex
+	var errs []error
+	h := NewHandler(testSecret, WithMaxConcurrency(1), WithErrorHandler(func(e *Event, err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}))
+	var handled int
+	h.OnMessage(func(ctx context.Context, e *Event) error {
+		if e.WebhookEventID == "e0" {
+			panic("boom")
+		}
+		handled++
+		return nil
+	})
+
+	body := messageEvents(2)
+	post(h, body, sign(testSecret, body))
+	h.Wait()
+
+	if len(errs) != 1 || !errors.Is(errs[0], ErrCallbackPanic) {
+		t.Errorf("errors = %v, want ErrCallbackPanic", errs)
+	}
+	// The worker survives the panic
+	if handled != 1 {
+		t.Errorf("%d events handled, want 1", handled)
+	}
+}
+
+func TestValidateSignature(t *testing.T) {
+	body := []byte(`{"events":[]}`)
+	if !ValidateSignature(testSecret, sign(testSecret, string(body)), body) {
+		t.Error("valid signature is rejected")
+	}
+	for _, s := range []string{"", "not base64!", sign("other", string(body))} {
+		if ValidateSignature(testSecret, s, body) {
+			t.Errorf("signature %q is accepted", s)
+		}
+	}
+}